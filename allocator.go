@@ -0,0 +1,81 @@
+package snowflake
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrNoFreeNode is returned by a LocalAllocator when every node number in its
+// range is in use.
+var ErrNoFreeNode = errors.New("no free node number")
+
+// A LocalAllocator hands out distinct node numbers from a range to the nodes
+// of a single process, such as one per shard or worker, so that they never
+// generate duplicate IDs between them.  It implements Coordinator, but only
+// coordinates within the process; give each process its own range.
+type LocalAllocator struct {
+	mu    sync.Mutex
+	first int64
+	used  []bool
+	free  int
+}
+
+// NewLocalAllocator returns a LocalAllocator handing out the count node
+// numbers starting at first.
+func NewLocalAllocator(first int64, count int) (*LocalAllocator, error) {
+	if first < 0 || count <= 0 {
+		return nil, errors.New("allocator range must start at 0 or above and hold at least one node number")
+	}
+
+	return &LocalAllocator{first: first, used: make([]bool, count), free: count}, nil
+}
+
+// ReserveNodeID reserves the lowest free node number of the range, or
+// returns ErrNoFreeNode.  Calling release returns it to the allocator; it is
+// safe to call more than once.
+func (a *LocalAllocator) ReserveNodeID(ctx context.Context) (int64, func(), error) {
+	if err := ctx.Err(); err != nil {
+		return 0, nil, err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for i, used := range a.used {
+		if used {
+			continue
+		}
+
+		a.used[i] = true
+		a.free--
+
+		var once sync.Once
+		return a.first + int64(i), func() { once.Do(func() { a.release(i) }) }, nil
+	}
+
+	return 0, nil, ErrNoFreeNode
+}
+
+func (a *LocalAllocator) release(i int) {
+	a.mu.Lock()
+	a.used[i] = false
+	a.free++
+	a.mu.Unlock()
+}
+
+// NewNode creates a node using the lowest free node number of the range and
+// opts.  The node number is returned to the allocator when the node is
+// closed with Node.Close.
+func (a *LocalAllocator) NewNode(opts ...Option) (*Node, error) {
+	n, _, err := NewNodeFromCoordinator(context.Background(), a, opts...)
+	return n, err
+}
+
+// Available returns the number of free node numbers left in the range.
+func (a *LocalAllocator) Available() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.free
+}
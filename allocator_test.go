@@ -0,0 +1,75 @@
+package snowflake
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestLocalAllocator(t *testing.T) {
+	a, err := NewLocalAllocator(100, 4)
+	if err != nil {
+		t.Fatalf("error creating LocalAllocator, %s", err)
+	}
+
+	// Concurrent workers each get a distinct node number.
+	nodes := make([]*Node, 4)
+	var wg sync.WaitGroup
+	for i := range nodes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			nodes[i], _ = a.NewNode()
+		}(i)
+	}
+	wg.Wait()
+
+	seen := make(map[int64]bool)
+	for _, n := range nodes {
+		if n == nil || n.Number() < 100 || n.Number() > 103 || seen[n.Number()] {
+			t.Fatalf("Got nodes %v, expected the distinct numbers 100 to 103", nodes)
+		}
+		seen[n.Number()] = true
+	}
+
+	if a.Available() != 0 {
+		t.Errorf("Got %d available, expected 0", a.Available())
+	}
+	if _, err := a.NewNode(); !errors.Is(err, ErrNoFreeNode) {
+		t.Errorf("Got %v, expected ErrNoFreeNode", err)
+	}
+
+	freed := nodes[2].Number()
+	if err := nodes[2].Close(); err != nil {
+		t.Fatalf("Unexpected error closing: %v", err)
+	}
+	nodes[2].Close()
+	if a.Available() != 1 {
+		t.Errorf("Got %d available after closing twice, expected 1", a.Available())
+	}
+
+	again, err := a.NewNode()
+	if err != nil || again.Number() != freed {
+		t.Errorf("Got (%v, %v), expected node %d to be reused", again, err, freed)
+	}
+
+	// A node that cannot be created gives its number back.
+	if _, err := a.NewNode(WithLayout(Layout{NodeBits: 2, StepBits: 12})); err == nil {
+		t.Error("Expected an error for a node number outside the layout")
+	}
+	nodes[0].Close()
+	if _, err := a.NewNode(WithLayout(Layout{NodeBits: 2, StepBits: 12})); err == nil || a.Available() != 1 {
+		t.Errorf("Got (%v, %d available), expected the failed node's number released", err, a.Available())
+	}
+
+	if _, err := NewLocalAllocator(0, 0); err == nil {
+		t.Error("Expected an error for an empty range")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := a.ReserveNodeID(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Got %v, expected context.Canceled", err)
+	}
+}
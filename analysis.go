@@ -0,0 +1,176 @@
+package snowflake
+
+import (
+	"strconv"
+	"time"
+)
+
+// SplitRuns partitions ids into maximal strictly increasing runs, in order.
+// A new run is started wherever an ID is not greater than the one before it,
+// which makes restarts or clock regressions in recovered logs easy to spot.
+// A fully sorted slice without duplicates returns a single run.  The returned
+// runs share ids' backing array.
+func SplitRuns(ids []ID) [][]ID {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var runs [][]ID
+	start := 0
+	for i := 1; i < len(ids); i++ {
+		if ids[i] <= ids[i-1] {
+			runs = append(runs, ids[start:i:i])
+			start = i
+		}
+	}
+
+	return append(runs, ids[start:])
+}
+
+// BaseRaw can be passed to EncodedSize to size IDs stored as raw 8 byte
+// integers.
+const BaseRaw = 0
+
+// EncodedSize returns the total number of bytes ids take up when encoded in
+// the given base, without building the encoded strings.  Bases 2 to 36 match
+// strconv.FormatInt and so String, Base2 and Base36, base 64 matches Base64,
+// and BaseRaw counts 8 bytes per ID.  Any other base panics.
+func EncodedSize(ids []ID, base int) int {
+	switch {
+	case base == BaseRaw:
+		return len(ids) * 8
+	case base == 64:
+		total := 0
+		for _, id := range ids {
+			total += (digits(int64(id), 10) + 2) / 3 * 4
+		}
+		return total
+	case base < 2 || base > 36:
+		panic("snowflake: unsupported base " + strconv.Itoa(base))
+	}
+
+	total := 0
+	for _, id := range ids {
+		total += digits(int64(id), base)
+	}
+	return total
+}
+
+// digits returns the length of strconv.FormatInt(v, base).
+func digits(v int64, base int) int {
+	n := 1
+	u := uint64(v)
+	if v < 0 {
+		n++
+		u = uint64(-v)
+	}
+
+	for b := uint64(base); u >= b; u /= b {
+		n++
+	}
+	return n
+}
+
+// BusiestNode returns the node number that appears most often in ids along
+// with the number of IDs it generated.  Ties are broken by the lowest node
+// number, and an empty slice returns (0, 0).
+func BusiestNode(ids []ID) (node int64, count int) {
+	for n, c := range NodesOf(ids) {
+		if c > count || (c == count && n < node) {
+			node, count = n, c
+		}
+	}
+	return node, count
+}
+
+// SortednessScore returns the fraction of adjacent pairs in ids whose second
+// ID has a time not earlier than the first, from 0 to 1.  A score of 1 means
+// ids is in time order, even if IDs from different nodes within the same
+// millisecond are not in numeric order.  Slices with fewer than two IDs score
+// 1.
+func SortednessScore(ids []ID) float64 {
+	if len(ids) < 2 {
+		return 1
+	}
+
+	sorted := 0
+	for i := 1; i < len(ids); i++ {
+		if ids[i].Time() >= ids[i-1].Time() {
+			sorted++
+		}
+	}
+	return float64(sorted) / float64(len(ids)-1)
+}
+
+// TimeBounds returns the earliest and latest times of the IDs in ids in a
+// single pass, without sorting.  ok is false if ids is empty.
+func TimeBounds(ids []ID) (earliest, latest time.Time, ok bool) {
+	if len(ids) == 0 {
+		return time.Time{}, time.Time{}, false
+	}
+
+	min, max := ids[0].Time(), ids[0].Time()
+	for _, id := range ids[1:] {
+		if t := id.Time(); t < min {
+			min = t
+		} else if t > max {
+			max = t
+		}
+	}
+	return time.UnixMilli(min), time.UnixMilli(max), true
+}
+
+// StepHistogram returns the number of IDs in ids for each millisecond, keyed
+// by the unix time in milliseconds.  Only milliseconds present in ids have an
+// entry, empty milliseconds are not filled in.
+func StepHistogram(ids []ID) map[int64]int {
+	h := make(map[int64]int)
+	for _, id := range ids {
+		h[id.Time()]++
+	}
+	return h
+}
+
+// TimeHistogram returns the number of IDs in ids for each bucket of the given
+// width, keyed by the start of the bucket as returned by time.Time.Truncate.
+// Only buckets present in ids have an entry.  The keys are built the same way
+// for every ID, so they can be looked up with the result of
+// time.UnixMilli(ms).Truncate(bucket).  It panics if bucket is not positive.
+func TimeHistogram(ids []ID, bucket time.Duration) map[time.Time]int {
+	if bucket <= 0 {
+		panic("snowflake: histogram bucket must be positive")
+	}
+
+	h := make(map[time.Time]int)
+	for _, id := range ids {
+		h[time.UnixMilli(id.Time()).Truncate(bucket)]++
+	}
+	return h
+}
+
+// NodesOf returns the number of IDs in ids generated by each node number.
+func NodesOf(ids []ID) map[int64]int {
+	counts := make(map[int64]int)
+	for _, id := range ids {
+		counts[id.Node()]++
+	}
+	return counts
+}
+
+// Range returns the smallest and largest IDs in ids in a single pass, without
+// sorting.  An empty slice returns (0, 0).
+func Range(ids []ID) (min, max ID) {
+	if len(ids) == 0 {
+		return 0, 0
+	}
+
+	min, max = ids[0], ids[0]
+	for _, id := range ids[1:] {
+		if id < min {
+			min = id
+		} else if id > max {
+			max = id
+		}
+	}
+	return min, max
+}
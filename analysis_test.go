@@ -0,0 +1,181 @@
+package snowflake
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSplitRuns(t *testing.T) {
+	ids := []ID{1, 2, 5, 3, 4, 4, 9}
+	expected := [][]ID{{1, 2, 5}, {3, 4}, {4, 9}}
+
+	if runs := SplitRuns(ids); !reflect.DeepEqual(runs, expected) {
+		t.Errorf("Got %v, expected %v", runs, expected)
+	}
+
+	if runs := SplitRuns([]ID{1, 2, 3}); len(runs) != 1 {
+		t.Errorf("Got %d runs for a sorted slice, expected 1", len(runs))
+	}
+
+	if runs := SplitRuns(nil); runs != nil {
+		t.Errorf("Got %v for an empty slice, expected nil", runs)
+	}
+}
+
+func TestEncodedSize(t *testing.T) {
+	ids := []ID{0, 1, 35, 36, -7, 1<<63 - 1, -1 << 63, 1288834974657 << 22}
+
+	for base := 2; base <= 36; base++ {
+		expected := 0
+		for _, id := range ids {
+			expected += len(strconv.FormatInt(int64(id), base))
+		}
+
+		if size := EncodedSize(ids, base); size != expected {
+			t.Errorf("Got %d for base %d, expected %d", size, base, expected)
+		}
+	}
+
+	expected := 0
+	for _, id := range ids {
+		expected += len(id.Base64())
+	}
+	if size := EncodedSize(ids, 64); size != expected {
+		t.Errorf("Got %d for base 64, expected %d", size, expected)
+	}
+
+	if size := EncodedSize(ids, BaseRaw); size != len(ids)*8 {
+		t.Errorf("Got %d for BaseRaw, expected %d", size, len(ids)*8)
+	}
+}
+
+func TestBusiestNode(t *testing.T) {
+	var ids []ID
+	for _, n := range []int64{3, 1, 3, 2, 3, 1} {
+		node, _ := NewNode(n)
+		ids = append(ids, node.Generate())
+	}
+
+	if node, count := BusiestNode(ids); node != 3 || count != 3 {
+		t.Errorf("Got (%d, %d), expected (3, 3)", node, count)
+	}
+
+	if node, count := BusiestNode(ids[1:4]); node != 1 || count != 1 {
+		t.Errorf("Got (%d, %d) for a tie, expected (1, 1)", node, count)
+	}
+
+	if node, count := BusiestNode(nil); node != 0 || count != 0 {
+		t.Errorf("Got (%d, %d) for an empty slice, expected (0, 0)", node, count)
+	}
+}
+
+func TestSortednessScore(t *testing.T) {
+	ids := make([]ID, 5)
+	for i := range ids {
+		ids[i] = ID(int64(i) << timeShift)
+	}
+
+	if s := SortednessScore(ids); s != 1 {
+		t.Errorf("Got %v for a sorted slice, expected 1", s)
+	}
+
+	jumbled := []ID{ids[0], ids[2], ids[1], ids[4], ids[3]}
+	if s := SortednessScore(jumbled); s != 0.5 {
+		t.Errorf("Got %v for a jumbled slice, expected 0.5", s)
+	}
+
+	sameMs := []ID{ids[1] | 5<<nodeShift, ids[1] | 2<<nodeShift}
+	if s := SortednessScore(sameMs); s != 1 {
+		t.Errorf("Got %v for IDs in the same millisecond, expected 1", s)
+	}
+
+	if s := SortednessScore(nil); s != 1 {
+		t.Errorf("Got %v for an empty slice, expected 1", s)
+	}
+}
+
+func TestTimeBounds(t *testing.T) {
+	at := func(ms int64, node int64) ID {
+		return ID((ms-Epoch)<<timeShift | node<<nodeShift)
+	}
+
+	base := Epoch + 1000000
+	ids := []ID{at(base+5, 1), at(base+2, 3), at(base+9, 0), at(base+2, 1), at(base+9, 2)}
+
+	earliest, latest, ok := TimeBounds(ids)
+	if !ok {
+		t.Fatal("Expected ok for a non-empty slice")
+	}
+
+	if !earliest.Equal(time.UnixMilli(base+2)) || !latest.Equal(time.UnixMilli(base+9)) {
+		t.Errorf("Got (%s, %s), expected (%s, %s)", earliest, latest, time.UnixMilli(base+2), time.UnixMilli(base+9))
+	}
+
+	if _, _, ok := TimeBounds(nil); ok {
+		t.Error("Expected !ok for an empty slice")
+	}
+}
+
+func TestStepHistogram(t *testing.T) {
+	base := Epoch + 1000000
+
+	var ids []ID
+	expected := map[int64]int{base: 3, base + 1: 1, base + 4: 5}
+	for ms, count := range expected {
+		for step := 0; step < count; step++ {
+			ids = append(ids, ID((ms-Epoch)<<timeShift|int64(step)))
+		}
+	}
+
+	if h := StepHistogram(ids); !reflect.DeepEqual(h, expected) {
+		t.Errorf("Got %v, expected %v", h, expected)
+	}
+}
+
+func TestTimeHistogram(t *testing.T) {
+	base := time.Date(2024, 1, 2, 3, 4, 0, 0, time.UTC)
+	at := func(d time.Duration) ID { return FirstIDForTime(base.Add(d)) }
+
+	ids := []ID{at(0), at(59 * time.Second), at(time.Minute), at(3*time.Minute + time.Second), at(time.Minute + time.Millisecond)}
+	h := TimeHistogram(ids, time.Minute)
+
+	expected := map[time.Time]int{
+		time.UnixMilli(base.UnixMilli()):                      2,
+		time.UnixMilli(base.Add(time.Minute).UnixMilli()):     2,
+		time.UnixMilli(base.Add(3 * time.Minute).UnixMilli()): 1,
+	}
+	if !reflect.DeepEqual(h, expected) {
+		t.Errorf("Got %v, expected %v", h, expected)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a panic for a zero bucket")
+		}
+	}()
+	TimeHistogram(ids, 0)
+}
+
+func TestNodesOf(t *testing.T) {
+	ids := []ID{1<<nodeShift | 1, 2 << nodeShift, 1 << nodeShift, 7 << nodeShift}
+	expected := map[int64]int{1: 2, 2: 1, 7: 1}
+	if got := NodesOf(ids); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Got %v, expected %v", got, expected)
+	}
+
+	if got := NodesOf(nil); len(got) != 0 {
+		t.Errorf("Got %v, expected an empty map", got)
+	}
+}
+
+func TestRange(t *testing.T) {
+	if min, max := Range([]ID{5, 3, 9, 1, 7}); min != 1 || max != 9 {
+		t.Errorf("Got %d, %d, expected 1, 9", min, max)
+	}
+
+	if min, max := Range(nil); min != 0 || max != 0 {
+		t.Errorf("Got %d, %d, expected 0, 0", min, max)
+	}
+}
@@ -0,0 +1,61 @@
+package snowflake
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrBackfillNotPast is returned by GenerateAt for a time that is not
+	// before the node's current time unit.
+	ErrBackfillNotPast = errors.New("backfill time is not in the past")
+
+	// ErrBackfillExhausted is returned by GenerateAt once every step of a
+	// time unit has been used for backfilled IDs.
+	ErrBackfillExhausted = errors.New("backfill steps exhausted for the time")
+)
+
+// GenerateAt creates and returns a snowflake ID with the explicit time t,
+// rounded down to the node's time unit, for backfilling imported historical
+// records so that their IDs sort among native IDs by creation time.  t must
+// be at or after the node's epoch and before its current time unit;
+// ErrBackfillNotPast is returned for the present or the future, where the ID
+// could collide with IDs the node is about to generate.
+//
+// Backfilled IDs are counted separately from Generate, so a node number that
+// generated live IDs in the past may produce duplicates of them: use a node
+// number dedicated to backfills.  Successive calls for the same time unit
+// take successive steps, up to 2^StepBits of them before
+// ErrBackfillExhausted, and a call for a different time unit starts again at
+// step zero.  IDs are therefore only unique if records are imported in time
+// order, or the import is split into runs by time unit and each unit is only
+// visited once.  GenerateInBucket may fit imports with only a time range.
+func (n *Node) GenerateAt(t time.Time) (ID, error) {
+	since := t.UnixNano() - n.epoch*int64(time.Millisecond)
+	if since < 0 {
+		return 0, errors.New("backfill time is before the epoch")
+	}
+	tick := since / n.unit
+
+	if err := n.open(); err != nil {
+		return 0, err
+	}
+
+	n.Lock()
+	defer n.Unlock()
+
+	if tick >= n.elapsed() {
+		return 0, ErrBackfillNotPast
+	}
+
+	if tick == n.backfillTime {
+		if n.backfillStep == n.stepMask {
+			return 0, ErrBackfillExhausted
+		}
+		n.backfillStep++
+	} else {
+		n.backfillTime, n.backfillStep = tick, 0
+	}
+
+	return n.compose(tick, n.node, n.backfillStep), nil
+}
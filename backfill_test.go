@@ -0,0 +1,52 @@
+package snowflake
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGenerateAt(t *testing.T) {
+	node, _ := NewNode(9, WithLayout(Layout{NodeBits: 10, StepBits: 2}))
+
+	past := time.Now().Add(-time.Hour).Truncate(time.Millisecond)
+
+	var ids []ID
+	for i := 0; i < 4; i++ {
+		id, err := node.GenerateAt(past.Add(time.Duration(i%2) * 100 * time.Microsecond))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	for i, id := range ids {
+		p := node.Decode(id)
+		if !p.Time.Equal(past) || p.Node != 9 || p.Step != int64(i) {
+			t.Errorf("Got %+v for ID %d, expected time %s node 9 step %d", p, i, past, i)
+		}
+	}
+
+	if _, err := node.GenerateAt(past); !errors.Is(err, ErrBackfillExhausted) {
+		t.Errorf("Got %v, expected ErrBackfillExhausted", err)
+	}
+
+	next, err := node.GenerateAt(past.Add(time.Millisecond))
+	if err != nil || node.IDStep(next) != 0 || next <= ids[len(ids)-1] {
+		t.Errorf("Got (%d, %v), expected a later ID at step 0", next, err)
+	}
+
+	if live := node.Generate(); live <= next {
+		t.Errorf("Got live ID %d, expected it after the backfilled %d", live, next)
+	}
+
+	for _, bad := range []time.Time{time.Now().Add(time.Hour), time.Now().Add(time.Millisecond)} {
+		if _, err := node.GenerateAt(bad); !errors.Is(err, ErrBackfillNotPast) {
+			t.Errorf("Got %v for %s, expected ErrBackfillNotPast", err, bad)
+		}
+	}
+
+	if _, err := node.GenerateAt(time.UnixMilli(Epoch - 1)); err == nil {
+		t.Error("Expected an error for a time before the epoch")
+	}
+}
@@ -0,0 +1,148 @@
+package snowflake
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// A Block holds IDs reserved in advance by Node.Reserve and hands them out
+// one at a time.  A Block is not safe for concurrent use.
+type Block struct {
+	ids []ID
+	pos int
+}
+
+// Reserve generates count IDs up front under a single lock and returns them
+// as a Block, for callers that allocate IDs before assigning them to records.
+// Reserved IDs that are never taken from the block are burned and leave a
+// gap in the node's sequence.
+func (n *Node) Reserve(count int) (Block, error) {
+	if count <= 0 {
+		return Block{}, errors.New("reserve count must be positive")
+	}
+	if err := n.open(); err != nil {
+		return Block{}, err
+	}
+
+	return Block{ids: n.GenerateN(count)}, nil
+}
+
+// Next returns the next reserved ID, or false once the block is exhausted.
+func (b *Block) Next() (ID, bool) {
+	if b.pos >= len(b.ids) {
+		return 0, false
+	}
+
+	id := b.ids[b.pos]
+	b.pos++
+	return id, true
+}
+
+// Remaining returns the number of IDs left in the block.
+func (b *Block) Remaining() int {
+	return len(b.ids) - b.pos
+}
+
+// An IDRange is a contiguous range of a node's time and step slots reserved
+// by Node.ReserveBlock.  Its IDs are computed on demand rather than stored,
+// and it is safe for concurrent use without locking.
+type IDRange struct {
+	n     *Node
+	first int64 // packed elapsed time and step of the first slot
+	count int64
+	pos   atomic.Int64
+}
+
+// ReserveBlock atomically reserves count consecutive step slots of the node,
+// starting at the next free slot and continuing into following time units
+// as each unit's steps run out, and returns them as an IDRange.  Sharded
+// workers can then draw from the range without contending on the node.
+//
+// A range reaching past the current time is reserved immediately, and later
+// calls to Generate wait for the clock to pass its end instead, so count
+// should stay within the node's throughput for the time the range is used.
+func (n *Node) ReserveBlock(count int) (*IDRange, error) {
+	if count <= 0 {
+		return nil, errors.New("reserve count must be positive")
+	}
+	if err := n.open(); err != nil {
+		return nil, err
+	}
+
+	stepBits := n.layout.StepBits
+	last := int64(count) - 1
+
+	var first int64
+	if n.lockFree {
+		for {
+			old := n.state.Load()
+			if old&stateLocked != 0 {
+				n.Lock()
+				n.Unlock()
+				continue
+			}
+
+			first = old + 1
+			if floor := n.elapsed()<<stepBits | n.firstStep(); floor > first {
+				first = floor
+			}
+
+			if n.state.CompareAndSwap(old, first+last) {
+				break
+			}
+		}
+	} else {
+		n.Lock()
+		now := n.elapsed()
+		first = now<<stepBits | n.firstStep()
+		if n.time >= now {
+			first = n.time<<stepBits | n.step + 1
+		}
+
+		end := (first + last) >> stepBits
+		if end > n.reserved && !n.reserve(end) {
+			n.Unlock()
+			return nil, n.storeErr
+		}
+
+		n.time, n.step = end, (first+last)&n.stepMask
+		n.Unlock()
+	}
+
+	n.count.Add(uint64(count))
+	return &IDRange{n: n, first: first, count: int64(count)}, nil
+}
+
+// Next returns the next ID of the range, or false once it is exhausted.
+func (r *IDRange) Next() (ID, bool) {
+	i := r.pos.Add(1) - 1
+	if i >= r.count {
+		return 0, false
+	}
+
+	return r.At(int(i)), true
+}
+
+// At returns the i'th ID of the range without consuming it.  It panics if i
+// is out of range.
+func (r *IDRange) At(i int) ID {
+	if i < 0 || int64(i) >= r.count {
+		panic("snowflake: IDRange index out of range")
+	}
+
+	p := r.first + int64(i)
+	return r.n.compose(p>>r.n.layout.StepBits, r.n.node, p&r.n.stepMask)
+}
+
+// Len returns the number of IDs in the range.
+func (r *IDRange) Len() int {
+	return int(r.count)
+}
+
+// Remaining returns the number of IDs not yet returned by Next.
+func (r *IDRange) Remaining() int {
+	if left := r.count - r.pos.Load(); left > 0 {
+		return int(left)
+	}
+	return 0
+}
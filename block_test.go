@@ -0,0 +1,118 @@
+package snowflake
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestReserve(t *testing.T) {
+	node, _ := NewNode(1)
+
+	block, err := node.Reserve(5000)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var last ID
+	count := 0
+	for {
+		id, ok := block.Next()
+		if !ok {
+			break
+		}
+
+		if id <= last {
+			t.Fatalf("ID %d is not greater than %d", id, last)
+		}
+		last = id
+		count++
+	}
+
+	if count != 5000 {
+		t.Errorf("Got %d IDs, expected 5000", count)
+	}
+
+	if _, ok := block.Next(); ok {
+		t.Error("Expected an exhausted block to keep reporting exhaustion")
+	}
+
+	if block.Remaining() != 0 {
+		t.Errorf("Got %d remaining, expected 0", block.Remaining())
+	}
+
+	if next := node.Generate(); next <= last {
+		t.Errorf("Generated ID %d is not greater than the reserved %d", next, last)
+	}
+
+	if _, err := node.Reserve(0); err == nil {
+		t.Error("Expected an error for a non-positive count")
+	}
+}
+
+func TestReserveBlock(t *testing.T) {
+	for _, opts := range [][]Option{{withMutex()}, nil} {
+		node, _ := NewNode(3, opts...)
+
+		before := node.Generate()
+		r, err := node.ReserveBlock(10000)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		after := node.Generate()
+
+		if r.Len() != 10000 || r.Remaining() != 10000 {
+			t.Fatalf("Got length %d and %d remaining, expected 10000", r.Len(), r.Remaining())
+		}
+
+		// Consume the range from several goroutines at once.
+		var wg sync.WaitGroup
+		results := make(chan ID, r.Len())
+		for w := 0; w < 4; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					id, ok := r.Next()
+					if !ok {
+						return
+					}
+					results <- id
+				}
+			}()
+		}
+		wg.Wait()
+		close(results)
+
+		seen := make(map[ID]bool)
+		for id := range results {
+			if seen[id] {
+				t.Fatalf("Duplicate ID %d", id)
+			}
+			seen[id] = true
+
+			if id <= before || id >= after {
+				t.Fatalf("ID %d is outside (%d, %d)", id, before, after)
+			}
+			if id.Node() != 3 {
+				t.Fatalf("Got node %d, expected 3", id.Node())
+			}
+		}
+
+		if len(seen) != 10000 || r.Remaining() != 0 {
+			t.Errorf("Got %d IDs and %d remaining, expected 10000 and 0", len(seen), r.Remaining())
+		}
+
+		// The range spans milliseconds with contiguous steps.
+		for i := 1; i < r.Len(); i++ {
+			prev, id := r.At(i-1), r.At(i)
+			if id.Step() != (prev.Step()+1)&0xFFF || (id.Step() == 0) != (id.Time() == prev.Time()+1) {
+				t.Fatalf("IDs %s and %s are not contiguous", prev.DebugString(), id.DebugString())
+			}
+		}
+	}
+
+	node, _ := NewNode(1)
+	if _, err := node.ReserveBlock(0); err == nil {
+		t.Error("Expected an error for a non-positive count")
+	}
+}
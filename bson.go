@@ -0,0 +1,65 @@
+package snowflake
+
+import (
+	"encoding/binary"
+	"errors"
+	"strconv"
+)
+
+// BSON type codes of the values an ID is stored as or read from.
+const (
+	bsonString byte = 0x02
+	bsonNull   byte = 0x0A
+	bsonInt32  byte = 0x10
+	bsonInt64  byte = 0x12
+)
+
+// MarshalBSONValue returns the snowflake ID as a BSON int64, so IDs are
+// stored in MongoDB as numbers that sort and index like the IDs.  It
+// implements the bson.ValueMarshaler interface of version 2 of the MongoDB
+// Go driver without this package depending on the driver.
+func (f ID) MarshalBSONValue() (byte, []byte, error) {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], uint64(f))
+	return bsonInt64, b[:], nil
+}
+
+// UnmarshalBSONValue converts a BSON int64 or int32, or a decimal string as
+// stored by code that marshaled IDs as strings, into an ID type.  A BSON null
+// leaves the ID unchanged.  It implements the bson.ValueUnmarshaler interface
+// of version 2 of the MongoDB Go driver.
+func (f *ID) UnmarshalBSONValue(typ byte, data []byte) error {
+	switch typ {
+	case bsonNull:
+		return nil
+
+	case bsonInt64:
+		if len(data) != 8 {
+			return errors.New("invalid BSON int64 length " + strconv.Itoa(len(data)))
+		}
+		*f = ID(int64(binary.LittleEndian.Uint64(data)))
+		return nil
+
+	case bsonInt32:
+		if len(data) != 4 {
+			return errors.New("invalid BSON int32 length " + strconv.Itoa(len(data)))
+		}
+		*f = ID(int32(binary.LittleEndian.Uint32(data)))
+		return nil
+
+	case bsonString:
+		// An int32 length including the trailing NUL, then the bytes.
+		if len(data) < 5 || int(binary.LittleEndian.Uint32(data)) != len(data)-4 || data[len(data)-1] != 0 {
+			return errors.New("invalid BSON string")
+		}
+
+		id, err := parseID(data[4 : len(data)-1])
+		if err != nil {
+			return err
+		}
+		*f = id
+		return nil
+	}
+
+	return errors.New("cannot unmarshal BSON type 0x" + strconv.FormatUint(uint64(typ), 16) + " into a snowflake ID")
+}
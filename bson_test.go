@@ -0,0 +1,59 @@
+package snowflake
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestBSONValue(t *testing.T) {
+	id := ID(0x0102030405060708)
+
+	typ, data, err := id.MarshalBSONValue()
+	if err != nil || typ != 0x12 || !bytes.Equal(data, []byte{8, 7, 6, 5, 4, 3, 2, 1}) {
+		t.Fatalf("Got (0x%x, %x, %v), expected an int64", typ, data, err)
+	}
+
+	var got ID
+	if err := got.UnmarshalBSONValue(typ, data); err != nil || got != id {
+		t.Errorf("Got (%d, %v), expected (%d, nil)", got, err, id)
+	}
+
+	for _, tc := range []struct {
+		name     string
+		typ      byte
+		data     []byte
+		expected ID
+	}{
+		{"int32", 0x10, []byte{0x13, 0x35, 0x00, 0x00}, 13587},
+		{"string", 0x02, []byte{0x06, 0x00, 0x00, 0x00, '1', '3', '5', '8', '7', 0x00}, 13587},
+		{"null", 0x0A, nil, 42},
+	} {
+		got := ID(42)
+		if err := got.UnmarshalBSONValue(tc.typ, tc.data); err != nil || got != tc.expected {
+			t.Errorf("%s: got (%d, %v), expected (%d, nil)", tc.name, got, err, tc.expected)
+		}
+	}
+
+	for _, tc := range []struct {
+		name string
+		typ  byte
+		data []byte
+	}{
+		{"short int64", 0x12, []byte{1, 2, 3}},
+		{"short int32", 0x10, []byte{1}},
+		{"string length", 0x02, []byte{0x09, 0x00, 0x00, 0x00, '1', 0x00}},
+		{"string without NUL", 0x02, []byte{0x02, 0x00, 0x00, 0x00, '1', '2'}},
+		{"double", 0x01, make([]byte, 8)},
+	} {
+		var got ID
+		if err := got.UnmarshalBSONValue(tc.typ, tc.data); err == nil {
+			t.Errorf("%s: expected an error", tc.name)
+		}
+	}
+
+	bad := []byte{0x04, 0x00, 0x00, 0x00, 'a', 'b', 'c', 0x00}
+	if err := new(ID).UnmarshalBSONValue(0x02, bad); !errors.Is(err, ErrInvalidID) {
+		t.Errorf("Got %v, expected ErrInvalidID for a non numeric string", err)
+	}
+}
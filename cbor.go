@@ -0,0 +1,88 @@
+package snowflake
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// ErrInvalidCBOR is returned by UnmarshalCBOR for input that is not a single
+// CBOR integer that fits an ID.
+var ErrInvalidCBOR = errors.New("invalid CBOR snowflake ID")
+
+// MarshalCBOR returns the snowflake ID as a CBOR integer, using the smallest
+// encoding that holds it, which is 9 bytes for IDs generated with the default
+// layout.  This implements cbor.Marshaler from github.com/fxamacker/cbor
+// without this package depending on it.
+func (f ID) MarshalCBOR() ([]byte, error) {
+	v := int64(f)
+	if v < 0 {
+		// Negative integers, major type 1, store -1 - v.
+		return appendCBORHead(make([]byte, 0, 9), 0x20, uint64(-1-v)), nil
+	}
+	return appendCBORHead(make([]byte, 0, 9), 0x00, uint64(v)), nil
+}
+
+func appendCBORHead(b []byte, major byte, arg uint64) []byte {
+	switch {
+	case arg < 24:
+		return append(b, major|byte(arg))
+	case arg <= math.MaxUint8:
+		return append(b, major|24, byte(arg))
+	case arg <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, major|25), uint16(arg))
+	case arg <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, major|26), uint32(arg))
+	}
+	return binary.BigEndian.AppendUint64(append(b, major|27), arg)
+}
+
+// UnmarshalCBOR converts a single CBOR integer of any width into an ID type.
+// CBOR null and undefined leave the ID unchanged.  This implements
+// cbor.Unmarshaler.
+func (f *ID) UnmarshalCBOR(data []byte) error {
+	if len(data) == 1 && (data[0] == 0xf6 || data[0] == 0xf7) {
+		return nil
+	}
+
+	if len(data) == 0 {
+		return ErrInvalidCBOR
+	}
+
+	major, info := data[0]&0xe0, data[0]&0x1f
+	if major != 0x00 && major != 0x20 {
+		return ErrInvalidCBOR
+	}
+
+	var size int
+	switch {
+	case info < 24:
+	case info <= 27:
+		size = 1 << (info - 24)
+	default:
+		return ErrInvalidCBOR
+	}
+
+	if len(data) != 1+size {
+		return ErrInvalidCBOR
+	}
+
+	arg := uint64(info)
+	if size > 0 {
+		arg = 0
+		for _, d := range data[1:] {
+			arg = arg<<8 | uint64(d)
+		}
+	}
+
+	if arg > math.MaxInt64 {
+		return ErrInvalidCBOR
+	}
+
+	if major == 0x20 {
+		*f = ID(-1 - int64(arg))
+	} else {
+		*f = ID(arg)
+	}
+	return nil
+}
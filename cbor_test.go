@@ -0,0 +1,46 @@
+package snowflake
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCBOR(t *testing.T) {
+	for _, tc := range []struct {
+		id      ID
+		encoded []byte
+	}{
+		{0, []byte{0x00}},
+		{23, []byte{0x17}},
+		{24, []byte{0x18, 0x18}},
+		{13587, []byte{0x19, 0x35, 0x13}},
+		{1 << 20, []byte{0x1a, 0x00, 0x10, 0x00, 0x00}},
+		{0x0102030405060708, []byte{0x1b, 1, 2, 3, 4, 5, 6, 7, 8}},
+		{-1, []byte{0x20}},
+		{-1000, []byte{0x39, 0x03, 0xe7}},
+		{-1 << 63, []byte{0x3b, 0x7f, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+	} {
+		b, err := tc.id.MarshalCBOR()
+		if err != nil || !bytes.Equal(b, tc.encoded) {
+			t.Errorf("Got (%x, %v) marshaling %d, expected %x", b, err, tc.id, tc.encoded)
+		}
+
+		var got ID
+		if err := got.UnmarshalCBOR(tc.encoded); err != nil || got != tc.id {
+			t.Errorf("Got (%d, %v) unmarshaling %x, expected (%d, nil)", got, err, tc.encoded, tc.id)
+		}
+	}
+
+	for _, null := range [][]byte{{0xf6}, {0xf7}} {
+		got := ID(42)
+		if err := got.UnmarshalCBOR(null); err != nil || got != 42 {
+			t.Errorf("Got (%d, %v) unmarshaling %x, expected it unchanged", got, err, null)
+		}
+	}
+
+	for _, bad := range [][]byte{nil, {0x19, 0x01}, {0x00, 0x00}, {0x1b, 0x80, 0, 0, 0, 0, 0, 0, 0}, {0x1c}, {0x61, 'x'}, {0xfb, 0, 0, 0, 0, 0, 0, 0, 0}} {
+		if err := new(ID).UnmarshalCBOR(bad); err != ErrInvalidCBOR {
+			t.Errorf("Got %v unmarshaling %x, expected ErrInvalidCBOR", err, bad)
+		}
+	}
+}
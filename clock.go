@@ -0,0 +1,159 @@
+package snowflake
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// A Clock is a source of the current time for a Node.  Now returns the unix
+// time in milliseconds.
+//
+// Custom clocks let tests control time deterministically, simulate skew, or
+// trade precision for a cheaper time source.  Now must be safe for
+// concurrent use.
+type Clock interface {
+	Now() int64
+}
+
+// A NanoClock is a Clock that can also report the unix time in nanoseconds.
+// Nodes whose layout has a time unit finer than a millisecond read NowNano
+// when their clock implements NanoClock; with a plain Clock their IDs only
+// advance once a millisecond.
+type NanoClock interface {
+	Clock
+	NowNano() int64
+}
+
+// nanosOf returns a function reading the unix time in nanoseconds from c.
+func nanosOf(c Clock) func() int64 {
+	if nc, ok := c.(NanoClock); ok {
+		return nc.NowNano
+	}
+	return func() int64 { return c.Now() * int64(time.Millisecond) }
+}
+
+// ClockFunc adapts an ordinary function to the Clock interface.
+type ClockFunc func() int64
+
+// Now returns f().
+func (f ClockFunc) Now() int64 {
+	return f()
+}
+
+// WithClock makes the node read the time from c instead of the system clock.
+func WithClock(c Clock) Option {
+	return func(n *Node) error {
+		if c == nil {
+			return errors.New("clock must not be nil")
+		}
+
+		n.now = nanosOf(c)
+		return nil
+	}
+}
+
+// WithClockCache makes the node read the time from a value refreshed in the
+// background every d, instead of reading its clock on every Generate call.
+// This shortens the time the node lock is held under contention at the cost
+// of IDs lagging the real time by up to d.  The cache keeps the time in
+// nanoseconds, so it serves layouts with a time unit finer than a
+// millisecond too, refreshed from the clock set by WithClock if one is given.
+//
+// While the cached value is stale, IDs keep using the same time unit, so
+// more of its step space is consumed and Generate can run out of steps and
+// wait for the next refresh more often.  d should be well below the time
+// unit, such as 100 * time.Microsecond with the default layout.
+//
+// The refresh goroutine is started once NewNode has succeeded, and stopped
+// by Close.  It is an error to give WithClockCache more than once.
+func WithClockCache(d time.Duration) Option {
+	return func(n *Node) error {
+		if d <= 0 {
+			return errors.New("clock cache duration must be positive")
+		}
+		if n.clockCache != 0 {
+			return errors.New("clock cache given more than once")
+		}
+
+		n.clockCache = d
+		return nil
+	}
+}
+
+// startClockCache replaces the node's clock with a cache of it refreshed in
+// the background, as set up by WithClockCache.
+func (n *Node) startClockCache() {
+	c := &cachedClock{now: n.now, stop: make(chan struct{})}
+	c.ns.Store(c.now())
+	go c.run(n.clockCache)
+
+	n.now = c.ns.Load
+	n.onClose(func() error { c.close(); return nil })
+	runtime.SetFinalizer(n, func(*Node) { c.close() })
+}
+
+// A cachedClock holds the unix time in nanoseconds as of its last refresh
+// from now.  It does not reference its Node so the Node can be garbage
+// collected, which stops the refresh goroutine if the Node was never closed.
+type cachedClock struct {
+	ns       atomic.Int64
+	now      func() int64
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// close stops the refresh goroutine.
+func (c *cachedClock) close() {
+	c.stopOnce.Do(func() { close(c.stop) })
+}
+
+func (c *cachedClock) run(d time.Duration) {
+	t := time.NewTicker(d)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+			c.ns.Store(c.now())
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+// ClockPolicy selects what GenerateSafe does when the clock has moved
+// backwards since the node's last ID, such as after an NTP step.
+type ClockPolicy int
+
+const (
+	// WaitOnBackwardsClock waits for the clock to catch up with the last ID.
+	// This is the default.
+	WaitOnBackwardsClock ClockPolicy = iota
+
+	// ErrorOnBackwardsClock makes GenerateSafe return ErrClockBackwards.
+	ErrorOnBackwardsClock
+)
+
+// ErrClockBackwards is returned by GenerateSafe when the clock is behind the
+// node's last ID and the node uses ErrorOnBackwardsClock.
+var ErrClockBackwards = errors.New("clock moved backwards")
+
+// WithClockPolicy sets how the node handles the clock moving backwards.
+//
+// Generate cannot return an error, so it never reuses an earlier
+// millisecond; the policy applies to GenerateSafe.  Lock free nodes, the
+// default, keep counting from their last millisecond until its steps run
+// out, and nodes with a StateStore wait for the clock to catch up.
+func WithClockPolicy(p ClockPolicy) Option {
+	return func(n *Node) error {
+		if p != WaitOnBackwardsClock && p != ErrorOnBackwardsClock {
+			return errors.New("unknown clock policy")
+		}
+
+		n.policy = p
+		return nil
+	}
+}
@@ -0,0 +1,211 @@
+package snowflake
+
+import (
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithClockCache(t *testing.T) {
+	node, err := NewNode(1, WithClockCache(100*time.Microsecond))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var last ID
+	for i := 0; i < 20000; i++ {
+		id := node.Generate()
+		if id <= last {
+			t.Fatalf("ID %d is not greater than %d", id, last)
+		}
+		last = id
+	}
+
+	if now := time.Now().UnixNano() / 1000000; now-last.Time() > 50 {
+		t.Errorf("Got time %d, expected close to %d", last.Time(), now)
+	}
+
+	if _, err := NewNode(1, WithClockCache(0)); err == nil {
+		t.Error("Expected an error for a non-positive duration")
+	}
+	if _, err := NewNode(1, WithClockCache(time.Millisecond), WithClockCache(time.Millisecond)); err == nil {
+		t.Error("Expected an error for a second clock cache")
+	}
+
+	// A node rejected by NewNode must not leave a refresh goroutine behind.
+	before := runtime.NumGoroutine()
+	if _, err := NewNode(-1, WithClockCache(time.Millisecond)); err == nil {
+		t.Fatal("Expected an error for an invalid node number")
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		t.Errorf("Got %d goroutines after a failed NewNode, expected %d", n, before)
+	}
+}
+
+func TestClockCacheNanos(t *testing.T) {
+	clock := &atomicNanoClock{}
+	clock.ns.Store(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC).UnixNano())
+
+	node, err := NewNode(1, WithLayout(MicrosecondLayout), WithClock(clock), WithClockCache(100*time.Microsecond))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer node.Close()
+
+	first := node.Decode(node.Generate()).Time
+	if expected := time.Unix(0, clock.NowNano()); !first.Equal(expected) {
+		t.Errorf("Got time %s, expected the cached clock's %s", first, expected)
+	}
+
+	clock.ns.Add(int64(250 * time.Microsecond))
+	deadline := time.Now().Add(time.Second)
+	for node.Decode(node.Generate()).Time.Equal(first) && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got, expected := node.Decode(node.Generate()).Time, time.Unix(0, clock.NowNano()); !got.Equal(expected) {
+		t.Errorf("Got time %s after the clock moved, expected %s", got, expected)
+	}
+}
+
+// atomicNanoClock is a NanoClock safe to move while a clock cache reads it.
+type atomicNanoClock struct{ ns atomic.Int64 }
+
+func (c *atomicNanoClock) Now() int64     { return c.ns.Load() / int64(time.Millisecond) }
+func (c *atomicNanoClock) NowNano() int64 { return c.ns.Load() }
+
+func TestWithClock(t *testing.T) {
+	var now int64 = 1500000000000
+	node, err := NewNode(1, WithClock(ClockFunc(func() int64 { return now })))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	id := node.Generate()
+	if id.Time() != now {
+		t.Errorf("Got time %d, expected %d", id.Time(), now)
+	}
+
+	now += 5
+	if id := node.Generate(); id.Time() != now || id.Step() != 0 {
+		t.Errorf("Got time %d and step %d, expected %d and 0", id.Time(), id.Step(), now)
+	}
+
+	if _, err := NewNode(1, WithClock(nil)); err == nil {
+		t.Error("Expected an error for a nil clock")
+	}
+}
+
+func TestBackwardsClock(t *testing.T) {
+	for _, opts := range [][]Option{{withMutex()}, nil} {
+		now, reads := nowMillis(), -1
+		clock := ClockFunc(func() int64 {
+			// Once stepped back, the clock catches up again after a few reads.
+			if reads >= 0 {
+				if reads++; reads > 10 {
+					return now + 100
+				}
+			}
+			return now
+		})
+
+		node, _ := NewNode(1, append(opts, WithClock(clock))...)
+		first := node.Generate()
+
+		now -= 100
+		reads = 0
+
+		if id := node.Generate(); id <= first {
+			t.Errorf("ID %d is not greater than %d after the clock went backwards", id, first)
+		}
+	}
+}
+
+func TestWithClockPolicy(t *testing.T) {
+	for _, opts := range [][]Option{{withMutex()}, nil} {
+		now := nowMillis()
+		clock := WithClock(ClockFunc(func() int64 { return now }))
+
+		node, err := NewNode(1, append(opts, clock, WithClockPolicy(ErrorOnBackwardsClock))...)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		first, err := node.GenerateSafe()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		now -= 100
+		if _, err := node.GenerateSafe(); err != ErrClockBackwards {
+			t.Errorf("Got error %v, expected ErrClockBackwards", err)
+		}
+
+		now += 101
+		if id, err := node.GenerateSafe(); err != nil || id <= first {
+			t.Errorf("Got %d, %v, expected an ID greater than %d", id, err, first)
+		}
+	}
+
+	if _, err := NewNode(1, WithClockPolicy(ClockPolicy(-1))); err == nil {
+		t.Error("Expected an error for an unknown policy")
+	}
+}
+
+func BenchmarkGenerateParallel(b *testing.B) {
+	node, _ := NewNode(1)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_ = node.Generate()
+		}
+	})
+}
+
+func BenchmarkGenerateClockCache(b *testing.B) {
+	node, _ := NewNode(1, WithClockCache(100*time.Microsecond))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		_ = node.Generate()
+	}
+}
+
+func BenchmarkGenerateClockCacheParallel(b *testing.B) {
+	node, _ := NewNode(1, WithClockCache(100*time.Microsecond))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_ = node.Generate()
+		}
+	})
+}
+
+// BenchmarkLockHold measures the work done while holding the node
+// lock, without the step exhaustion waits that cap Generate's throughput.
+func BenchmarkLockHold(b *testing.B) {
+	for _, bc := range []struct {
+		name string
+		opts []Option
+	}{
+		{"SystemClock", nil},
+		{"ClockCache", []Option{WithClockCache(100 * time.Microsecond)}},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			node, _ := NewNode(1, bc.opts...)
+
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				node.Lock()
+				node.step = 0
+				_ = node.now()
+				node.Unlock()
+			}
+		})
+	}
+}
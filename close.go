@@ -0,0 +1,102 @@
+package snowflake
+
+import (
+	"errors"
+	"math"
+)
+
+// ErrClosed is returned by GenerateSafe, GenerateCtx, GenerateNCtx and the
+// other generating methods that return an error once the node has been
+// closed.  Generate, GenerateUnlocked, GenerateN and WithLock, which cannot
+// return an error, panic with it instead, so servers that may outlive their
+// node should generate with GenerateCtx and GenerateNCtx.
+//
+// Whether the node is closed is checked before generating starts, without
+// ordering it against Close, so a call running concurrently with Close may
+// still return an ID.  Such IDs are still unique, and a StateStore is kept
+// ahead of them.
+var ErrClosed = errors.New("node is closed")
+
+// WithCloser makes Close call fn, for packages that tie a resource such as a
+// leased node number to the node.  Closers run in the reverse order they
+// were added and the first error is returned by Close.
+//
+// WithCloser may be given more than once to add several closers.
+func WithCloser(fn func() error) Option {
+	return func(n *Node) error {
+		if fn == nil {
+			return errors.New("closer must not be nil")
+		}
+
+		n.onClose(fn)
+		return nil
+	}
+}
+
+// Close shuts the node down.  Later calls to generate IDs fail with
+// ErrClosed, and Stream, BatchStream, IDs and Reader stop.  With a
+// StateStore, the time the node actually reached is saved in place of the
+// window reserved ahead, so a restart does not wait out the window.  The
+// WithClockCache refresh goroutine is stopped, and resources tied to the
+// node, such as a node number from NewNodeFromCoordinator, a LocalAllocator
+// or WithCloser, are released so that another node may use them.
+//
+// Calls already generating when Close is called may still return an ID.
+// Calling Close again returns the result of the first call.
+func (n *Node) Close() error {
+	n.closeOnce.Do(func() {
+		n.closed.Store(true)
+		n.closeErr = n.flush()
+
+		for i := len(n.closers) - 1; i >= 0; i-- {
+			if err := n.closers[i](); err != nil && n.closeErr == nil {
+				n.closeErr = err
+			}
+		}
+	})
+	return n.closeErr
+}
+
+// flush saves the last time the node generated an ID in its store.  Calls
+// that were already generating when the node was closed move past the saved
+// time by reserving again, which keeps the store ahead of every ID.
+func (n *Node) flush() error {
+	if n.store == nil {
+		return nil
+	}
+
+	n.Lock()
+	defer n.Unlock()
+
+	if n.time == math.MinInt64 || n.time >= n.reserved {
+		return nil
+	}
+
+	if err := n.store.Save(n.layout.timeOf(n.time, n.epoch)); err != nil {
+		return err
+	}
+	n.reserved = n.time
+	return nil
+}
+
+// onClose registers fn to be called by Close.  Functions run in the reverse
+// order they were registered in.  It must be called before the node is
+// shared.
+func (n *Node) onClose(fn func() error) {
+	n.closers = append(n.closers, fn)
+}
+
+// open returns ErrClosed if the node has been closed.
+func (n *Node) open() error {
+	if n.closed.Load() {
+		return ErrClosed
+	}
+	return nil
+}
+
+// mustOpen panics with ErrClosed if the node has been closed.
+func (n *Node) mustOpen() {
+	if n.closed.Load() {
+		panic(ErrClosed)
+	}
+}
@@ -0,0 +1,180 @@
+package snowflake
+
+import (
+	"context"
+	"errors"
+	"io"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestClose(t *testing.T) {
+	node, _ := NewNode(1)
+	if err := node.Close(); err != nil {
+		t.Errorf("Got %v closing a plain node, expected nil", err)
+	}
+
+	var order []int
+	node, _ = NewNode(1, WithCloser(func() error { order = append(order, 0); return nil }))
+	node.onClose(func() error { order = append(order, 1); return nil })
+	node.onClose(func() error { order = append(order, 2); return errors.New("second") })
+	if err := node.Close(); err == nil || err.Error() != "second" {
+		t.Errorf("Got %v, expected the closer's error", err)
+	}
+	if len(order) != 3 || order[0] != 2 || order[1] != 1 || order[2] != 0 {
+		t.Errorf("Got closers run in order %v, expected [2 1 0]", order)
+	}
+
+	node.Close()
+	if len(order) != 3 {
+		t.Errorf("Closers ran again on the second Close")
+	}
+
+	if _, err := NewNode(1, WithCloser(nil)); err == nil {
+		t.Error("Expected an error for a nil closer")
+	}
+}
+
+func TestCloseGenerate(t *testing.T) {
+	node, _ := NewNode(1, WithRegionBits(2))
+	node.Generate()
+	node.Close()
+
+	checks := map[string]func() error{
+		"GenerateSafe": func() error { _, err := node.GenerateSafe(); return err },
+		"GenerateCtx":  func() error { _, err := node.GenerateCtx(context.Background()); return err },
+		"GenerateRegion": func() error {
+			_, err := node.GenerateRegion(1)
+			return err
+		},
+		"GenerateAt": func() error {
+			_, err := node.GenerateAt(time.Now().Add(-time.Hour))
+			return err
+		},
+		"GenerateInBucket": func() error {
+			_, err := node.GenerateInBucket(time.Now().Add(-time.Hour), time.Now(), 1)
+			return err
+		},
+		"GenerateNCtx": func() error { _, err := node.GenerateNCtx(context.Background(), 2); return err },
+		"Reserve":      func() error { _, err := node.Reserve(1); return err },
+		"ReserveBlock": func() error { _, err := node.ReserveBlock(1); return err },
+		"Reader":       func() error { _, err := node.Reader().Read(make([]byte, 8)); return err },
+	}
+	for name, check := range checks {
+		if err := check(); err != ErrClosed {
+			t.Errorf("Got %v from %s after Close, expected ErrClosed", err, name)
+		}
+	}
+
+	panics := map[string]func(){
+		"Generate":         func() { node.Generate() },
+		"GenerateUnlocked": func() { node.GenerateUnlocked() },
+		"GenerateN":        func() { node.GenerateN(2) },
+		"WithLock":         func() { node.WithLock(func(func() ID) {}) },
+	}
+	for name, fn := range panics {
+		func() {
+			defer func() {
+				if r := recover(); r != ErrClosed {
+					t.Errorf("Got panic %v from %s after Close, expected ErrClosed", r, name)
+				}
+			}()
+			fn()
+		}()
+	}
+
+	if _, ok := <-node.Stream(context.Background()); ok {
+		t.Error("Stream sent an ID after Close")
+	}
+	if _, ok := <-node.BatchStream(context.Background(), 4, time.Second); ok {
+		t.Error("BatchStream sent a batch after Close")
+	}
+}
+
+func TestCloseStopsReader(t *testing.T) {
+	node, _ := NewNode(1)
+	r := node.Reader()
+	if _, err := io.ReadFull(r, make([]byte, 64)); err != nil {
+		t.Fatalf("Unexpected error reading: %v", err)
+	}
+
+	node.Close()
+	if _, err := io.Copy(io.Discard, r); err != ErrClosed {
+		t.Errorf("Got %v reading after Close, expected ErrClosed", err)
+	}
+}
+
+func TestCloseFlushesState(t *testing.T) {
+	store := &memStore{}
+	now := nowMillis()
+	clock := WithClock(ClockFunc(func() int64 { return now }))
+
+	node, err := NewNode(1, clock, WithStateStore(store, time.Hour))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	node.Generate()
+	now += 5
+	last := node.Generate()
+
+	if err := node.Close(); err != nil {
+		t.Fatalf("Unexpected error closing: %v", err)
+	}
+	if expected := time.UnixMilli(now); !store.until.Equal(expected) {
+		t.Errorf("Got state saved until %s after Close, expected %s", store.until, expected)
+	}
+
+	// A restart does not wait out the hour reserved before Close.
+	now++
+	restarted, err := NewNode(1, clock, WithStateStore(store, time.Hour))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if id := restarted.Generate(); id <= last {
+		t.Errorf("Got %d after restarting, expected an ID after %d", id, last)
+	}
+
+	failing := &memStore{}
+	node, _ = NewNode(1, WithStateStore(failing, time.Hour))
+	node.Generate()
+	failing.err = errors.New("store is down")
+	if err := node.Close(); err != failing.err {
+		t.Errorf("Got %v closing with a failing store, expected its error", err)
+	}
+}
+
+func TestCloseStopsClockCache(t *testing.T) {
+	before := runtime.NumGoroutine()
+	node, _ := NewNode(1, WithClockCache(time.Millisecond))
+	node.Close()
+
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		t.Errorf("Got %d goroutines after Close, expected %d", n, before)
+	}
+}
+
+func TestCloseReleasesCoordinator(t *testing.T) {
+	a, _ := NewLocalAllocator(0, 1)
+	node, release, err := NewNodeFromCoordinator(context.Background(), a)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	node.Close()
+	if a.Available() != 1 {
+		t.Errorf("Got %d free node numbers after Close, expected 1", a.Available())
+	}
+
+	// The node number has already been released, so this must not release
+	// the number reserved next.
+	again, _, _ := NewNodeFromCoordinator(context.Background(), a)
+	release()
+	if a.Available() != 0 {
+		t.Errorf("Calling release after Close freed node %d", again.Number())
+	}
+}
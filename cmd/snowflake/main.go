@@ -0,0 +1,171 @@
+// Command snowflake generates, inspects and converts snowflake IDs.
+//
+// Usage:
+//
+//	snowflake generate [-n count] [-node number] [-epoch ms] [-format name]
+//	snowflake inspect [-epoch ms] [-format name] id...
+//	snowflake convert [-from name] [-to name] id...
+//
+// The formats are decimal, hex, base2, base32, base36, base58 and base64.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+)
+
+// A format converts snowflake IDs to and from one string encoding.
+type format struct {
+	format func(snowflake.ID) string
+	parse  func(string) (snowflake.ID, error)
+}
+
+var formats = map[string]format{
+	"decimal": {snowflake.ID.String, snowflake.ParseString},
+	"hex":     {snowflake.ID.Hex, parseHex},
+	"base2":   {snowflake.ID.Base2, snowflake.ParseBase2},
+	"base32":  {snowflake.ID.Base32Fixed, snowflake.ParseBase32Fixed},
+	"base36":  {snowflake.ID.Base36, snowflake.ParseBase36},
+	"base58":  {snowflake.ID.Base58, snowflake.ParseBase58},
+	"base64":  {snowflake.ID.Base64, snowflake.ParseBase64},
+}
+
+// parseHex is snowflake.ParseHex but also accepts a 0x prefix, as hex IDs
+// are often copied from code and logs.
+func parseHex(s string) (snowflake.ID, error) {
+	return snowflake.ParseHex(strings.TrimPrefix(s, "0x"))
+}
+
+func lookupFormat(name string) (format, error) {
+	f, ok := formats[name]
+	if !ok {
+		names := make([]string, 0, len(formats))
+		for n := range formats {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return format{}, fmt.Errorf("unknown format %q, expected one of %s", name, strings.Join(names, ", "))
+	}
+	return f, nil
+}
+
+const usage = `usage:
+  snowflake generate [-n count] [-node number] [-epoch ms] [-format name]
+  snowflake inspect [-epoch ms] [-format name] id...
+  snowflake convert [-from name] [-to name] id...
+`
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "snowflake:", err)
+		os.Exit(1)
+	}
+}
+
+// run executes the command line args, writing results to w.
+func run(args []string, w io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("missing command\n" + usage)
+	}
+
+	switch args[0] {
+	case "generate":
+		return generate(args[1:], w)
+	case "inspect":
+		return inspect(args[1:], w)
+	case "convert":
+		return convert(args[1:], w)
+	}
+
+	return fmt.Errorf("unknown command %q\n%s", args[0], usage)
+}
+
+func generate(args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("generate", flag.ContinueOnError)
+	n := fs.Int("n", 1, "number of IDs to generate")
+	node := fs.Int64("node", 0, "node number")
+	epoch := fs.Int64("epoch", snowflake.Epoch, "epoch in milliseconds since the unix epoch")
+	name := fs.String("format", "decimal", "output format")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *n < 1 {
+		return fmt.Errorf("count must be at least 1, got %d\n%s", *n, usage)
+	}
+
+	f, err := lookupFormat(*name)
+	if err != nil {
+		return err
+	}
+
+	gen, err := snowflake.NodeConfig{Node: *node, Epoch: *epoch}.NewNode()
+	if err != nil {
+		return err
+	}
+
+	for _, id := range gen.GenerateN(*n) {
+		fmt.Fprintln(w, f.format(id))
+	}
+	return nil
+}
+
+func inspect(args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("inspect", flag.ContinueOnError)
+	epoch := fs.Int64("epoch", snowflake.Epoch, "epoch in milliseconds since the unix epoch")
+	name := fs.String("format", "decimal", "input format")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	f, err := lookupFormat(*name)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range fs.Args() {
+		id, err := f.parse(s)
+		if err != nil {
+			return fmt.Errorf("invalid ID %q: %v", s, err)
+		}
+
+		p := snowflake.DefaultLayout.Decode(id, *epoch)
+		fmt.Fprintf(w, "%s\ttime=%s node=%d step=%d\n", s, p.Time.UTC().Format(time.RFC3339Nano), p.Node, p.Step)
+	}
+	return nil
+}
+
+func convert(args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("convert", flag.ContinueOnError)
+	from := fs.String("from", "decimal", "input format")
+	to := fs.String("to", "base58", "output format")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	in, err := lookupFormat(*from)
+	if err != nil {
+		return err
+	}
+	out, err := lookupFormat(*to)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range fs.Args() {
+		id, err := in.parse(s)
+		if err != nil {
+			return fmt.Errorf("invalid ID %q: %v", s, err)
+		}
+		fmt.Fprintln(w, out.format(id))
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/snowflake"
+)
+
+func TestGenerate(t *testing.T) {
+	var out bytes.Buffer
+	if err := run([]string{"generate", "-n", "3", "-node", "5"}, &out); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	lines := strings.Fields(out.String())
+	if len(lines) != 3 {
+		t.Fatalf("Got %d IDs, expected 3", len(lines))
+	}
+
+	for _, l := range lines {
+		id, err := snowflake.ParseString(l)
+		if err != nil {
+			t.Fatalf("Unexpected error parsing %q: %v", l, err)
+		}
+		if id.Node() != 5 {
+			t.Errorf("Got node %d, expected 5", id.Node())
+		}
+	}
+}
+
+func TestInspect(t *testing.T) {
+	id := snowflake.ID(1<<22 | 42<<12 | 7)
+
+	var out bytes.Buffer
+	if err := run([]string{"inspect", "-format", "base58", id.Base58()}, &out); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := id.Base58() + "\ttime=2010-11-04T01:42:54.658Z node=42 step=7\n"
+	if out.String() != expected {
+		t.Errorf("Got %q, expected %q", out.String(), expected)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	id := snowflake.ID(1234567890123)
+
+	for name, f := range formats {
+		var out bytes.Buffer
+		if err := run([]string{"convert", "-to", name, id.String()}, &out); err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+
+		s := strings.TrimSpace(out.String())
+		if got, err := f.parse(s); err != nil || got != id {
+			t.Errorf("%s: got (%d, %v) parsing %q, expected (%d, nil)", name, got, err, s, id)
+		}
+
+		out.Reset()
+		if err := run([]string{"convert", "-from", name, "-to", "decimal", s}, &out); err != nil || strings.TrimSpace(out.String()) != id.String() {
+			t.Errorf("%s: got (%q, %v) converting back, expected %s", name, out.String(), err, id)
+		}
+	}
+	var out bytes.Buffer
+	if err := run([]string{"convert", "-to", "hex", id.String()}, &out); err != nil || strings.TrimSpace(out.String()) != id.Hex() {
+		t.Errorf("Got (%q, %v) converting to hex, expected %s", out.String(), err, id.Hex())
+	}
+	out.Reset()
+	if err := run([]string{"convert", "-from", "hex", "-to", "decimal", "0x" + id.Hex()}, &out); err != nil || strings.TrimSpace(out.String()) != id.String() {
+		t.Errorf("Got (%q, %v) converting 0x%s, expected %s", out.String(), err, id.Hex(), id)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	for _, args := range [][]string{
+		nil,
+		{"explode"},
+		{"generate", "-n", "-1"},
+		{"generate", "-n", "0"},
+		{"convert", "-to", "roman", "1"},
+		{"convert", "12a"},
+		{"inspect", "-format", "hex", "xyz"},
+	} {
+		if err := run(args, &bytes.Buffer{}); err == nil {
+			t.Errorf("Expected an error for %q", args)
+		}
+	}
+}
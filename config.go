@@ -0,0 +1,80 @@
+package snowflake
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// Set parses a decimal snowflake ID into f.  Together with String and Type
+// it makes *ID a flag.Value and a pflag.Value, so IDs can be passed as
+// command line flags with flag.Var.
+func (f *ID) Set(s string) error {
+	id, err := ParseString(s)
+	if err != nil {
+		return err
+	}
+
+	*f = id
+	return nil
+}
+
+// Type returns the name of the flag type for pflag.
+func (f *ID) Type() string {
+	return "snowflakeID"
+}
+
+// NodeConfig holds the settings needed to create a Node, populated from
+// command line flags or the environment for twelve-factor deployments.
+type NodeConfig struct {
+	// Node is the node number.
+	Node int64
+
+	// Epoch is the epoch in milliseconds since the unix epoch.  Zero uses
+	// the package Epoch.
+	Epoch int64
+}
+
+// RegisterFlags registers the -snowflake-node and -snowflake-epoch flags on
+// fs, defaulting to the config's current values.  pflag users can add them
+// with pflag.CommandLine.AddGoFlagSet.
+func (c *NodeConfig) RegisterFlags(fs *flag.FlagSet) {
+	fs.Int64Var(&c.Node, "snowflake-node", c.Node, "snowflake node number")
+	fs.Int64Var(&c.Epoch, "snowflake-epoch", c.Epoch, "snowflake epoch in milliseconds since the unix epoch, or 0 for the default")
+}
+
+// LoadEnv sets the config from the SNOWFLAKE_NODE_ID and SNOWFLAKE_EPOCH
+// environment variables.  Unset variables leave their field unchanged.
+func (c *NodeConfig) LoadEnv() error {
+	for _, v := range []struct {
+		name  string
+		field *int64
+	}{
+		{"SNOWFLAKE_NODE_ID", &c.Node},
+		{"SNOWFLAKE_EPOCH", &c.Epoch},
+	} {
+		s, ok := os.LookupEnv(v.name)
+		if !ok {
+			continue
+		}
+
+		i, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid %s %q: %v", v.name, s, err)
+		}
+		*v.field = i
+	}
+
+	return nil
+}
+
+// NewNode returns a new node using the config and opts.  Options given in
+// opts are applied after the config's, so they take precedence.
+func (c NodeConfig) NewNode(opts ...Option) (*Node, error) {
+	if c.Epoch != 0 {
+		opts = append([]Option{WithEpoch(c.Epoch)}, opts...)
+	}
+
+	return NewNode(c.Node, opts...)
+}
@@ -0,0 +1,71 @@
+package snowflake
+
+import (
+	"flag"
+	"testing"
+)
+
+func TestIDFlag(t *testing.T) {
+	var id ID
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(&id, "id", "snowflake ID")
+
+	if err := fs.Parse([]string{"-id", "1234567890"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if id != 1234567890 {
+		t.Errorf("Got %d, expected 1234567890", id)
+	}
+
+	if err := id.Set("12a"); err == nil {
+		t.Error("Expected an error for an invalid ID")
+	}
+	if id.Type() != "snowflakeID" {
+		t.Errorf("Got type %q, expected snowflakeID", id.Type())
+	}
+}
+
+func TestNodeConfigFlags(t *testing.T) {
+	c := NodeConfig{Node: 3}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	c.RegisterFlags(fs)
+
+	if err := fs.Parse([]string{"-snowflake-epoch", "1420070400000"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	node, err := c.NewNode()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if node.Number() != 3 || node.Epoch() != 1420070400000 {
+		t.Errorf("Got node %d and epoch %d, expected 3 and 1420070400000", node.Number(), node.Epoch())
+	}
+}
+
+func TestNodeConfigLoadEnv(t *testing.T) {
+	t.Setenv("SNOWFLAKE_NODE_ID", "42")
+
+	c := NodeConfig{Epoch: 5}
+	if err := c.LoadEnv(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if c != (NodeConfig{Node: 42, Epoch: 5}) {
+		t.Errorf("Got %+v, expected node 42 and epoch 5", c)
+	}
+
+	node, err := (NodeConfig{Node: 42}).NewNode(WithEpoch(7))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if node.Epoch() != 7 {
+		t.Errorf("Got epoch %d, expected the option's 7", node.Epoch())
+	}
+
+	t.Setenv("SNOWFLAKE_EPOCH", "soon")
+	if err := c.LoadEnv(); err == nil {
+		t.Error("Expected an error for an invalid epoch")
+	}
+}
@@ -0,0 +1,43 @@
+package snowflake
+
+import (
+	"context"
+	"sync"
+)
+
+// A Coordinator hands out node numbers that are unique across a cluster, so
+// processes can get a node number without manual configuration or relying
+// on hostname hashing, which collides.
+//
+// ReserveNodeID returns a free node number and a function releasing it.  The
+// node number stays reserved until release is called or the coordinator
+// loses the reservation, for example when the process dies.
+type Coordinator interface {
+	ReserveNodeID(ctx context.Context) (id int64, release func(), err error)
+}
+
+// NewNodeFromCoordinator reserves a node number from c and returns a new node
+// using it and opts, along with the function releasing the node number.  The
+// node number is released by either calling the release function or closing
+// the node, once the node is no longer used.
+func NewNodeFromCoordinator(ctx context.Context, c Coordinator, opts ...Option) (*Node, func(), error) {
+	id, reserved, err := c.ReserveNodeID(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var once sync.Once
+	release := func() { once.Do(reserved) }
+	opts = append(opts[:len(opts):len(opts)], WithCloser(func() error {
+		release()
+		return nil
+	}))
+
+	n, err := NewNode(id, opts...)
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+
+	return n, release, nil
+}
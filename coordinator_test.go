@@ -0,0 +1,56 @@
+package snowflake
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// counterCoordinator hands out increasing node numbers and records releases.
+type counterCoordinator struct {
+	next     int64
+	released []int64
+}
+
+func (c *counterCoordinator) ReserveNodeID(ctx context.Context) (int64, func(), error) {
+	if err := ctx.Err(); err != nil {
+		return 0, nil, err
+	}
+
+	id := c.next
+	c.next++
+	return id, func() { c.released = append(c.released, id) }, nil
+}
+
+func TestNewNodeFromCoordinator(t *testing.T) {
+	c := &counterCoordinator{next: 5}
+
+	node, release, err := NewNodeFromCoordinator(context.Background(), c)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if id := node.Generate(); id.Node() != 5 {
+		t.Errorf("Got node %d, expected 5", id.Node())
+	}
+
+	release()
+	if len(c.released) != 1 || c.released[0] != 5 {
+		t.Errorf("Got releases %v, expected [5]", c.released)
+	}
+
+	// A node number the layout cannot hold is released again.
+	c.next = 1024
+	if _, _, err := NewNodeFromCoordinator(context.Background(), c); err == nil {
+		t.Error("Expected an error for a node number outside the layout")
+	}
+	if len(c.released) != 2 || c.released[1] != 1024 {
+		t.Errorf("Got releases %v, expected [5 1024]", c.released)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := NewNodeFromCoordinator(ctx, c); !errors.Is(err, context.Canceled) {
+		t.Errorf("Got error %v, expected context.Canceled", err)
+	}
+}
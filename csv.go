@@ -0,0 +1,30 @@
+package snowflake
+
+import "strings"
+
+// MarshalCSV returns the decimal string of the snowflake ID, for CSV
+// libraries such as gocarina/gocsv that look for a MarshalCSV method.
+func (f ID) MarshalCSV() (string, error) {
+	return f.String(), nil
+}
+
+// UnmarshalCSV converts a CSV field holding a decimal snowflake ID into an
+// ID.  It also accepts fields written with ExcelSafeString.  Errors are the
+// same as for UnmarshalText.
+func (f *ID) UnmarshalCSV(s string) error {
+	if strings.HasPrefix(s, `="`) && strings.HasSuffix(s, `"`) && len(s) >= 3 {
+		s = s[2 : len(s)-1]
+	}
+
+	return f.UnmarshalText([]byte(s))
+}
+
+// ExcelSafeString returns the snowflake ID as a spreadsheet formula, such as
+// ="1417819914141990912", that Excel and similar software display as text.
+// Written as a plain number, a 19 digit ID is rounded to 15 significant
+// digits and shown in scientific notation, corrupting it when the file is
+// saved again.  Write the result as a CSV field as is; encoding/csv quotes
+// it as needed.
+func (f ID) ExcelSafeString() string {
+	return `="` + f.String() + `"`
+}
@@ -0,0 +1,48 @@
+package snowflake
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"testing"
+)
+
+func TestCSV(t *testing.T) {
+	id := ID(1417819914141990912)
+
+	if s, err := id.MarshalCSV(); err != nil || s != "1417819914141990912" {
+		t.Errorf("Got (%s, %v), expected (1417819914141990912, nil)", s, err)
+	}
+	if s := id.ExcelSafeString(); s != `="1417819914141990912"` {
+		t.Errorf("Got %s, expected =\"1417819914141990912\"", s)
+	}
+
+	// Round trip both forms through encoding/csv.
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	plain, _ := id.MarshalCSV()
+	w.Write([]string{plain, id.ExcelSafeString()})
+	w.Flush()
+
+	if expected := "1417819914141990912,\"=\"\"1417819914141990912\"\"\"\n"; buf.String() != expected {
+		t.Errorf("Got %q, expected %q", buf.String(), expected)
+	}
+
+	record, err := csv.NewReader(&buf).Read()
+	if err != nil {
+		t.Fatalf("error reading CSV, %s", err)
+	}
+	for _, field := range record {
+		var got ID
+		if err := got.UnmarshalCSV(field); err != nil || got != id {
+			t.Errorf("Got (%d, %v) unmarshaling %q, expected (%d, nil)", got, err, field, id)
+		}
+	}
+
+	var got ID
+	for _, bad := range []string{"", "=\"", "1.4e18", "=\"abc\""} {
+		if err := got.UnmarshalCSV(bad); !errors.Is(err, ErrInvalidID) {
+			t.Errorf("Got %v unmarshaling %q, expected ErrInvalidID", err, bad)
+		}
+	}
+}
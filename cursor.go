@@ -0,0 +1,63 @@
+package snowflake
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+)
+
+// A CursorCodec encodes snowflake IDs as signed pagination cursors.  Since
+// IDs order by creation time, the last ID of a page is all that is needed to
+// fetch the next one, with a query such as id > cursor ORDER BY id.
+//
+// Cursors are URL safe and carry an HMAC-SHA256 tag truncated to 8 bytes, so
+// a client cannot forge or edit one to skip around.  They are opaque to
+// clients by convention only: the ID can be read back from the cursor, so
+// use an Obfuscator as well if the IDs themselves must stay private.
+type CursorCodec struct {
+	key []byte
+}
+
+// ErrInvalidCursor is returned by DecodeCursor for a cursor that was not
+// produced by EncodeCursor with the same key, or was altered.
+var ErrInvalidCursor = errors.New("invalid pagination cursor")
+
+const cursorTagSize = 8
+
+// NewCursorCodec returns a CursorCodec signing cursors with key, which must
+// be at least 16 bytes and should be kept secret.
+func NewCursorCodec(key []byte) (*CursorCodec, error) {
+	if len(key) < 16 {
+		return nil, errors.New("cursor key must be at least 16 bytes")
+	}
+
+	return &CursorCodec{key: append([]byte(nil), key...)}, nil
+}
+
+// EncodeCursor returns a signed cursor pointing at id, 22 characters of
+// unpadded base64url.
+func (c *CursorCodec) EncodeCursor(id ID) string {
+	var b [8 + cursorTagSize]byte
+	binary.BigEndian.PutUint64(b[:8], uint64(id))
+	copy(b[8:], c.tag(b[:8]))
+	return base64.RawURLEncoding.EncodeToString(b[:])
+}
+
+// DecodeCursor returns the ID that EncodeCursor encoded as s, or
+// ErrInvalidCursor if s is malformed or its signature does not match.
+func (c *CursorCodec) DecodeCursor(s string) (ID, error) {
+	b, err := base64.RawURLEncoding.Strict().DecodeString(s)
+	if err != nil || len(b) != 8+cursorTagSize || !hmac.Equal(b[8:], c.tag(b[:8])) {
+		return 0, ErrInvalidCursor
+	}
+
+	return ID(binary.BigEndian.Uint64(b[:8])), nil
+}
+
+func (c *CursorCodec) tag(id []byte) []byte {
+	m := hmac.New(sha256.New, c.key)
+	m.Write(id)
+	return m.Sum(nil)[:cursorTagSize]
+}
@@ -0,0 +1,49 @@
+package snowflake
+
+import (
+	"testing"
+)
+
+func TestCursorCodec(t *testing.T) {
+	codec, err := NewCursorCodec([]byte("0123456789abcdef"))
+	if err != nil {
+		t.Fatalf("error creating CursorCodec, %s", err)
+	}
+
+	node, _ := NewNode(1)
+	for _, id := range []ID{0, 1, node.Generate(), 1<<63 - 1} {
+		cursor := codec.EncodeCursor(id)
+		if len(cursor) != 22 {
+			t.Errorf("Got cursor %s of length %d, expected 22", cursor, len(cursor))
+		}
+
+		got, err := codec.DecodeCursor(cursor)
+		if err != nil || got != id {
+			t.Errorf("Got (%d, %v) decoding %s, expected (%d, nil)", got, err, cursor, id)
+		}
+	}
+
+	cursor := codec.EncodeCursor(node.Generate())
+	for i := range cursor {
+		for _, c := range "ABQgw_-" {
+			if rune(cursor[i]) == c {
+				continue
+			}
+			tampered := cursor[:i] + string(c) + cursor[i+1:]
+			if _, err := codec.DecodeCursor(tampered); err != ErrInvalidCursor {
+				t.Errorf("Got %v decoding tampered cursor %s, expected ErrInvalidCursor", err, tampered)
+			}
+		}
+	}
+
+	other, _ := NewCursorCodec([]byte("fedcba9876543210"))
+	for _, bad := range []string{"", "not a cursor!", cursor[:21], cursor + "A", other.EncodeCursor(1)} {
+		if _, err := codec.DecodeCursor(bad); err != ErrInvalidCursor {
+			t.Errorf("Got %v decoding %q, expected ErrInvalidCursor", err, bad)
+		}
+	}
+
+	if _, err := NewCursorCodec([]byte("short")); err == nil {
+		t.Error("Expected an error for a short key")
+	}
+}
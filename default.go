@@ -0,0 +1,75 @@
+package snowflake
+
+import (
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// defaultNode holds the node used by the package level Generate: the one set
+// with SetDefaultNode, or else the one created from the environment on first
+// use.
+var defaultNode struct {
+	override atomic.Pointer[Node]
+
+	once sync.Once
+	lazy *Node
+	err  error
+}
+
+// Generate creates and returns a unique snowflake ID from the default node,
+// for simple programs that do not want to pass a *Node around.  It panics if
+// the default node cannot be created; call DefaultNode first to handle that
+// error.
+func Generate() ID {
+	n, err := DefaultNode()
+	if err != nil {
+		panic("snowflake: cannot create the default node: " + err.Error())
+	}
+
+	return n.Generate()
+}
+
+// DefaultNode returns the node used by the package level Generate.  Unless
+// one has been set with SetDefaultNode, it is created on first use from the
+// SNOWFLAKE_NODE_ID and SNOWFLAKE_EPOCH environment variables as read by
+// NodeConfig.LoadEnv.  Without SNOWFLAKE_NODE_ID the node number is derived
+// from the hostname like NewNodeByHostname, which may collide; set it in
+// any deployment with more than a few machines.  It is safe for concurrent
+// use, including the first call.
+func DefaultNode() (*Node, error) {
+	if n := defaultNode.override.Load(); n != nil {
+		return n, nil
+	}
+
+	defaultNode.once.Do(func() {
+		defaultNode.lazy, defaultNode.err = newDefaultNode()
+	})
+	return defaultNode.lazy, defaultNode.err
+}
+
+func newDefaultNode() (*Node, error) {
+	var c NodeConfig
+	if _, ok := os.LookupEnv("SNOWFLAKE_NODE_ID"); !ok {
+		id, err := HostnameNodeID()
+		if err != nil {
+			return nil, err
+		}
+		c.Node = id
+	}
+
+	if err := c.LoadEnv(); err != nil {
+		return nil, err
+	}
+	return c.NewNode()
+}
+
+// SetDefaultNode makes n the node used by the package level Generate and
+// returns the node set before, or nil.  Passing nil goes back to the node
+// created from the environment.  Tests can swap in a node with a fake clock
+// and restore the previous one afterwards:
+//
+//	defer snowflake.SetDefaultNode(snowflake.SetDefaultNode(node))
+func SetDefaultNode(n *Node) *Node {
+	return defaultNode.override.Swap(n)
+}
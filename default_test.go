@@ -0,0 +1,54 @@
+package snowflake
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestDefaultNode(t *testing.T) {
+	t.Setenv("SNOWFLAKE_NODE_ID", "77")
+
+	// Concurrent first use creates a single node.
+	nodes := make([]*Node, 8)
+	var wg sync.WaitGroup
+	for i := range nodes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			nodes[i], _ = DefaultNode()
+			Generate()
+		}(i)
+	}
+	wg.Wait()
+
+	for _, n := range nodes {
+		if n == nil || n != nodes[0] {
+			t.Fatalf("Got nodes %v, expected the same default node", nodes)
+		}
+	}
+
+	// No other test creates the default node before this one.
+	if nodes[0].Number() != 77 {
+		t.Errorf("Got node %d, expected 77 from SNOWFLAKE_NODE_ID", nodes[0].Number())
+	}
+}
+
+func TestSetDefaultNode(t *testing.T) {
+	node, _ := NewNode(5)
+	prev := SetDefaultNode(node)
+
+	if n, err := DefaultNode(); err != nil || n != node {
+		t.Errorf("Got (%v, %v), expected the node that was set", n, err)
+	}
+	if id := Generate(); id.Node() != 5 {
+		t.Errorf("Got node %d, expected 5", id.Node())
+	}
+
+	if got := SetDefaultNode(prev); got != node {
+		t.Errorf("Got %v back from SetDefaultNode, expected the node that was set", got)
+	}
+
+	if n, _ := DefaultNode(); n == node {
+		t.Error("Expected the default node to be restored")
+	}
+}
@@ -0,0 +1,344 @@
+package snowflake
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// crockfordAlphabet is Douglas Crockford's base32 alphabet, which leaves out
+// I, L, O and U to avoid ambiguous characters.
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// decodeCrockford maps a byte to its Crockford base32 value, or 0xFF if it
+// is not valid.  Lowercase letters are accepted, as are O for 0 and I and L
+// for 1.
+var decodeCrockford [256]byte
+
+func init() {
+	for i := range decodeCrockford {
+		decodeCrockford[i] = 0xFF
+	}
+
+	for i := 0; i < len(crockfordAlphabet); i++ {
+		c := crockfordAlphabet[i]
+		decodeCrockford[c] = byte(i)
+		if c >= 'A' && c <= 'Z' {
+			decodeCrockford[c+'a'-'A'] = byte(i)
+		}
+	}
+
+	for _, c := range []byte("Oo") {
+		decodeCrockford[c] = 0
+	}
+	for _, c := range []byte("IiLl") {
+		decodeCrockford[c] = 1
+	}
+}
+
+// ErrInvalidBase32Fixed is returned by ParseBase32Fixed for malformed input.
+var ErrInvalidBase32Fixed = errors.New("invalid fixed width base32 ID")
+
+// Base32Fixed returns the snowflake ID as exactly 13 Crockford base32
+// characters (0-9 and A-Z without I, L, O and U), zero padded on the left.
+// 13 characters cover all 63 bits of a non-negative ID, and because the
+// width is fixed the strings sort lexically in the same order as the IDs.
+func (f ID) Base32Fixed() string {
+	var b [13]byte
+
+	v := uint64(f)
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i] = crockfordAlphabet[v&0x1F]
+		v >>= 5
+	}
+
+	return string(b[:])
+}
+
+// ParseBase32Fixed parses a 13 character string returned by Base32Fixed.
+// Decoding is case insensitive and accepts O for 0 and I or L for 1.
+func ParseBase32Fixed(s string) (ID, error) {
+	if len(s) != 13 {
+		return 0, ErrInvalidBase32Fixed
+	}
+
+	var v uint64
+	for i := 0; i < len(s); i++ {
+		d := decodeCrockford[s[i]]
+		if d == 0xFF {
+			return 0, ErrInvalidBase32Fixed
+		}
+		v = v<<5 | uint64(d)
+	}
+
+	// The first character holds only the top 3 bits of a non-negative ID.
+	if decodeCrockford[s[0]] > 7 {
+		return 0, ErrInvalidBase32Fixed
+	}
+
+	return ID(v), nil
+}
+
+// base58Alphabet is the Bitcoin base58 alphabet, which leaves out 0, O, I and
+// l to avoid ambiguous characters.
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// decodeBase58 maps a byte to its base58 value, or 0xFF if it is not valid.
+var decodeBase58 [256]byte
+
+func init() {
+	for i := range decodeBase58 {
+		decodeBase58[i] = 0xFF
+	}
+
+	for i := 0; i < len(base58Alphabet); i++ {
+		decodeBase58[base58Alphabet[i]] = byte(i)
+	}
+}
+
+// ErrInvalidBase58 is returned by ParseBase58 for malformed input.
+var ErrInvalidBase58 = errors.New("invalid base58 ID")
+
+// Base58 returns a base58 string of the snowflake ID using the Bitcoin
+// alphabet, which avoids ambiguous and URL unsafe characters.
+func (f ID) Base58() string {
+	var b [11]byte
+	return string(f.AppendBase58(b[:0]))
+}
+
+// AppendBase58 appends the base58 string of the snowflake ID, as returned by
+// Base58, to dst and returns the extended buffer.
+func (f ID) AppendBase58(dst []byte) []byte {
+	var b [11]byte
+
+	i := len(b)
+	for v := uint64(f); ; v /= 58 {
+		i--
+		b[i] = base58Alphabet[v%58]
+		if v < 58 {
+			break
+		}
+	}
+
+	return append(dst, b[i:]...)
+}
+
+// ParseBase58 parses a base58 string returned by Base58 into a snowflake ID.
+func ParseBase58(s string) (ID, error) {
+	if len(s) == 0 {
+		return 0, ErrInvalidBase58
+	}
+
+	var v int64
+	for i := 0; i < len(s); i++ {
+		d := decodeBase58[s[i]]
+		if d == 0xFF || v > (1<<63-1-int64(d))/58 {
+			return 0, ErrInvalidBase58
+		}
+		v = v*58 + int64(d)
+	}
+
+	return ID(v), nil
+}
+
+// Base returns the snowflake ID in the given base, from 2 to 36, using the
+// lowercase digits of strconv.FormatInt.  It panics for any other base.
+func (f ID) Base(base int) string {
+	return strconv.FormatInt(int64(f), base)
+}
+
+// Hex returns a lowercase hexadecimal string of the snowflake ID.
+func (f ID) Hex() string {
+	return strconv.FormatInt(int64(f), 16)
+}
+
+// ParseHex converts a hexadecimal string, such as one returned by Hex, into a
+// snowflake ID.  Both cases are accepted.
+func ParseHex(id string) (ID, error) {
+	i, err := strconv.ParseInt(id, 16, 64)
+	return ID(i), err
+}
+
+// ErrInvalidBase32 is returned by ParseBase32 and ParseBase32Check for
+// malformed input or a checksum mismatch.
+var ErrInvalidBase32 = errors.New("invalid base32 ID")
+
+// crockfordCheckSymbols are the extra symbols Crockford base32 uses for check
+// values 32 to 36.
+const crockfordCheckSymbols = "*~$=U"
+
+// Base32 returns the snowflake ID as Crockford base32 without padding.  Use
+// Base32Fixed for strings that sort in ID order.
+func (f ID) Base32() string {
+	return f.Encode(crockfordAlphabet)
+}
+
+// ParseBase32 parses a Crockford base32 string, such as one returned by
+// Base32 or Base32Fixed.  Decoding is case insensitive, accepts O for 0 and
+// I or L for 1, and ignores hyphens.
+func ParseBase32(s string) (ID, error) {
+	var v int64
+	digits := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == '-' {
+			continue
+		}
+
+		d := decodeCrockford[s[i]]
+		if d == 0xFF || v > (1<<63-1-int64(d))>>5 {
+			return 0, ErrInvalidBase32
+		}
+		v = v<<5 | int64(d)
+		digits++
+	}
+
+	if digits == 0 {
+		return 0, ErrInvalidBase32
+	}
+
+	return ID(v), nil
+}
+
+// Base32Check returns Base32 followed by Crockford's check symbol, the ID
+// modulo 37, which catches any single mistyped or transposed character.
+func (f ID) Base32Check() string {
+	return f.Base32() + string(crockfordCheck(uint64(f)%37))
+}
+
+// ParseBase32Check parses a string returned by Base32Check and verifies its
+// check symbol.
+func ParseBase32Check(s string) (ID, error) {
+	if len(s) < 2 {
+		return 0, ErrInvalidBase32
+	}
+
+	id, err := ParseBase32(s[:len(s)-1])
+	if err != nil {
+		return 0, err
+	}
+
+	c := s[len(s)-1]
+	if c >= 'a' && c <= 'z' {
+		c -= 'a' - 'A'
+	}
+	if c != crockfordCheck(uint64(id)%37) {
+		return 0, ErrInvalidBase32
+	}
+
+	return id, nil
+}
+
+// ShortCode returns Base32Check split into hyphen separated groups of 4
+// characters, such as "1FVK-SS1F-8580-76", for printing on tickets and other
+// codes that people type in by hand.
+func (f ID) ShortCode() string {
+	s := f.Base32Check()
+
+	b := make([]byte, 0, len(s)+len(s)/4)
+	for i := 0; i < len(s); i++ {
+		if i > 0 && i%4 == 0 {
+			b = append(b, '-')
+		}
+		b = append(b, s[i])
+	}
+	return string(b)
+}
+
+// ParseShortCode parses a code returned by ShortCode as typed in by a person.
+// It is case insensitive, ignores hyphens and spaces, reads O as 0 and I or L
+// as 1, and returns ErrInvalidBase32 for a code with a single mistyped
+// character or two adjacent characters swapped.
+func ParseShortCode(s string) (ID, error) {
+	return ParseBase32Check(strings.Map(func(r rune) rune {
+		if r == ' ' || r == '-' {
+			return -1
+		}
+		return r
+	}, s))
+}
+
+func crockfordCheck(v uint64) byte {
+	if v < 32 {
+		return crockfordAlphabet[v]
+	}
+	return crockfordCheckSymbols[v-32]
+}
+
+// ErrInvalidAlphabet is returned by Decode, and panicked by Encode, when an
+// alphabet has fewer than 2 characters or repeats one.
+var ErrInvalidAlphabet = errors.New("invalid alphabet")
+
+// ErrInvalidEncoding is returned by Decode for a string that is empty, uses a
+// character outside the alphabet or overflows an ID.
+var ErrInvalidEncoding = errors.New("invalid encoded ID")
+
+// Encode returns the snowflake ID in the base of len(alphabet), with each
+// digit written as the corresponding byte of alphabet, most significant
+// first and without padding.  This allows URL safe or vanity alphabets.
+// It panics with ErrInvalidAlphabet for an invalid alphabet.
+func (f ID) Encode(alphabet string) string {
+	if _, err := alphabetTable(alphabet); err != nil {
+		panic(err)
+	}
+
+	var b [64]byte
+
+	base := uint64(len(alphabet))
+	i := len(b)
+	for v := uint64(f); ; v /= base {
+		i--
+		b[i] = alphabet[v%base]
+		if v < base {
+			break
+		}
+	}
+
+	return string(b[i:])
+}
+
+// Decode parses a string returned by Encode with the same alphabet into a
+// snowflake ID.  Decoding is case sensitive.
+func Decode(alphabet, s string) (ID, error) {
+	table, err := alphabetTable(alphabet)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(s) == 0 {
+		return 0, ErrInvalidEncoding
+	}
+
+	base := int64(len(alphabet))
+	var v int64
+	for i := 0; i < len(s); i++ {
+		d := int64(table[s[i]])
+		if d == 0xFF || v > (1<<63-1-d)/base {
+			return 0, ErrInvalidEncoding
+		}
+		v = v*base + d
+	}
+
+	return ID(v), nil
+}
+
+// alphabetTable maps each byte to its digit in alphabet, or 0xFF if it is not
+// in alphabet.
+func alphabetTable(alphabet string) ([256]byte, error) {
+	var table [256]byte
+	for i := range table {
+		table[i] = 0xFF
+	}
+
+	if len(alphabet) < 2 || len(alphabet) > 255 {
+		return table, ErrInvalidAlphabet
+	}
+
+	for i := 0; i < len(alphabet); i++ {
+		if table[alphabet[i]] != 0xFF {
+			return table, ErrInvalidAlphabet
+		}
+		table[alphabet[i]] = byte(i)
+	}
+
+	return table, nil
+}
@@ -0,0 +1,243 @@
+package snowflake
+
+import (
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestBase32Fixed(t *testing.T) {
+	ids := []ID{0, 1, 31, 32, 1<<63 - 1}
+	for i := 0; i < 1000; i++ {
+		ids = append(ids, ID(rand.Int63()))
+	}
+
+	strs := make([]string, len(ids))
+	for i, id := range ids {
+		s := id.Base32Fixed()
+		if len(s) != 13 {
+			t.Fatalf("Got %q of length %d, expected 13", s, len(s))
+		}
+
+		parsed, err := ParseBase32Fixed(s)
+		if err != nil {
+			t.Fatalf("Unexpected error parsing %q: %v", s, err)
+		}
+		if parsed != id {
+			t.Fatalf("Got %d parsing %q, expected %d", parsed, s, id)
+		}
+
+		strs[i] = s
+	}
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	sort.Strings(strs)
+	for i := range ids {
+		if ids[i].Base32Fixed() != strs[i] {
+			t.Fatalf("Lexical order of %q does not match numeric order", strs[i])
+		}
+	}
+
+	if id, err := ParseBase32Fixed("0000000000o1l"); err != nil || id != 33 {
+		t.Errorf("Got (%d, %v) parsing aliases, expected (33, nil)", id, err)
+	}
+
+	for _, bad := range []string{"", "000000000000", "00000000000000", "000000000000U", "8000000000000"} {
+		if _, err := ParseBase32Fixed(bad); err == nil {
+			t.Errorf("Expected an error parsing %q", bad)
+		}
+	}
+}
+
+func TestBase58(t *testing.T) {
+	ids := []ID{0, 1, 57, 58, 1<<63 - 1}
+	for i := 0; i < 1000; i++ {
+		ids = append(ids, ID(rand.Int63()))
+	}
+
+	for _, id := range ids {
+		s := id.Base58()
+
+		parsed, err := ParseBase58(s)
+		if err != nil {
+			t.Fatalf("Unexpected error parsing %q: %v", s, err)
+		}
+		if parsed != id {
+			t.Fatalf("Got %d parsing %q, expected %d", parsed, s, id)
+		}
+	}
+
+	if s := ID(58).Base58(); s != "21" {
+		t.Errorf("Got %q, expected \"21\"", s)
+	}
+
+	for _, bad := range []string{"", "0", "O", "I", "l", "+", "NQm6nKp8qFD", "zzzzzzzzzzzz"} {
+		if _, err := ParseBase58(bad); err == nil {
+			t.Errorf("Expected an error parsing %q", bad)
+		}
+	}
+
+	s := ID(1<<62 + 12345).Base58()
+	if allocs := testing.AllocsPerRun(100, func() { ParseBase58(s) }); allocs != 0 {
+		t.Errorf("Got %v allocations parsing, expected 0", allocs)
+	}
+}
+
+func BenchmarkParseBase58(b *testing.B) {
+	node, _ := NewNode(1)
+	s := node.Generate().Base58()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		_, _ = ParseBase58(s)
+	}
+}
+
+func TestBaseAndHex(t *testing.T) {
+	id := ID(1<<62 + 12345)
+
+	if got, expected := id.Base(7), strconv.FormatInt(int64(id), 7); got != expected {
+		t.Errorf("Got %q, expected %q", got, expected)
+	}
+
+	if got := ID(0xBEEF).Hex(); got != "beef" {
+		t.Errorf("Got %q, expected beef", got)
+	}
+
+	for _, s := range []string{id.Hex(), strings.ToUpper(id.Hex())} {
+		if got, err := ParseHex(s); err != nil || got != id {
+			t.Errorf("Got (%d, %v) parsing %q, expected (%d, nil)", got, err, s, id)
+		}
+	}
+
+	if _, err := ParseHex("xyz"); err == nil {
+		t.Error("Expected an error for invalid hex")
+	}
+}
+
+func TestBase32(t *testing.T) {
+	ids := []ID{0, 1, 31, 32, 36, 37, 1<<63 - 1}
+	for i := 0; i < 1000; i++ {
+		ids = append(ids, ID(rand.Int63()))
+	}
+
+	for _, id := range ids {
+		s := id.Base32()
+		if got, err := ParseBase32(s); err != nil || got != id {
+			t.Fatalf("Got (%d, %v) parsing %q, expected (%d, nil)", got, err, s, id)
+		}
+		if got, err := ParseBase32(id.Base32Fixed()); err != nil || got != id {
+			t.Fatalf("Got (%d, %v) parsing %q, expected (%d, nil)", got, err, id.Base32Fixed(), id)
+		}
+
+		c := id.Base32Check()
+		if got, err := ParseBase32Check(strings.ToLower(c)); err != nil || got != id {
+			t.Fatalf("Got (%d, %v) parsing %q, expected (%d, nil)", got, err, c, id)
+		}
+	}
+
+	if got := ID(1234).Base32(); got != "16J" {
+		t.Errorf("Got %q, expected 16J", got)
+	}
+	if got := ID(36).Base32Check(); got != "14U" {
+		t.Errorf("Got %q, expected 14U", got)
+	}
+	if got, err := ParseBase32("1-6j"); err != nil || got != 1234 {
+		t.Errorf("Got (%d, %v), expected (1234, nil)", got, err)
+	}
+
+	for _, bad := range []string{"", "-", "U", "8000000000000"} {
+		if _, err := ParseBase32(bad); err != ErrInvalidBase32 {
+			t.Errorf("Got %v parsing %q, expected ErrInvalidBase32", err, bad)
+		}
+	}
+
+	// "16JD" with the first two characters transposed.
+	if _, err := ParseBase32Check("61JD"); err != ErrInvalidBase32 {
+		t.Errorf("Got %v, expected ErrInvalidBase32", err)
+	}
+}
+
+func TestEncode(t *testing.T) {
+	const urlSafe = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz-_"
+
+	for _, id := range []ID{0, 1, 63, 64, 1<<63 - 1, ID(rand.Int63())} {
+		for _, alphabet := range []string{"01", crockfordAlphabet, base58Alphabet, urlSafe} {
+			s := id.Encode(alphabet)
+			if got, err := Decode(alphabet, s); err != nil || got != id {
+				t.Errorf("Got (%d, %v) decoding %q, expected (%d, nil)", got, err, s, id)
+			}
+		}
+	}
+
+	if got := ID(1234).Encode("01"); got != ID(1234).Base2() {
+		t.Errorf("Got %q, expected %q", got, ID(1234).Base2())
+	}
+	if got := ID(4095).Encode(urlSafe); got != "__" {
+		t.Errorf("Got %q, expected __", got)
+	}
+
+	for _, bad := range []string{"", "0", "0120"} {
+		if _, err := Decode(bad, "0"); err != ErrInvalidAlphabet {
+			t.Errorf("Got %v for alphabet %q, expected ErrInvalidAlphabet", err, bad)
+		}
+	}
+
+	for _, bad := range []string{"", "012", "11111111111111111111111111111111111111111111111111111111111111111"} {
+		if _, err := Decode("01", bad); err != ErrInvalidEncoding {
+			t.Errorf("Got %v decoding %q, expected ErrInvalidEncoding", err, bad)
+		}
+	}
+
+	defer func() {
+		if recover() != ErrInvalidAlphabet {
+			t.Error("Expected Encode to panic with ErrInvalidAlphabet")
+		}
+	}()
+	ID(1).Encode("aa")
+}
+
+func TestShortCode(t *testing.T) {
+	if got := ID(1234).ShortCode(); got != "16JD" {
+		t.Errorf("Got %q, expected 16JD", got)
+	}
+	if got := ID(1<<63 - 1).ShortCode(); got != "7ZZZ-ZZZZ-ZZZZ-Z5" {
+		t.Errorf("Got %q, expected 7ZZZ-ZZZZ-ZZZZ-Z5", got)
+	}
+
+	for i := 0; i < 200; i++ {
+		id := ID(rand.Int63())
+		code := id.ShortCode()
+
+		for _, typed := range []string{code, strings.ToLower(code), strings.ReplaceAll(code, "-", " "), strings.ReplaceAll(code, "-", "")} {
+			if got, err := ParseShortCode(typed); err != nil || got != id {
+				t.Fatalf("Got (%d, %v) parsing %q, expected (%d, nil)", got, err, typed, id)
+			}
+		}
+
+		// Every single character substitution and adjacent swap is detected.
+		plain := id.Base32Check()
+		for j := 0; j < len(plain); j++ {
+			for k := 0; k < len(crockfordAlphabet); k++ {
+				c := crockfordAlphabet[k]
+				if c == plain[j] || j == len(plain)-1 {
+					continue
+				}
+				typo := plain[:j] + string(c) + plain[j+1:]
+				if got, err := ParseShortCode(typo); err == nil {
+					t.Fatalf("Got %d parsing typo %q of %q, expected an error", got, typo, plain)
+				}
+			}
+
+			if j > 0 && plain[j] != plain[j-1] {
+				swapped := plain[:j-1] + string(plain[j]) + string(plain[j-1]) + plain[j+1:]
+				if got, err := ParseShortCode(swapped); err == nil {
+					t.Fatalf("Got %d parsing swapped %q of %q, expected an error", got, swapped, plain)
+				}
+			}
+		}
+	}
+}
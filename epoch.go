@@ -0,0 +1,35 @@
+package snowflake
+
+import (
+	"errors"
+	"time"
+)
+
+// DecodeMultiEpoch decodes the time of a snowflake ID whose epoch is one of
+// several candidates, such as IDs spanning epoch migrations.  A candidate fits
+// if it decodes id to a time between the epoch itself and now, and the latest
+// fitting epoch is chosen, since an ID decoded with an epoch older than its
+// own always appears older but still fits.  The decoded time and chosen epoch
+// are returned, or an error if no epoch fits.
+//
+// The result is ambiguous when more than one epoch fits: an old ID decoded
+// with a newer epoch only fails to fit if that would place it in the future,
+// which is not the case for IDs created long before the migration.  Epochs
+// that are far apart relative to the age of the data decode most reliably.
+func DecodeMultiEpoch(id ID, epochs []int64) (time.Time, int64, error) {
+	now := time.Now().UnixNano() / 1000000
+	raw := int64(id) >> timeShift
+
+	best, found := int64(0), false
+	for _, epoch := range epochs {
+		if ms := raw + epoch; ms >= epoch && ms <= now && (!found || epoch > best) {
+			best, found = epoch, true
+		}
+	}
+
+	if !found {
+		return time.Time{}, 0, errors.New("no epoch decodes the ID to a time before now")
+	}
+
+	return time.UnixMilli(raw + best), best, nil
+}
@@ -0,0 +1,36 @@
+package snowflake
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDecodeMultiEpoch(t *testing.T) {
+	now := time.Now().UnixNano() / 1000000
+	oldEpoch := now - 10*365*24*3600*1000
+	newEpoch := now - 24*3600*1000
+	epochs := []int64{oldEpoch, newEpoch}
+
+	created := now - 1000
+	for _, epoch := range epochs {
+		id := ID((created-epoch)<<timeShift | 1<<nodeShift)
+
+		tm, got, err := DecodeMultiEpoch(id, epochs)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if got != epoch {
+			t.Errorf("Got epoch %d, expected %d", got, epoch)
+		}
+
+		if tm.UnixNano()/1000000 != created {
+			t.Errorf("Got time %d, expected %d", tm.UnixNano()/1000000, created)
+		}
+	}
+
+	future := ID((now + 3600*1000 - oldEpoch) << timeShift)
+	if _, _, err := DecodeMultiEpoch(future, epochs); err == nil {
+		t.Error("Expected an error when no epoch fits")
+	}
+}
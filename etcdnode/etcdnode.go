@@ -0,0 +1,136 @@
+// Package etcdnode is a snowflake.Coordinator handing out node numbers
+// through etcd, so a cluster can share the node range without manual
+// configuration.
+//
+// Each reservation is a key created under an etcd lease, which is kept alive
+// until the node number is released and expires if the process dies.  The
+// package talks to etcd through the small Client interface rather than
+// importing the etcd client, so adapting clientv3 takes a few lines.
+package etcdnode
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// Client is the subset of etcd operations used to reserve node numbers.  With
+// clientv3, Grant wraps Lease.Grant, KeepAlive wraps Lease.KeepAlive and
+// closes its channel once the response channel closes, Create is a Txn
+// putting the key with the lease if its CreateRevision is 0, and Revoke wraps
+// Lease.Revoke.
+type Client interface {
+	// Grant creates a lease expiring after ttl unless kept alive and returns
+	// its ID.
+	Grant(ctx context.Context, ttl time.Duration) (int64, error)
+
+	// KeepAlive keeps the lease alive until ctx is done.  The returned
+	// channel is closed when the lease can no longer be kept alive.
+	KeepAlive(ctx context.Context, lease int64) (<-chan struct{}, error)
+
+	// Create creates key with value attached to the lease if it does not
+	// exist and reports whether it was created.
+	Create(ctx context.Context, key, value string, lease int64) (bool, error)
+
+	// Revoke revokes the lease, deleting the keys attached to it.
+	Revoke(ctx context.Context, lease int64) error
+}
+
+// ErrNoFreeNode is returned by ReserveNodeID when every node number is
+// reserved.
+var ErrNoFreeNode = errors.New("etcdnode: no free node number")
+
+// Config configures how node numbers are reserved.  The zero value uses the
+// defaults.
+type Config struct {
+	// Prefix is prepended to the node number to form the key.  It defaults
+	// to "/snowflake/nodes/".
+	Prefix string
+
+	// TTL is how long a reservation outlives its process.  It defaults to
+	// 10 seconds.
+	TTL time.Duration
+
+	// Nodes is the number of node numbers to hand out, starting at 0.  It
+	// defaults to 1024, the node range of snowflake.DefaultLayout.
+	Nodes int64
+
+	// Value is stored in each reserved key to show which process holds it,
+	// such as the hostname.
+	Value string
+
+	// OnLost is called with the node number if its lease expires before it
+	// was released, after which another process may reserve it.
+	OnLost func(id int64)
+}
+
+// A Coordinator reserves node numbers in etcd.  It implements
+// snowflake.Coordinator.
+type Coordinator struct {
+	c   Client
+	cfg Config
+}
+
+// New returns a Coordinator reserving node numbers through c.
+func New(c Client, cfg Config) *Coordinator {
+	if cfg.Prefix == "" {
+		cfg.Prefix = "/snowflake/nodes/"
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = 10 * time.Second
+	}
+	if cfg.Nodes <= 0 {
+		cfg.Nodes = 1024
+	}
+
+	return &Coordinator{c: c, cfg: cfg}
+}
+
+// ReserveNodeID reserves the lowest free node number.  Calling release
+// revokes the lease, freeing the node number immediately.
+func (co *Coordinator) ReserveNodeID(ctx context.Context) (int64, func(), error) {
+	lease, err := co.c.Grant(ctx, co.cfg.TTL)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	for id := int64(0); id < co.cfg.Nodes; id++ {
+		ok, err := co.c.Create(ctx, co.cfg.Prefix+strconv.FormatInt(id, 10), co.cfg.Value, lease)
+		if err != nil {
+			co.c.Revoke(context.Background(), lease)
+			return 0, nil, err
+		}
+		if ok {
+			return co.keepAlive(id, lease)
+		}
+	}
+
+	co.c.Revoke(context.Background(), lease)
+	return 0, nil, ErrNoFreeNode
+}
+
+func (co *Coordinator) keepAlive(id, lease int64) (int64, func(), error) {
+	kctx, cancel := context.WithCancel(context.Background())
+
+	done, err := co.c.KeepAlive(kctx, lease)
+	if err != nil {
+		cancel()
+		co.c.Revoke(context.Background(), lease)
+		return 0, nil, err
+	}
+
+	go func() {
+		<-done
+		if kctx.Err() == nil && co.cfg.OnLost != nil {
+			co.cfg.OnLost(id)
+		}
+	}()
+
+	release := func() {
+		cancel()
+		co.c.Revoke(context.Background(), lease)
+	}
+
+	return id, release, nil
+}
@@ -0,0 +1,136 @@
+package etcdnode
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+)
+
+var _ snowflake.Coordinator = (*Coordinator)(nil)
+
+// fakeEtcd implements Client in memory.
+type fakeEtcd struct {
+	sync.Mutex
+	next   int64
+	keys   map[string]int64
+	alive  map[int64]chan struct{}
+	values map[string]string
+}
+
+func newFakeEtcd() *fakeEtcd {
+	return &fakeEtcd{keys: make(map[string]int64), alive: make(map[int64]chan struct{}), values: make(map[string]string)}
+}
+
+func (e *fakeEtcd) Grant(ctx context.Context, ttl time.Duration) (int64, error) {
+	e.Lock()
+	defer e.Unlock()
+
+	e.next++
+	e.alive[e.next] = make(chan struct{})
+	return e.next, nil
+}
+
+func (e *fakeEtcd) KeepAlive(ctx context.Context, lease int64) (<-chan struct{}, error) {
+	e.Lock()
+	defer e.Unlock()
+
+	return e.alive[lease], nil
+}
+
+func (e *fakeEtcd) Create(ctx context.Context, key, value string, lease int64) (bool, error) {
+	e.Lock()
+	defer e.Unlock()
+
+	if _, ok := e.keys[key]; ok {
+		return false, nil
+	}
+	e.keys[key] = lease
+	e.values[key] = value
+	return true, nil
+}
+
+func (e *fakeEtcd) Revoke(ctx context.Context, lease int64) error {
+	e.Lock()
+	defer e.Unlock()
+
+	for k, l := range e.keys {
+		if l == lease {
+			delete(e.keys, k)
+			delete(e.values, k)
+		}
+	}
+	if ch, ok := e.alive[lease]; ok {
+		close(ch)
+		delete(e.alive, lease)
+	}
+	return nil
+}
+
+// expire simulates the lease expiring without being revoked.
+func (e *fakeEtcd) expire(lease int64) {
+	e.Revoke(context.Background(), lease)
+}
+
+func TestReserveNodeID(t *testing.T) {
+	e := newFakeEtcd()
+	co := New(e, Config{Nodes: 2, Value: "host-a"})
+	ctx := context.Background()
+
+	a, releaseA, err := co.ReserveNodeID(ctx)
+	if err != nil || a != 0 {
+		t.Fatalf("Got (%d, %v), expected (0, nil)", a, err)
+	}
+	if v := e.values["/snowflake/nodes/0"]; v != "host-a" {
+		t.Errorf("Got value %q, expected host-a", v)
+	}
+
+	b, releaseB, err := co.ReserveNodeID(ctx)
+	if err != nil || b != 1 {
+		t.Fatalf("Got (%d, %v), expected (1, nil)", b, err)
+	}
+
+	if _, _, err := co.ReserveNodeID(ctx); err != ErrNoFreeNode {
+		t.Fatalf("Got error %v, expected ErrNoFreeNode", err)
+	}
+
+	releaseA()
+	if c, _, err := co.ReserveNodeID(ctx); err != nil || c != 0 {
+		t.Errorf("Got (%d, %v), expected the released node (0, nil)", c, err)
+	}
+
+	releaseB()
+	if len(e.alive) != 1 {
+		t.Errorf("Got %d live leases, expected 1", len(e.alive))
+	}
+}
+
+func TestReserveNodeIDLost(t *testing.T) {
+	e := newFakeEtcd()
+
+	lost := make(chan int64, 1)
+	co := New(e, Config{OnLost: func(id int64) { lost <- id }})
+
+	node, release, err := snowflake.NewNodeFromCoordinator(context.Background(), co)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer release()
+
+	if id := node.Generate(); id.Node() != 0 {
+		t.Errorf("Got node %d, expected 0", id.Node())
+	}
+
+	e.expire(e.keys["/snowflake/nodes/0"])
+
+	select {
+	case id := <-lost:
+		if id != 0 {
+			t.Errorf("Got lost node %d, expected 0", id)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Lost lease was not reported")
+	}
+}
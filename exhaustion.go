@@ -0,0 +1,56 @@
+package snowflake
+
+import "errors"
+
+// ExhaustionPolicy selects what a node does when the step of the current
+// time unit is used up, after 2^StepBits IDs in one millisecond with the
+// default layout.
+type ExhaustionPolicy int
+
+const (
+	// SpinOnExhausted busy waits, yielding the processor, until the clock
+	// reaches the next time unit.  It has the lowest latency and is the
+	// default.
+	SpinOnExhausted ExhaustionPolicy = iota
+
+	// SleepOnExhausted sleeps until the clock reaches the next time unit,
+	// freeing the CPU at the cost of waking up late, as sleeps are coarser
+	// than a millisecond on many systems.  Only nodes with a StateStore
+	// keep the node lock held while sleeping; lock free nodes sleep without
+	// it, so other goroutines may take the next time unit's steps first.
+	SleepOnExhausted
+
+	// ErrorOnExhausted makes GenerateSafe return ErrSequenceExhausted
+	// instead of waiting.  Generate cannot return an error, so it still
+	// spins.
+	ErrorOnExhausted
+
+	// BorrowOnExhausted moves the node on to the next time unit straight
+	// away, ahead of the clock, so bursts never wait.  IDs stay unique and
+	// increasing but their times run ahead of the real time for as long as
+	// the burst outpaces the step space, and fall back in line once the
+	// rate drops.  Stats.Borrowed counts how often this happens.
+	//
+	// While the node is ahead it cannot tell that from the clock moving
+	// backwards, so it keeps counting from its last time instead of waiting
+	// for either, and GenerateSafe reports ErrClockBackwards for both when
+	// the node also uses ErrorOnBackwardsClock.
+	BorrowOnExhausted
+)
+
+// ErrSequenceExhausted is returned by GenerateSafe when the step of the
+// current time unit is used up and the node uses ErrorOnExhausted.
+var ErrSequenceExhausted = errors.New("sequence exhausted for the current time")
+
+// WithExhaustionPolicy sets what the node does when the step of the current
+// time unit is used up.
+func WithExhaustionPolicy(p ExhaustionPolicy) Option {
+	return func(n *Node) error {
+		if p < SpinOnExhausted || p > BorrowOnExhausted {
+			return errors.New("unknown exhaustion policy")
+		}
+
+		n.exhaustion = p
+		return nil
+	}
+}
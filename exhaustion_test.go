@@ -0,0 +1,107 @@
+package snowflake
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// exhaustionLayout has 4 steps per millisecond.
+var exhaustionLayout = Layout{NodeBits: 10, StepBits: 2}
+
+func TestErrorOnExhausted(t *testing.T) {
+	for _, lockFree := range []bool{false, true} {
+		now := Epoch + 1000
+		opts := []Option{
+			WithLayout(exhaustionLayout),
+			WithClock(ClockFunc(func() int64 { return now })),
+			WithExhaustionPolicy(ErrorOnExhausted),
+		}
+		if !lockFree {
+			opts = append(opts, withMutex())
+		}
+		node, _ := NewNode(1, opts...)
+
+		for i := 0; i < 4; i++ {
+			if _, err := node.GenerateSafe(); err != nil {
+				t.Fatalf("Got %v for step %d, expected nil", err, i)
+			}
+		}
+		if _, err := node.GenerateSafe(); !errors.Is(err, ErrSequenceExhausted) {
+			t.Errorf("Got %v with lock free %v, expected ErrSequenceExhausted", err, lockFree)
+		}
+		if s := node.Stats(); s.Exhausted != 1 || s.Generated != 4 {
+			t.Errorf("Got %+v, expected 1 exhausted and 4 generated", s)
+		}
+
+		now++
+		if id, err := node.GenerateSafe(); err != nil || node.IDStep(id) != 0 {
+			t.Errorf("Got (%d, %v) in the next millisecond, expected step 0", id, err)
+		}
+	}
+}
+
+func TestBorrowOnExhausted(t *testing.T) {
+	for _, lockFree := range []bool{false, true} {
+		now := Epoch + 1000
+		opts := []Option{
+			WithLayout(exhaustionLayout),
+			WithClock(ClockFunc(func() int64 { return now })),
+			WithExhaustionPolicy(BorrowOnExhausted),
+		}
+		if !lockFree {
+			opts = append(opts, withMutex())
+		}
+		node, _ := NewNode(1, opts...)
+
+		// With a frozen clock, 12 IDs run 2 milliseconds ahead.
+		var last ID
+		for i := 0; i < 12; i++ {
+			id := node.Generate()
+			if id <= last {
+				t.Fatalf("ID %d is not greater than %d", id, last)
+			}
+			last = id
+		}
+
+		if got := node.IDTime(last); got != now+2 {
+			t.Errorf("Got time %d with lock free %v, expected %d", got, lockFree, now+2)
+		}
+		if s := node.Stats(); s.Borrowed != 2 {
+			t.Errorf("Got %d borrowed with lock free %v, expected 2", s.Borrowed, lockFree)
+		}
+
+		now += 10
+		if id := node.Generate(); node.IDTime(id) != now {
+			t.Errorf("Got time %d once the clock moved on, expected %d", node.IDTime(id), now)
+		}
+	}
+
+	node, _ := NewNode(1, WithLayout(exhaustionLayout), WithExhaustionPolicy(BorrowOnExhausted))
+	ids := node.GenerateN(100)
+	for i := 1; i < len(ids); i++ {
+		if ids[i] <= ids[i-1] {
+			t.Fatalf("ID %d is not greater than %d", ids[i], ids[i-1])
+		}
+	}
+}
+
+func TestSleepOnExhausted(t *testing.T) {
+	node, _ := NewNode(1, WithLayout(exhaustionLayout), WithExhaustionPolicy(SleepOnExhausted))
+
+	start := time.Now()
+	ids := node.GenerateN(20)
+	if d := time.Since(start); d < 3*time.Millisecond {
+		t.Errorf("Got 20 IDs in %s, expected at least 4 milliseconds of steps", d)
+	}
+
+	for i := 1; i < len(ids); i++ {
+		if ids[i] <= ids[i-1] {
+			t.Fatalf("ID %d is not greater than %d", ids[i], ids[i-1])
+		}
+	}
+
+	if _, err := NewNode(1, WithExhaustionPolicy(ExhaustionPolicy(9))); err == nil {
+		t.Error("Expected an error for an unknown policy")
+	}
+}
@@ -0,0 +1,56 @@
+package snowflake
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// LogParts makes ID.LogValue log snowflake IDs as a group of their decimal
+// string, time, node and step instead of just the string, so structured logs
+// are self describing without decoding IDs by hand.  Like Epoch it should be
+// set once before logging begins.
+var LogParts = false
+
+// LogValue implements slog.LogValuer.  It logs the decimal string of the
+// snowflake ID, or with LogParts set a group of id, time, node and step
+// attributes decoded using DefaultLayout and Epoch.
+func (f ID) LogValue() slog.Value {
+	if !LogParts {
+		return slog.StringValue(f.String())
+	}
+
+	return slog.GroupValue(
+		slog.String("id", f.String()),
+		slog.Time("time", time.UnixMilli(f.Time()).UTC()),
+		slog.Int64("node", f.Node()),
+		slog.Int64("step", f.Step()),
+	)
+}
+
+// Format implements fmt.Formatter so that every verb formats the snowflake
+// ID's value, where fmt would otherwise hex encode the String for %x.  %v and
+// %s print the decimal string and %q quotes it, %+v adds the time, node and
+// step decoded using DefaultLayout and Epoch, as in
+// 1417819914141990912(time=2021-07-21T12:13:04.008Z node=648 step=0), and
+// %#v prints snowflake.ID(1417819914141990912).  %d, %x, %X, %o, %O and %b
+// format the integer.  Width and flags are honoured as for an int64.
+func (f ID) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			fmt.Fprintf(s, "%d(time=%s node=%d step=%d)", int64(f),
+				time.UnixMilli(f.Time()).UTC().Format(time.RFC3339Nano), f.Node(), f.Step())
+			return
+		}
+		if s.Flag('#') {
+			fmt.Fprintf(s, "snowflake.ID(%d)", int64(f))
+			return
+		}
+		fmt.Fprintf(s, fmt.FormatString(s, 's'), f.String())
+	case 's', 'q':
+		fmt.Fprintf(s, fmt.FormatString(s, verb), f.String())
+	default:
+		fmt.Fprintf(s, fmt.FormatString(s, verb), int64(f))
+	}
+}
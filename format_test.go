@@ -0,0 +1,64 @@
+package snowflake
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	id := ID(1417819914141990912)
+
+	for format, expected := range map[string]string{
+		"%v":   "1417819914141990912",
+		"%s":   "1417819914141990912",
+		"%d":   "1417819914141990912",
+		"%q":   `"1417819914141990912"`,
+		"%x":   "13ad1bb331e88000",
+		"%X":   "13AD1BB331E88000",
+		"%#x":  "0x13ad1bb331e88000",
+		"%#v":  "snowflake.ID(1417819914141990912)",
+		"%+v":  "1417819914141990912(time=2021-07-21T12:13:04.008Z node=648 step=0)",
+		"%22v": "   1417819914141990912",
+		"%-4d": "1417819914141990912",
+	} {
+		if got := fmt.Sprintf(format, id); got != expected {
+			t.Errorf("Got %s formatting with %s, expected %s", got, format, expected)
+		}
+	}
+
+	if got := fmt.Sprintf("%04d|%-3s|%o", ID(7), ID(7), ID(8)); got != "0007|7  |10" {
+		t.Errorf("Got %s, expected 0007|7  |10", got)
+	}
+}
+
+func TestLogValue(t *testing.T) {
+	id := ID(1417819914141990912)
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+
+	logger.Info("created", "id", id)
+	if got := buf.String(); !strings.Contains(got, "id=1417819914141990912") {
+		t.Errorf("Got %q, expected the decimal ID", got)
+	}
+
+	LogParts = true
+	defer func() { LogParts = false }()
+
+	buf.Reset()
+	logger.Info("created", "id", id)
+	expected := "id.id=1417819914141990912 id.time=2021-07-21T12:13:04.008Z id.node=648 id.step=0"
+	if got := buf.String(); !strings.Contains(got, expected) {
+		t.Errorf("Got %q, expected it to contain %q", got, expected)
+	}
+}
@@ -0,0 +1,188 @@
+package snowflake
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+// fuzzSeeds are valid and malformed inputs shared by the parser fuzz targets.
+var fuzzSeeds = []string{
+	"", "0", "1", "-1", "1417819914141990912", "9223372036854775807",
+	"9223372036854775808", `"1417819914141990912"`, "null", "\"", "+5",
+	"1e18", " 1", "ZZZZZZZZZZZZZ", "E8LyzN0AiAA=", "npL6MjP8Qfc",
+	"20231114T153000.123-n42-s7", "1FVK-SS1F-8580-76", "7zzzzzzzzzzzz",
+}
+
+func addSeeds(f *testing.F, extra ...string) {
+	for _, s := range append(fuzzSeeds, extra...) {
+		f.Add(s)
+	}
+}
+
+// fuzzParse checks that parse never panics and that anything it accepts
+// encodes back to an input it parses to the same ID.
+func fuzzParse(f *testing.F, parse func(string) (ID, error), format func(ID) string) {
+	f.Fuzz(func(t *testing.T, s string) {
+		id, err := parse(s)
+		if err != nil {
+			return
+		}
+
+		again, err := parse(format(id))
+		if err != nil || again != id {
+			t.Fatalf("Got (%d, %v) parsing %q formatted from %q, expected (%d, nil)", again, err, format(id), s, id)
+		}
+	})
+}
+
+func FuzzParseString(f *testing.F) {
+	addSeeds(f)
+	fuzzParse(f, ParseString, ID.String)
+}
+
+func FuzzParseBase2(f *testing.F) {
+	addSeeds(f, "101", "-0")
+	fuzzParse(f, ParseBase2, ID.Base2)
+}
+
+func FuzzParseBase32(f *testing.F) {
+	addSeeds(f, "ybndrfg8ejkmcpqxot1uwisza345h769")
+	fuzzParse(f, ParseBase32, ID.Base32)
+}
+
+func FuzzParseBase36(f *testing.F) {
+	addSeeds(f)
+	fuzzParse(f, ParseBase36, ID.Base36)
+}
+
+func FuzzParseBase58(f *testing.F) {
+	addSeeds(f, "0OIl")
+	fuzzParse(f, ParseBase58, ID.Base58)
+}
+
+func FuzzParseBase64(f *testing.F) {
+	addSeeds(f, "AAAAAAAAAAA=", "////////")
+	fuzzParse(f, ParseBase64, ID.Base64)
+}
+
+func FuzzParseBase64URL(f *testing.F) {
+	addSeeds(f, "AAAAAAAAAAA", "________")
+	fuzzParse(f, ParseBase64URL, ID.Base64URL)
+}
+
+func FuzzParseHex(f *testing.F) {
+	addSeeds(f, "0x10", "ffffffffffffffff")
+	fuzzParse(f, ParseHex, ID.Hex)
+}
+
+func FuzzParseBase32Check(f *testing.F) {
+	addSeeds(f, "7ZZZ-ZZZZ-ZZZZ-Z5", "0*", "U")
+	fuzzParse(f, ParseBase32Check, ID.Base32Check)
+}
+
+func FuzzParseShortCode(f *testing.F) {
+	addSeeds(f, "7ZZZ-ZZZZ-ZZZZ-Z5", "1fvk ss1f 8580 76")
+	fuzzParse(f, ParseShortCode, ID.ShortCode)
+}
+
+func FuzzParseDebugString(f *testing.F) {
+	addSeeds(f, "20060102T150405.000-n-s", "20231114T153000.123-n1024-s4096")
+	fuzzParse(f, ParseDebugString, ID.DebugString)
+}
+
+func FuzzUnmarshalJSON(f *testing.F) {
+	addSeeds(f, "{}", "[]", "true", `"null"`)
+	f.Fuzz(func(t *testing.T, s string) {
+		var id ID
+		if err := id.UnmarshalJSON([]byte(s)); err != nil {
+			return
+		}
+
+		b, _ := id.MarshalJSON()
+		var again ID
+		if err := again.UnmarshalJSON(b); err != nil || again != id {
+			t.Fatalf("Got (%d, %v) unmarshaling %s from %q, expected (%d, nil)", again, err, b, s, id)
+		}
+	})
+}
+
+func FuzzUnmarshalBinary(f *testing.F) {
+	for _, b := range [][]byte{nil, {1}, {0, 0, 0, 0, 0, 0, 0, 1}, bytes.Repeat([]byte{0xFF}, 9)} {
+		f.Add(b)
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		var id ID
+		if err := id.UnmarshalBinary(b); err != nil {
+			return
+		}
+
+		if again, _ := id.MarshalBinary(); !bytes.Equal(again, b) {
+			t.Fatalf("Got %x marshaling %d, expected %x", again, id, b)
+		}
+
+		var m ID
+		rest, err := m.UnmarshalMsg(b)
+		_, _ = rest, err
+		var c ID
+		_ = c.UnmarshalCBOR(b)
+		var s NodeState
+		_ = s.UnmarshalBinary(b)
+	})
+}
+
+// roundTripLayouts are the layouts checked by TestLayoutRoundTrips.
+var roundTripLayouts = map[string]Preset{
+	"default":     {Layout: DefaultLayout, Epoch: Epoch},
+	"sonyflake":   {Layout: SonyflakeLayout, Epoch: SonyflakeEpoch},
+	"instagram":   {Layout: InstagramLayout, Epoch: InstagramEpoch},
+	"discord":     {Layout: DiscordLayout, Epoch: DiscordEpoch},
+	"unsigned":    {Layout: Layout{NodeBits: 10, StepBits: 12, Unsigned: true}, Epoch: Epoch},
+	"microsecond": {Layout: Layout{NodeBits: 6, StepBits: 6, TimeUnit: time.Microsecond}, Epoch: Epoch},
+	"seconds":     {Layout: Layout{NodeBits: 16, StepBits: 16, TimeUnit: time.Second}, Epoch: Epoch},
+}
+
+// TestLayoutRoundTrips checks that for every layout, generated IDs survive
+// each encoding and decode to fields that encode back to the same ID.
+func TestLayoutRoundTrips(t *testing.T) {
+	encodings := map[string]struct {
+		format func(ID) string
+		parse  func(string) (ID, error)
+	}{
+		"string":      {ID.String, ParseString},
+		"base2":       {ID.Base2, ParseBase2},
+		"base32":      {ID.Base32, ParseBase32},
+		"base36":      {ID.Base36, ParseBase36},
+		"base58":      {ID.Base58, ParseBase58},
+		"base64":      {ID.Base64, ParseBase64},
+		"base64url":   {ID.Base64URL, ParseBase64URL},
+		"hex":         {ID.Hex, ParseHex},
+		"base32check": {ID.Base32Check, ParseBase32Check},
+	}
+
+	for name, p := range roundTripLayouts {
+		node, err := NewNode(1<<p.Layout.NodeBits-1, WithPreset(p))
+		if err != nil {
+			t.Fatalf("%s: error creating NewNode, %s", name, err)
+		}
+
+		for _, id := range node.GenerateN(500) {
+			parts := node.Decode(id)
+			if again, err := p.Layout.Encode(parts, p.Epoch); err != nil || again != id {
+				t.Fatalf("%s: Got (%d, %v) encoding %+v, expected (%d, nil)", name, again, err, parts, id)
+			}
+
+			for enc, e := range encodings {
+				if got, err := e.parse(e.format(id)); err != nil || got != id {
+					t.Fatalf("%s: Got (%d, %v) through %s, expected (%d, nil)", name, got, err, enc, id)
+				}
+			}
+
+			b, _ := id.MarshalJSON()
+			var fromJSON ID
+			if err := fromJSON.UnmarshalJSON(b); err != nil || node.Decode(fromJSON) != parts {
+				t.Fatalf("%s: Got (%+v, %v) through JSON, expected %+v", name, node.Decode(fromJSON), err, parts)
+			}
+		}
+	}
+}
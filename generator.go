@@ -0,0 +1,22 @@
+package snowflake
+
+// A Generator generates snowflake IDs and decodes the IDs it generated.  All
+// of a generator's configuration, such as its epoch, layout and clock, lives
+// on the generator itself, so generators with different ID schemes can run
+// side by side, for example one per tenant of a service, and alternative
+// implementations, such as one using random suffixes, can be swapped in
+// behind the same API.
+//
+// Node and Pool implement Generator.
+type Generator interface {
+	// Generate returns a new unique ID.
+	Generate() ID
+
+	// Decode returns the fields of an ID generated by the generator.
+	Decode(id ID) Parts
+}
+
+var (
+	_ Generator = (*Node)(nil)
+	_ Generator = (*Pool)(nil)
+)
@@ -0,0 +1,40 @@
+package snowflake
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGeneratorIsolation(t *testing.T) {
+	epoch := Epoch
+
+	discord, _ := NewNode(3, WithPreset(Preset{Layout: DiscordLayout, Epoch: DiscordEpoch}))
+	sony, _ := NewNode(0xBEEF, WithPreset(Preset{Layout: SonyflakeLayout, Epoch: SonyflakeEpoch}))
+	pool, err := NewPool([]int64{1, 2}, &PoolOptions{NodeOptions: []Option{WithLayout(Layout{NodeBits: 4, StepBits: 18})}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, tc := range []struct {
+		gen   Generator
+		nodes []int64
+	}{
+		{discord, []int64{3}},
+		{sony, []int64{0xBEEF}},
+		{pool, []int64{1, 2}},
+	} {
+		for i := 0; i < 4; i++ {
+			p := tc.gen.Decode(tc.gen.Generate())
+			if d := time.Since(p.Time); d < 0 || d > time.Second {
+				t.Errorf("Got time %s, expected close to now", p.Time)
+			}
+			if p.Node != tc.nodes[0] && p.Node != tc.nodes[len(tc.nodes)-1] {
+				t.Errorf("Got node %d, expected one of %v", p.Node, tc.nodes)
+			}
+		}
+	}
+
+	if Epoch != epoch {
+		t.Errorf("Got package Epoch %d, expected it unchanged at %d", Epoch, epoch)
+	}
+}
@@ -0,0 +1,56 @@
+package snowflake
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// MarshalGQL writes the snowflake ID as a quoted decimal string, the wire
+// format of the GraphQL ID scalar.  Together with UnmarshalGQL it
+// implements graphql.Marshaler and graphql.Unmarshaler from
+// github.com/99designs/gqlgen, so ID can be bound directly to an ID scalar
+// without this package depending on gqlgen:
+//
+//	models:
+//	  ID:
+//	    model: github.com/bwmarrin/snowflake.ID
+func (f ID) MarshalGQL(w io.Writer) {
+	w.Write(f.AppendQuotedJSON(make([]byte, 0, 22)))
+}
+
+// UnmarshalGQL sets the ID from a GraphQL input value.  Clients may send an
+// ID scalar as a string or an integer, so strings, Go integers, json.Number
+// and whole float64 values are all accepted.
+func (f *ID) UnmarshalGQL(v interface{}) error {
+	switch v := v.(type) {
+	case string:
+		return f.UnmarshalText([]byte(v))
+	case json.Number:
+		return f.UnmarshalText([]byte(v))
+	case int:
+		return f.setGQLInt(int64(v))
+	case int32:
+		return f.setGQLInt(int64(v))
+	case int64:
+		return f.setGQLInt(v)
+	case float64:
+		if v != math.Trunc(v) || v < 0 || v >= math.MaxInt64 {
+			return &InvalidIDError{Input: fmt.Sprint(v), Err: errors.New("not a whole number in range")}
+		}
+		return f.setGQLInt(int64(v))
+	}
+
+	return fmt.Errorf("cannot unmarshal GraphQL value of type %T into a snowflake ID", v)
+}
+
+func (f *ID) setGQLInt(v int64) error {
+	if v < 0 {
+		return &InvalidIDError{Input: fmt.Sprint(v), Err: errNegativeID}
+	}
+
+	*f = ID(v)
+	return nil
+}
@@ -0,0 +1,33 @@
+package snowflake
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestGQL(t *testing.T) {
+	var b strings.Builder
+	ID(13587).MarshalGQL(&b)
+	if b.String() != `"13587"` {
+		t.Errorf("Got %s, expected \"13587\"", b.String())
+	}
+
+	for _, v := range []interface{}{"13587", json.Number("13587"), 13587, int32(13587), int64(13587), float64(13587)} {
+		var id ID
+		if err := id.UnmarshalGQL(v); err != nil || id != 13587 {
+			t.Errorf("Got (%d, %v) unmarshaling %#v, expected (13587, nil)", id, err, v)
+		}
+	}
+
+	for _, v := range []interface{}{"abc", "-1", -1, 1.5, float64(1 << 63), true, nil} {
+		if err := new(ID).UnmarshalGQL(v); err == nil {
+			t.Errorf("Expected an error unmarshaling %#v", v)
+		}
+	}
+
+	if err := new(ID).UnmarshalGQL(-1); !errors.Is(err, ErrInvalidID) {
+		t.Errorf("Got %v, expected ErrInvalidID", err)
+	}
+}
@@ -0,0 +1,43 @@
+package snowflake
+
+import "errors"
+
+// WithHook makes the node call fn with every ID returned by Generate,
+// GenerateUnlocked, GenerateSafe, GenerateCtx, GenerateN, GenerateRegion and
+// TenantGen.Generate, for audit logging, tagging tracing spans or feeding
+// metrics without wrapping the node.  Hooks are called in the order they were
+// added, in the generating goroutine after the node lock has been released,
+// so a slow hook delays its own caller but not others.  IDs generated through
+// WithLock, ReserveBlock, GenerateInBucket or GenerateAt are not passed to
+// hooks.
+//
+// WithHook may be given more than once to add several hooks.
+func WithHook(fn func(ID)) Option {
+	return func(n *Node) error {
+		if fn == nil {
+			return errors.New("hook must not be nil")
+		}
+
+		n.hooks = append(n.hooks, fn)
+		return nil
+	}
+}
+
+// runHooks calls the node's hooks with id.  It must be called without the
+// node lock held.
+func (n *Node) runHooks(id ID) {
+	for _, h := range n.hooks {
+		h(id)
+	}
+}
+
+// runHookN calls the node's hooks with each of ids in order.
+func (n *Node) runHookN(ids []ID) {
+	if len(n.hooks) == 0 {
+		return
+	}
+
+	for _, id := range ids {
+		n.runHooks(id)
+	}
+}
@@ -0,0 +1,65 @@
+package snowflake
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWithHook(t *testing.T) {
+	var first, second []ID
+	node, err := NewNode(1,
+		WithHook(func(id ID) { first = append(first, id) }),
+		WithHook(func(id ID) { second = append(second, id) }),
+		WithRegionBits(2),
+	)
+	if err != nil {
+		t.Fatalf("error creating NewNode, %s", err)
+	}
+
+	var want []ID
+	want = append(want, node.Generate(), node.GenerateUnlocked())
+	id, _ := node.GenerateSafe()
+	want = append(want, id)
+	id, _ = node.GenerateCtx(context.Background())
+	want = append(want, id)
+	want = append(want, node.GenerateN(3)...)
+	id, _ = node.GenerateRegion(1)
+	want = append(want, id)
+
+	node.WithLock(func(gen func() ID) { gen() })
+	if _, err := node.ReserveBlock(2); err != nil {
+		t.Fatalf("error reserving block, %s", err)
+	}
+
+	for name, got := range map[string][]ID{"first": first, "second": second} {
+		if len(got) != len(want) {
+			t.Fatalf("%s hook got %d IDs, expected %d", name, len(got), len(want))
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("%s hook got %d at %d, expected %d", name, got[i], i, want[i])
+			}
+		}
+	}
+
+	if _, err := NewNode(1, WithHook(nil)); err == nil {
+		t.Error("Expected an error for a nil hook")
+	}
+}
+
+func TestWithHookOutsideLock(t *testing.T) {
+	var node *Node
+	node, _ = NewNode(1, WithHook(func(ID) {
+		if !node.TryLock() {
+			t.Error("Hook called with the node lock held")
+			return
+		}
+		node.Unlock()
+	}))
+
+	node.Generate()
+	node.GenerateN(2)
+
+	lockFree, _ := NewNode(1, WithLockFree(), WithHook(func(ID) {}))
+	lockFree.Generate()
+}
@@ -0,0 +1,158 @@
+// Package httpserver serves snowflake IDs from a Node over HTTP, for running
+// a small ID sidecar or central allocator.
+//
+// The handler answers three JSON endpoints:
+//
+//	GET /id              {"id":"1234"}
+//	GET /ids?count=N     {"ids":["1234","1235"]}
+//	GET /decode/{id}     {"id":"1234","time":"2023-11-14T15:30:00.123Z","node":1,"step":0}
+//
+// IDs are encoded as snowflake.ID marshals them, as decimal strings unless
+// changed with snowflake.SetJSONEncoding, so JavaScript clients do not lose
+// precision by default.  Errors are returned as {"error":"..."} with a 4xx
+// status, or 503 Service Unavailable once the node is closed.
+//
+// Only GET and HEAD requests are accepted.  HEAD requests to /id and /ids
+// answer without generating IDs, and their responses are marked
+// Cache-Control: no-store, as every GET returns new IDs.
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+)
+
+// DefaultMaxBatch is the largest count accepted by /ids unless changed with
+// Server.MaxBatch.
+const DefaultMaxBatch = 1000
+
+// A Server is an http.Handler serving IDs generated by a Node.  It matches
+// the full request path, so mount it with http.StripPrefix to serve it below
+// a prefix.
+type Server struct {
+	// MaxBatch is the largest count accepted by /ids.
+	MaxBatch int
+
+	node *snowflake.Node
+}
+
+// New returns a Server generating IDs with node.
+func New(node *snowflake.Node) *Server {
+	return &Server{MaxBatch: DefaultMaxBatch, node: node}
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var handle func(http.ResponseWriter, *http.Request)
+	switch {
+	case r.URL.Path == "/id":
+		handle = s.handleID
+		w.Header().Set("Cache-Control", "no-store")
+	case r.URL.Path == "/ids":
+		handle = s.handleIDs
+		w.Header().Set("Cache-Control", "no-store")
+	case strings.HasPrefix(r.URL.Path, "/decode/"):
+		handle = s.handleDecode
+	default:
+		writeJSON(w, http.StatusNotFound, errorResponse{"not found"})
+		return
+	}
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{"method not allowed"})
+		return
+	}
+
+	handle(w, r)
+}
+
+type idResponse struct {
+	ID snowflake.ID `json:"id"`
+}
+
+type idsResponse struct {
+	IDs []snowflake.ID `json:"ids"`
+}
+
+type decodeResponse struct {
+	ID   snowflake.ID `json:"id"`
+	Time time.Time    `json:"time"`
+	Node int64        `json:"node"`
+	Step int64        `json:"step"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func (s *Server) handleID(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodHead {
+		writeHead(w)
+		return
+	}
+
+	id, err := s.node.GenerateCtx(r.Context())
+	if err != nil {
+		writeGenerateError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, idResponse{id})
+}
+
+func (s *Server) handleIDs(w http.ResponseWriter, r *http.Request) {
+	count, err := strconv.Atoi(r.URL.Query().Get("count"))
+	if err != nil || count < 1 || count > s.MaxBatch {
+		writeJSON(w, http.StatusBadRequest, errorResponse{"count must be between 1 and " + strconv.Itoa(s.MaxBatch)})
+		return
+	}
+
+	if r.Method == http.MethodHead {
+		writeHead(w)
+		return
+	}
+
+	ids, err := s.node.GenerateNCtx(r.Context(), count)
+	if err != nil {
+		writeGenerateError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, idsResponse{ids})
+}
+
+func (s *Server) handleDecode(w http.ResponseWriter, r *http.Request) {
+	raw := strings.TrimPrefix(r.URL.Path, "/decode/")
+	id, err := snowflake.ParseString(raw)
+	if err != nil || id < 0 {
+		writeJSON(w, http.StatusBadRequest, errorResponse{"invalid ID " + strconv.Quote(raw)})
+		return
+	}
+
+	p := s.node.Decode(id)
+	writeJSON(w, http.StatusOK, decodeResponse{ID: id, Time: p.Time.UTC(), Node: p.Node, Step: p.Step})
+}
+
+// writeGenerateError reports an error generating IDs: the node being closed,
+// or the request being cancelled while waiting for the node's rate limit.
+func writeGenerateError(w http.ResponseWriter, err error) {
+	writeJSON(w, http.StatusServiceUnavailable, errorResponse{err.Error()})
+}
+
+// writeHead answers a HEAD request with the headers of a successful GET.
+func writeHead(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,150 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+)
+
+func get(t *testing.T, h http.Handler, path string, v interface{}) int {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Got content type %q for %s, expected application/json", ct, path)
+	}
+	if v != nil {
+		if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+			t.Fatalf("Unexpected error decoding %s: %v", rec.Body, err)
+		}
+	}
+	return rec.Code
+}
+
+func TestID(t *testing.T) {
+	node, _ := snowflake.NewNode(9)
+	s := New(node)
+
+	var res idResponse
+	if code := get(t, s, "/id", &res); code != http.StatusOK {
+		t.Fatalf("Got status %d, expected 200", code)
+	}
+	if res.ID.Node() != 9 {
+		t.Errorf("Got node %d, expected 9", res.ID.Node())
+	}
+}
+
+func TestIDs(t *testing.T) {
+	node, _ := snowflake.NewNode(9)
+	s := New(node)
+	s.MaxBatch = 50
+
+	var res idsResponse
+	if code := get(t, s, "/ids?count=50", &res); code != http.StatusOK {
+		t.Fatalf("Got status %d, expected 200", code)
+	}
+	if len(res.IDs) != 50 {
+		t.Fatalf("Got %d IDs, expected 50", len(res.IDs))
+	}
+	for i := 1; i < len(res.IDs); i++ {
+		if res.IDs[i] <= res.IDs[i-1] {
+			t.Fatalf("ID %d is not greater than %d", res.IDs[i], res.IDs[i-1])
+		}
+	}
+
+	for _, path := range []string{"/ids", "/ids?count=0", "/ids?count=51", "/ids?count=many"} {
+		var e errorResponse
+		if code := get(t, s, path, &e); code != http.StatusBadRequest || e.Error == "" {
+			t.Errorf("Got status %d and error %q for %s, expected 400 with an error", code, e.Error, path)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	node, _ := snowflake.NewNode(9)
+	s := New(node)
+
+	id := node.Generate()
+
+	var res decodeResponse
+	if code := get(t, s, "/decode/"+id.String(), &res); code != http.StatusOK {
+		t.Fatalf("Got status %d, expected 200", code)
+	}
+
+	p := node.Decode(id)
+	if res.ID != id || !res.Time.Equal(p.Time) || res.Node != 9 || res.Step != p.Step {
+		t.Errorf("Got %+v, expected %d decoded as %+v", res, id, p)
+	}
+	if d := time.Since(res.Time); d < 0 || d > time.Minute {
+		t.Errorf("Got time %s, expected close to now", res.Time)
+	}
+
+	var e errorResponse
+	if code := get(t, s, "/decode/12a", &e); code != http.StatusBadRequest {
+		t.Errorf("Got status %d, expected 400", code)
+	}
+
+	if code := get(t, s, "/nothing", &e); code != http.StatusNotFound {
+		t.Errorf("Got status %d, expected 404", code)
+	}
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/id", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Got status %d for POST, expected 405", rec.Code)
+	}
+}
+
+func TestClosedNode(t *testing.T) {
+	node, _ := snowflake.NewNode(9)
+	s := New(node)
+	node.Close()
+
+	for _, path := range []string{"/id", "/ids?count=2"} {
+		var res errorResponse
+		if code := get(t, s, path, &res); code != http.StatusServiceUnavailable || res.Error == "" {
+			t.Errorf("Got status %d and error %q for %s, expected 503 with an error", code, res.Error, path)
+		}
+	}
+}
+
+func TestNoStore(t *testing.T) {
+	node, _ := snowflake.NewNode(9)
+	s := New(node)
+
+	for _, path := range []string{"/id", "/ids?count=2"} {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if cc := rec.Header().Get("Cache-Control"); cc != "no-store" {
+			t.Errorf("Got Cache-Control %q for %s, expected no-store", cc, path)
+		}
+	}
+}
+
+func TestHead(t *testing.T) {
+	node, _ := snowflake.NewNode(9)
+	s := New(node)
+
+	for _, path := range []string{"/id", "/ids?count=2"} {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodHead, path, nil))
+		if rec.Code != http.StatusOK || rec.Body.Len() != 0 {
+			t.Errorf("Got status %d and a %d byte body for HEAD %s, expected 200 and no body", rec.Code, rec.Body.Len(), path)
+		}
+	}
+	if n := node.Stats().Generated; n != 0 {
+		t.Errorf("Got %d IDs generated by HEAD requests, expected none", n)
+	}
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodHead, "/ids?count=0", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Got status %d for HEAD with a bad count, expected 400", rec.Code)
+	}
+}
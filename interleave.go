@@ -0,0 +1,57 @@
+package snowflake
+
+import "math/bits"
+
+// WithBitInterleave makes the node return IDs with their bits permuted so
+// that the fastest changing step and node bits are spread across the whole
+// ID, interleaved with the slowly changing time bits.  This spreads
+// consecutive IDs across hash and range partitioned stores instead of
+// clustering them.
+//
+// Interleaved IDs are NOT k-sortable and their Time, Node and Step methods
+// are meaningless; use Deinterleave to recover the canonical ID, or decode
+// them with the node's Decode, IDTime, IDNode and IDStep, which deinterleave
+// first.
+func WithBitInterleave() Option {
+	return func(n *Node) error {
+		n.interleave = true
+		return nil
+	}
+}
+
+// interleave maps bit i of the low 32 bits to bit 62-2i and bit i of the high
+// 31 bits to bit 61-2i.
+func (f ID) interleave() ID {
+	var r int64
+	for i := uint(0); i < 32; i++ {
+		r |= (int64(f) >> i & 1) << (62 - 2*i)
+	}
+	for i := uint(0); i < 31; i++ {
+		r |= (int64(f) >> (32 + i) & 1) << (61 - 2*i)
+	}
+	return ID(r)
+}
+
+// Deinterleave returns the canonical form of an ID generated by a node using
+// WithBitInterleave.
+func (f ID) Deinterleave() ID {
+	var r int64
+	for i := uint(0); i < 32; i++ {
+		r |= (int64(f) >> (62 - 2*i) & 1) << i
+	}
+	for i := uint(0); i < 31; i++ {
+		r |= (int64(f) >> (61 - 2*i) & 1) << (32 + i)
+	}
+	return ID(r)
+}
+
+// BitReverse returns the snowflake ID with the order of its 63 low bits
+// reversed, so the fast changing step bits become the most significant.
+// Storing reversed IDs spreads writes across an LSM or B-tree keyspace rather
+// than always appending at its tail.
+//
+// Reversed IDs are NOT k-sortable.  The transform is its own inverse, so
+// calling BitReverse on a reversed ID recovers the original.
+func (f ID) BitReverse() ID {
+	return ID(bits.Reverse64(uint64(f)<<1) & (1<<63 - 1))
+}
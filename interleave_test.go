@@ -0,0 +1,84 @@
+package snowflake
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestInterleaveRoundTrip(t *testing.T) {
+	for i := 0; i < 10000; i++ {
+		id := ID(rand.Int63())
+		if r := id.interleave().Deinterleave(); r != id {
+			t.Fatalf("Got %d after round trip, expected %d", r, id)
+		}
+	}
+
+	for _, id := range []ID{0, 1, 1<<63 - 1} {
+		if r := id.interleave().Deinterleave(); r != id {
+			t.Errorf("Got %d after round trip, expected %d", r, id)
+		}
+	}
+}
+
+func TestWithBitInterleave(t *testing.T) {
+	node, _ := NewNode(5, WithBitInterleave())
+	plain, _ := NewNode(5)
+
+	id := node.Generate()
+	if id < 0 {
+		t.Fatalf("Got negative interleaved ID %d", id)
+	}
+
+	canonical := id.Deinterleave()
+	if canonical.Node() != 5 {
+		t.Errorf("Got node %d, expected 5", canonical.Node())
+	}
+
+	if ref := plain.Generate(); canonical.Time() > ref.Time() || ref.Time()-canonical.Time() > 1000 {
+		t.Errorf("Got time %d, expected close to %d", canonical.Time(), ref.Time())
+	}
+}
+
+func TestBitReverse(t *testing.T) {
+	for i := 0; i < 10000; i++ {
+		id := ID(rand.Int63())
+		if r := id.BitReverse().BitReverse(); r != id {
+			t.Fatalf("Got %d after reversing twice, expected %d", r, id)
+		}
+	}
+
+	if r := ID(1).BitReverse(); r != 1<<62 {
+		t.Errorf("Got %d, expected %d", r, int64(1<<62))
+	}
+
+	if r := ID(1<<63 - 1).BitReverse(); r != 1<<63-1 {
+		t.Errorf("Got %d, expected %d", r, int64(1<<63-1))
+	}
+}
+
+func TestInterleavedNodeDecode(t *testing.T) {
+	node, _ := NewNode(1, WithBitInterleave())
+
+	before := time.Now().Add(-time.Millisecond)
+	id := node.Generate()
+
+	p := node.Decode(id)
+	if p.Node != 1 || node.IDNode(id) != 1 {
+		t.Errorf("Got node %d and %d, expected 1", p.Node, node.IDNode(id))
+	}
+	if p.Step != node.IDStep(id) || p.Time.Before(before) || p.Time.After(time.Now()) {
+		t.Errorf("Got %+v, expected the current time", p)
+	}
+	if node.IDTime(id) != p.Time.UnixMilli() {
+		t.Errorf("Got time %d, expected %d", node.IDTime(id), p.Time.UnixMilli())
+	}
+
+	later := node.Generate()
+	if node.Compare(id, later) >= 0 {
+		t.Errorf("Expected %d to compare before %d", id, later)
+	}
+	if shifted := node.AddTime(id, time.Second); node.IDTime(shifted) != p.Time.UnixMilli()+1000 || node.IDNode(shifted) != 1 {
+		t.Errorf("Got %+v after adding a second, expected the same node a second later", node.Decode(shifted))
+	}
+}
@@ -0,0 +1,94 @@
+package snowflake
+
+import (
+	"strconv"
+	"sync/atomic"
+)
+
+// A JSONEncoding selects how ID.MarshalJSON encodes snowflake IDs.
+type JSONEncoding int32
+
+const (
+	// EncQuoted encodes IDs as quoted decimal strings, which JavaScript
+	// clients can read without losing precision.  This is the default.
+	EncQuoted JSONEncoding = iota
+
+	// EncNumber encodes IDs as bare JSON numbers, like NumericID.
+	EncNumber
+
+	// EncBase36 encodes IDs as quoted base36 strings, as returned by Base36.
+	EncBase36
+
+	// EncBase58 encodes IDs as quoted base58 strings, as returned by Base58.
+	EncBase58
+)
+
+// jsonEncoding holds the JSONEncoding set by SetJSONEncoding.
+var jsonEncoding atomic.Int32
+
+// SetJSONEncoding selects how ID and Typed values are encoded by MarshalJSON
+// and decoded by UnmarshalJSON, so one ID type can serve both JavaScript
+// clients that need strings and Go services that prefer numbers.  Like Epoch
+// it should be set once, before any IDs are marshaled.  NumericID always
+// encodes numbers and MarshalGQL always encodes quoted decimal strings,
+// whatever the encoding.  It panics for an unknown encoding.
+//
+// UnmarshalJSON always accepts bare numbers.  Quoted strings are parsed as
+// decimal with EncQuoted and EncNumber, and as base36 or base58 with
+// EncBase36 or EncBase58, as a string of digits is ambiguous between them.
+func SetJSONEncoding(enc JSONEncoding) {
+	if enc < EncQuoted || enc > EncBase58 {
+		panic("snowflake: unknown JSON encoding " + strconv.Itoa(int(enc)))
+	}
+
+	jsonEncoding.Store(int32(enc))
+}
+
+// AppendJSON appends the JSON encoding of the snowflake ID, as returned by
+// MarshalJSON with the encoding set by SetJSONEncoding, to dst and returns
+// the extended buffer.
+func (f ID) AppendJSON(dst []byte) []byte {
+	switch JSONEncoding(jsonEncoding.Load()) {
+	case EncNumber:
+		return strconv.AppendInt(dst, int64(f), 10)
+	case EncBase36:
+		dst = append(dst, '"')
+		dst = f.AppendBase36(dst)
+		return append(dst, '"')
+	case EncBase58:
+		dst = append(dst, '"')
+		dst = f.AppendBase58(dst)
+		return append(dst, '"')
+	}
+
+	return f.AppendQuotedJSON(dst)
+}
+
+// parseJSONString parses the contents of a quoted JSON snowflake ID in the
+// encoding set by SetJSONEncoding.
+func parseJSONString(b []byte) (ID, error) {
+	var (
+		id  ID
+		err error
+	)
+	switch JSONEncoding(jsonEncoding.Load()) {
+	case EncBase36:
+		var i int64
+		if i, err = strconv.ParseInt(string(b), 36, 64); err == nil && i < 0 {
+			err = errNegativeID
+		}
+		if ne, ok := err.(*strconv.NumError); ok {
+			err = ne.Err
+		}
+		id = ID(i)
+	case EncBase58:
+		id, err = ParseBase58(string(b))
+	default:
+		return parseID(b)
+	}
+
+	if err != nil {
+		return 0, &InvalidIDError{Input: string(b), Err: err}
+	}
+	return id, nil
+}
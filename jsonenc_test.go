@@ -0,0 +1,85 @@
+package snowflake
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestSetJSONEncoding(t *testing.T) {
+	defer SetJSONEncoding(EncQuoted)
+
+	id := ID(1417819914141990912)
+	for _, tc := range []struct {
+		enc      JSONEncoding
+		expected string
+	}{
+		{EncQuoted, `"1417819914141990912"`},
+		{EncNumber, `1417819914141990912`},
+		{EncBase36, `"` + id.Base36() + `"`},
+		{EncBase58, `"` + id.Base58() + `"`},
+	} {
+		SetJSONEncoding(tc.enc)
+
+		b, err := json.Marshal(struct{ ID ID }{id})
+		if err != nil {
+			t.Fatalf("Unexpected error marshaling with encoding %d: %v", tc.enc, err)
+		}
+		if expected := `{"ID":` + tc.expected + `}`; string(b) != expected {
+			t.Errorf("Got %s with encoding %d, expected %s", b, tc.enc, expected)
+		}
+
+		var back struct{ ID ID }
+		if err := json.Unmarshal(b, &back); err != nil || back.ID != id {
+			t.Errorf("Got %d, %v unmarshaling %s, expected %d", back.ID, err, b, id)
+		}
+
+		// Bare numbers are accepted in every encoding.
+		var num ID
+		if err := num.UnmarshalJSON([]byte("13587")); err != nil || num != 13587 {
+			t.Errorf("Got %d, %v unmarshaling a number with encoding %d", num, err, tc.enc)
+		}
+
+		if b, _ := Typed[struct{}](id).MarshalJSON(); string(b) != tc.expected {
+			t.Errorf("Got %s from Typed with encoding %d, expected %s", b, tc.enc, tc.expected)
+		}
+	}
+}
+
+func TestSetJSONEncodingErrors(t *testing.T) {
+	defer SetJSONEncoding(EncQuoted)
+
+	SetJSONEncoding(EncBase36)
+	for _, bad := range []string{`"-abc"`, `"a*c"`, `"zzzzzzzzzzzzzzzz"`} {
+		var id ID
+		if err := id.UnmarshalJSON([]byte(bad)); !errors.Is(err, ErrInvalidID) {
+			t.Errorf("Got %v unmarshaling %s as base36, expected ErrInvalidID", err, bad)
+		}
+	}
+
+	SetJSONEncoding(EncBase58)
+	for _, bad := range []string{`"0OIl"`, `""`} {
+		var id ID
+		if err := id.UnmarshalJSON([]byte(bad)); !errors.Is(err, ErrInvalidID) {
+			t.Errorf("Got %v unmarshaling %s as base58, expected ErrInvalidID", err, bad)
+		}
+	}
+
+	// NumericID and MarshalGQL keep their own encodings.
+	if b, _ := NumericID(13587).MarshalJSON(); string(b) != "13587" {
+		t.Errorf("Got %s from NumericID, expected 13587", b)
+	}
+	var buf bytes.Buffer
+	ID(13587).MarshalGQL(&buf)
+	if buf.String() != `"13587"` {
+		t.Errorf("Got %s from MarshalGQL, expected \"13587\"", buf.String())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a panic for an unknown encoding")
+		}
+	}()
+	SetJSONEncoding(EncBase58 + 1)
+}
@@ -0,0 +1,100 @@
+package snowflake
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// hostname returns the pod's hostname; it is a variable so tests can replace
+// it.
+var hostname = os.Hostname
+
+// NewNodeFromStatefulSetOrdinal creates a new Node using the ordinal of the
+// Kubernetes StatefulSet pod it runs in as the node number.  StatefulSet pods
+// are named after the set with their ordinal appended, such as "ids-3", and
+// no two pods of a set run with the same ordinal at once, so unlike hashed
+// hostnames the node numbers never collide.  The set must have no more
+// replicas than the layout has node numbers.
+func NewNodeFromStatefulSetOrdinal(opts ...Option) (*Node, error) {
+	ord, err := StatefulSetOrdinal()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewNode(ord, opts...)
+}
+
+// StatefulSetOrdinal returns the StatefulSet ordinal parsed from the end of
+// the pod's hostname, which NewNodeFromStatefulSetOrdinal uses as the node
+// number.
+func StatefulSetOrdinal() (int64, error) {
+	name, err := hostname()
+	if err != nil {
+		return 0, err
+	}
+
+	i := strings.LastIndexByte(name, '-')
+	if i < 0 {
+		return 0, errors.New("hostname " + name + " has no StatefulSet ordinal")
+	}
+
+	digits := name[i+1:]
+	ord, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil || digits[0] < '0' || digits[0] > '9' {
+		return 0, errors.New("hostname " + name + " has no StatefulSet ordinal")
+	}
+
+	return ord, nil
+}
+
+// NewNodeFromDownwardAPI creates a new Node using a node number published to
+// the pod through a Kubernetes downward API volume, such as an annotation
+// assigned by a deployment controller.  path is the file in the volume.  If
+// key is empty the file must hold just the number, as when a single field
+// such as metadata.annotations['snowflake/node'] is projected; otherwise the
+// file is read in the key="value" format of a projected metadata.annotations
+// or metadata.labels and the number is taken from key.
+func NewNodeFromDownwardAPI(path, key string, opts ...Option) (*Node, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	value := string(bytes.TrimSpace(b))
+	if key != "" {
+		var ok bool
+		if value, ok = downwardAPIValue(b, key); !ok {
+			return nil, errors.New("downward API file " + path + " has no " + key)
+		}
+	}
+
+	node, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, errors.New("downward API value " + strconv.Quote(value) + " is not a node number")
+	}
+
+	return NewNode(node, opts...)
+}
+
+// downwardAPIValue returns the value of key in a downward API file of
+// key="value" lines.
+func downwardAPIValue(b []byte, key string) (string, bool) {
+	s := bufio.NewScanner(bytes.NewReader(b))
+	for s.Scan() {
+		k, v, ok := strings.Cut(s.Text(), "=")
+		if !ok || k != key {
+			continue
+		}
+
+		if unquoted, err := strconv.Unquote(v); err == nil {
+			v = unquoted
+		}
+		return v, true
+	}
+
+	return "", false
+}
@@ -0,0 +1,76 @@
+package snowflake
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewNodeFromStatefulSetOrdinal(t *testing.T) {
+	defer func(h func() (string, error)) { hostname = h }(hostname)
+
+	for name, expected := range map[string]int64{"ids-0": 0, "ids-7": 7, "snowflake-api-42": 42} {
+		hostname = func() (string, error) { return name, nil }
+
+		node, err := NewNodeFromStatefulSetOrdinal()
+		if err != nil {
+			t.Errorf("Unexpected error for %s: %v", name, err)
+			continue
+		}
+		if node.Number() != expected {
+			t.Errorf("Got node %d for %s, expected %d", node.Number(), name, expected)
+		}
+	}
+
+	for _, name := range []string{"ids", "ids-", "ids-abc", "ids-+1", "ids-2000"} {
+		hostname = func() (string, error) { return name, nil }
+		if _, err := NewNodeFromStatefulSetOrdinal(); err == nil {
+			t.Errorf("Expected an error for %s", name)
+		}
+	}
+
+	hostname = func() (string, error) { return "", errors.New("no hostname") }
+	if _, err := StatefulSetOrdinal(); err == nil {
+		t.Error("Expected the hostname error")
+	}
+
+	hostname = func() (string, error) { return "ids-3", nil }
+	node, err := NewNodeFromStatefulSetOrdinal(WithLayout(Layout{NodeBits: 2, StepBits: 20}))
+	if err != nil || node.Number() != 3 {
+		t.Errorf("Got (%v, %v), expected node 3 with the layout option", node, err)
+	}
+}
+
+func TestNewNodeFromDownwardAPI(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	single := write("node", "17\n")
+	node, err := NewNodeFromDownwardAPI(single, "")
+	if err != nil || node.Number() != 17 {
+		t.Errorf("Got (%v, %v), expected node 17", node, err)
+	}
+
+	annotations := write("annotations", "kubernetes.io/config.seen=\"2024-01-02T03:04:05Z\"\nsnowflake/node=\"512\"\n")
+	node, err = NewNodeFromDownwardAPI(annotations, "snowflake/node")
+	if err != nil || node.Number() != 512 {
+		t.Errorf("Got (%v, %v), expected node 512", node, err)
+	}
+
+	if _, err := NewNodeFromDownwardAPI(annotations, "snowflake/missing"); err == nil {
+		t.Error("Expected an error for a missing key")
+	}
+	if _, err := NewNodeFromDownwardAPI(write("bad", "abc"), ""); err == nil {
+		t.Error("Expected an error for a non numeric value")
+	}
+	if _, err := NewNodeFromDownwardAPI(filepath.Join(dir, "missing"), ""); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
@@ -0,0 +1,307 @@
+package snowflake
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"time"
+)
+
+// A Layout describes how the 63 usable bits of a snowflake ID are split
+// between its fields.  The step uses the low StepBits bits, the node the
+// NodeBits bits above it and the time all of the remaining high bits.  An
+// Unsigned layout uses all 64 bits.
+type Layout struct {
+	NodeBits uint8
+	StepBits uint8
+
+	// TimeUnit is the duration of one tick of the time field.  It must be a
+	// whole number of milliseconds, such as 10 * time.Millisecond or
+	// time.Second, or divide a millisecond evenly, such as time.Microsecond;
+	// zero means one millisecond.  Coarser units extend the lifetime of the
+	// time field, finer units suit layouts with fewer step bits.
+	TimeUnit time.Duration
+
+	// NodeLow swaps the node and step fields, putting the node in the low
+	// NodeBits bits and the step above it.
+	NodeLow bool
+
+	// Unsigned gives the sign bit to the time field, doubling its range, for
+	// IDs stored as unsigned 64 bit integers such as MySQL BIGINT UNSIGNED.
+	// IDs from the second half of the range are negative as an ID, so they
+	// should be handled as a UID and do not sort correctly as int64s.  An
+	// unsigned layout must have at least one node bit.
+	Unsigned bool
+}
+
+// DefaultLayout is the layout used by NewNode and the ID methods, with 41
+// time bits, 10 node bits and 12 step bits.
+var DefaultLayout = Layout{NodeBits: nodeBits, StepBits: stepBits}
+
+// SonyflakeLayout is the layout of Sonyflake IDs, with 39 time bits counting
+// 10 millisecond units, 8 step bits and 16 node bits in the low bits.  Use it
+// with SonyflakeEpoch to keep generating and decoding IDs compatible with
+// Sonyflake's defaults.
+var SonyflakeLayout = Layout{NodeBits: 16, StepBits: 8, TimeUnit: 10 * time.Millisecond, NodeLow: true}
+
+// SonyflakeEpoch is Sonyflake's default start time, 2014-09-01 00:00:00 UTC,
+// in milliseconds since the unix epoch.
+const SonyflakeEpoch int64 = 1409529600000
+
+// MicrosecondLayout is a high resolution layout for tracing and other
+// systems that need IDs from the same node to order events within a
+// millisecond.  It has 52 time bits counting microseconds, about 142 years
+// from the epoch, 7 node bits and 4 step bits, for 128 nodes generating up to
+// 16 IDs a microsecond each.  Nodes using it read the clock in nanoseconds;
+// custom clocks must implement NanoClock for IDs to advance more than once a
+// millisecond.  Decode its IDs with Node.Decode or Node.IDTimestamp to keep
+// the microseconds.
+var MicrosecondLayout = Layout{NodeBits: 7, StepBits: 4, TimeUnit: time.Microsecond}
+
+// Parts holds the decoded fields of a snowflake ID.
+type Parts struct {
+	Time time.Time
+	Node int64
+	Step int64
+}
+
+// Validate returns an error if the layout leaves no bits for the time or its
+// time unit is neither a whole number of milliseconds nor divides one.
+func (l Layout) Validate() error {
+	if int(l.NodeBits)+int(l.StepBits) >= int(l.signBits()) {
+		return errors.New("layout leaves no bits for the time")
+	}
+	if l.Unsigned && l.NodeBits == 0 {
+		return errors.New("unsigned layout must have at least one node bit")
+	}
+	if l.TimeUnit < 0 || l.TimeUnit > 0 && l.TimeUnit%time.Millisecond != 0 && time.Millisecond%l.TimeUnit != 0 {
+		return errors.New("layout time unit must be a whole number of milliseconds or divide a millisecond")
+	}
+	return nil
+}
+
+// unit returns the layout's time unit in nanoseconds.
+func (l Layout) unit() int64 {
+	if l.TimeUnit == 0 {
+		return int64(time.Millisecond)
+	}
+	return int64(l.TimeUnit)
+}
+
+// timeOf returns the time of the start of tick t of the layout after epoch,
+// which is in milliseconds since the unix epoch.  It splits t into whole
+// milliseconds so even coarse units over long spans do not overflow.
+func (l Layout) timeOf(t, epoch int64) time.Time {
+	u := l.unit()
+	if u >= int64(time.Millisecond) {
+		return time.UnixMilli(epoch + t*(u/int64(time.Millisecond)))
+	}
+
+	perMs := int64(time.Millisecond) / u
+	return time.UnixMilli(epoch + t/perMs).Add(time.Duration(t % perMs * u))
+}
+
+// shifts returns the positions of the node and step fields.
+func (l Layout) shifts() (nodeShift, stepShift uint8) {
+	if l.NodeLow {
+		return 0, l.NodeBits
+	}
+	return l.StepBits, 0
+}
+
+// TimeBits returns the number of bits used by the time field.
+func (l Layout) TimeBits() uint8 {
+	return l.signBits() - l.NodeBits - l.StepBits
+}
+
+// timeLimit returns 2^TimeBits, the number of time units the time field
+// holds.  It is unsigned as a layout may have 63 time bits.
+func (l Layout) timeLimit() uint64 {
+	return 1 << l.TimeBits()
+}
+
+// timeFits reports whether elapsed time t fits in the time field.
+func (l Layout) timeFits(t int64) bool {
+	return t >= 0 && uint64(t) < l.timeLimit()
+}
+
+// signBits returns the number of bits usable by the layout's IDs.
+func (l Layout) signBits() uint8 {
+	if l.Unsigned {
+		return 64
+	}
+	return 63
+}
+
+// Decode returns the fields of id according to the layout, with the time
+// relative to epoch, which is in milliseconds since the unix epoch.
+func (l Layout) Decode(id ID, epoch int64) Parts {
+	var p Parts
+	l.DecodeInto(id, epoch, &p)
+	return p
+}
+
+// DecodeInto is like Decode but fills the caller owned p, for hot loops that
+// decode many IDs.
+func (l Layout) DecodeInto(id ID, epoch int64, p *Parts) {
+	t, node, step := l.fields(id)
+	p.Time = l.timeOf(t, epoch)
+	p.Node = node
+	p.Step = step
+}
+
+// Encode is the inverse of Decode: it packs the fields of p into a snowflake
+// ID according to the layout, with the time relative to epoch.  The time is
+// rounded down to the layout's time unit.  An error is returned if the time
+// is before epoch or a field does not fit.
+func (l Layout) Encode(p Parts, epoch int64) (ID, error) {
+	if err := l.Validate(); err != nil {
+		return 0, err
+	}
+
+	ms := p.Time.UnixMilli() - epoch
+
+	var t int64
+	if u := l.unit(); u >= int64(time.Millisecond) {
+		t = ms / (u / int64(time.Millisecond))
+	} else {
+		t = ms*(int64(time.Millisecond)/u) + int64(p.Time.Nanosecond())%int64(time.Millisecond)/u
+	}
+
+	switch {
+	case ms < 0:
+		return 0, errors.New("time is before the epoch")
+	case !l.timeFits(t):
+		return 0, fmt.Errorf("time %d does not fit in %d time bits", t, l.TimeBits())
+	case p.Node < 0 || p.Node >= 1<<l.NodeBits:
+		return 0, fmt.Errorf("node %d does not fit in %d node bits", p.Node, l.NodeBits)
+	case p.Step < 0 || p.Step >= 1<<l.StepBits:
+		return 0, fmt.Errorf("step %d does not fit in %d step bits", p.Step, l.StepBits)
+	}
+
+	nodeShift, stepShift := l.shifts()
+	return ID(t<<(l.NodeBits+l.StepBits) | p.Node<<nodeShift | p.Step<<stepShift), nil
+}
+
+// fields splits id into its raw time, node and step fields.
+func (l Layout) fields(id ID) (t, node, step int64) {
+	nodeShift, stepShift := l.shifts()
+	t = int64(uint64(id) >> (l.NodeBits + l.StepBits))
+	if !l.Unsigned {
+		t = int64(id) >> (l.NodeBits + l.StepBits)
+	}
+	node = int64(id) >> nodeShift & (1<<l.NodeBits - 1)
+	step = int64(id) >> stepShift & (1<<l.StepBits - 1)
+	return t, node, step
+}
+
+// DecodeInto fills p with the fields of the snowflake ID using DefaultLayout
+// and Epoch.  It does not allocate.
+func (f ID) DecodeInto(p *Parts) {
+	p.Time = time.UnixMilli(f.Time())
+	p.Node = f.Node()
+	p.Step = f.Step()
+}
+
+// Decompose returns the fields of the snowflake ID using DefaultLayout and
+// Epoch, with the time converted to a time.Time.
+func (f ID) Decompose() Parts {
+	var p Parts
+	f.DecodeInto(&p)
+	return p
+}
+
+// Describe returns a human readable description of the snowflake ID for
+// debugging, such as "ID 1724449608815517703 created 2023-11-14T15:30:00.123Z
+// by node 42 at step 7".  Unlike DebugString it is not meant to be parsed.
+func (f ID) Describe() string {
+	p := f.Decompose()
+	return fmt.Sprintf("ID %d created %s by node %d at step %d", int64(f), p.Time.UTC().Format("2006-01-02T15:04:05.000Z07:00"), p.Node, p.Step)
+}
+
+// Inspect parses the decimal string s and decodes it according to layout l
+// and epoch, without needing a Node.  This allows IDs from any service to be
+// decoded given their layout.  IDs of an unsigned layout are parsed as
+// unsigned, as they use the sign bit.
+func Inspect(s string, l Layout, epoch int64) (Parts, error) {
+	if err := l.Validate(); err != nil {
+		return Parts{}, err
+	}
+
+	if l.Unsigned {
+		u, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return Parts{}, fmt.Errorf("invalid ID %q: %v", s, err)
+		}
+		return l.Decode(ID(u), epoch), nil
+	}
+
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return Parts{}, fmt.Errorf("invalid ID %q: %v", s, err)
+	}
+
+	if v < 0 {
+		return Parts{}, fmt.Errorf("invalid ID %q: negative", s)
+	}
+
+	return l.Decode(ID(v), epoch), nil
+}
+
+// Relayout re-packs id from layout from into layout to, keeping its time
+// relative to the epoch, node and step unchanged.  An error is returned if a
+// field does not fit in the narrower field of the new layout, such as a node
+// number wider than the new node bits.  Converting to a coarser time unit
+// rounds the time down.
+func Relayout(id ID, from, to Layout) (ID, error) {
+	if err := from.Validate(); err != nil {
+		return 0, err
+	}
+	if err := to.Validate(); err != nil {
+		return 0, err
+	}
+
+	t, node, step := from.fields(id)
+	if t >= 0 {
+		// The time in nanoseconds can overflow an int64, so convert through
+		// 128 bits.
+		hi, lo := bits.Mul64(uint64(t), uint64(from.unit()))
+		if hi >= uint64(to.unit()) {
+			return 0, fmt.Errorf("time %d does not fit in %d time bits", t, to.TimeBits())
+		}
+		q, _ := bits.Div64(hi, lo, uint64(to.unit()))
+		t = int64(q)
+	}
+
+	switch {
+	case !to.timeFits(t):
+		return 0, fmt.Errorf("time %d does not fit in %d time bits", t, to.TimeBits())
+	case node >= 1<<to.NodeBits:
+		return 0, fmt.Errorf("node %d does not fit in %d node bits", node, to.NodeBits)
+	case step >= 1<<to.StepBits:
+		return 0, fmt.Errorf("step %d does not fit in %d step bits", step, to.StepBits)
+	}
+
+	nodeShift, stepShift := to.shifts()
+	return ID(t<<(to.NodeBits+to.StepBits) | node<<nodeShift | step<<stepShift), nil
+}
+
+// Rebase re-packs id, generated with the epoch from, to have the same
+// absolute time relative to the epoch to, keeping its node and step, using
+// DefaultLayout.  Both epochs are in milliseconds since the unix epoch.  An
+// error is returned if the time is before the new epoch or does not fit in
+// the time bits after it.  Rebased IDs from different systems order
+// correctly against each other with Compare, but the node numbers of the two
+// systems may still overlap.
+func Rebase(id ID, from, to int64) (ID, error) {
+	t := int64(id)>>timeShift + from - to
+	switch {
+	case t < 0:
+		return 0, errors.New("time is before the new epoch")
+	case t >= 1<<(63-timeShift):
+		return 0, fmt.Errorf("time %d does not fit in %d time bits", t, 63-timeShift)
+	}
+
+	return ID(t<<timeShift | int64(id)&(1<<timeShift-1)), nil
+}
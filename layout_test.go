@@ -0,0 +1,444 @@
+package snowflake
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestInspect(t *testing.T) {
+	node, _ := NewNode(42)
+	id := node.Generate()
+
+	p, err := Inspect(id.String(), DefaultLayout, Epoch)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if p.Time.UnixNano()/1000000 != id.Time() || p.Node != id.Node() || p.Step != id.Step() {
+		t.Errorf("Got %+v, expected time %d node %d step %d", p, id.Time(), id.Node(), id.Step())
+	}
+
+	const epoch int64 = 1420070400000
+	l := Layout{NodeBits: 5, StepBits: 17}
+	created := time.Date(2022, 3, 4, 5, 6, 7, 8000000, time.UTC)
+	custom := ID((created.UnixNano()/1000000-epoch)<<22 | 17<<17 | 100000)
+
+	p, err = Inspect(custom.String(), l, epoch)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !p.Time.Equal(created) || p.Node != 17 || p.Step != 100000 {
+		t.Errorf("Got %+v, expected time %s node 17 step 100000", p, created)
+	}
+
+	for _, bad := range []string{"", "abc", "-5", "99999999999999999999"} {
+		if _, err := Inspect(bad, DefaultLayout, Epoch); err == nil {
+			t.Errorf("Expected an error inspecting %q", bad)
+		}
+	}
+
+	if _, err := Inspect("1", Layout{NodeBits: 40, StepBits: 23}, Epoch); err == nil {
+		t.Error("Expected an error for a layout without time bits")
+	}
+
+	unsigned := Layout{NodeBits: 10, StepBits: 12, Unsigned: true}
+	p, err = Inspect("18446744073709551615", unsigned, Epoch)
+	if err != nil {
+		t.Fatalf("Unexpected error inspecting the largest unsigned ID: %v", err)
+	}
+	if expected := unsigned.Decode(ID(-1), Epoch); p != expected {
+		t.Errorf("Got %+v, expected %+v", p, expected)
+	}
+	for _, bad := range []string{"-5", "18446744073709551616"} {
+		if _, err := Inspect(bad, unsigned, Epoch); err == nil {
+			t.Errorf("Expected an error inspecting %q with an unsigned layout", bad)
+		}
+	}
+}
+
+func TestDecodeInto(t *testing.T) {
+	node, _ := NewNode(42)
+	id := node.Generate()
+
+	var p Parts
+	id.DecodeInto(&p)
+	if expected := DefaultLayout.Decode(id, Epoch); p != expected {
+		t.Errorf("Got %+v, expected %+v", p, expected)
+	}
+
+	if allocs := testing.AllocsPerRun(100, func() { id.DecodeInto(&p) }); allocs != 0 {
+		t.Errorf("Got %v allocations, expected 0", allocs)
+	}
+}
+
+func BenchmarkDecode(b *testing.B) {
+	node, _ := NewNode(1)
+	id := node.Generate()
+
+	var p Parts
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		p = DefaultLayout.Decode(id, Epoch)
+	}
+	_ = p
+}
+
+func BenchmarkDecodeInto(b *testing.B) {
+	node, _ := NewNode(1)
+	id := node.Generate()
+
+	var p Parts
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		id.DecodeInto(&p)
+	}
+}
+
+func TestRelayout(t *testing.T) {
+	wide := Layout{NodeBits: 12, StepBits: 10}
+
+	id := ID(123456789<<timeShift | 1000<<nodeShift | 900)
+
+	moved, err := Relayout(id, DefaultLayout, wide)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if p, q := DefaultLayout.Decode(id, Epoch), wide.Decode(moved, Epoch); p != q {
+		t.Errorf("Got %+v after relayout, expected %+v", q, p)
+	}
+
+	back, err := Relayout(moved, wide, DefaultLayout)
+	if err != nil || back != id {
+		t.Errorf("Got (%d, %v) relayouting back, expected (%d, nil)", back, err, id)
+	}
+
+	bigNode := ID(1<<22 | 4000<<10 | 5)
+	if _, err := Relayout(bigNode, wide, DefaultLayout); err == nil {
+		t.Error("Expected an error for a node that does not fit")
+	}
+
+	bigStep := ID(1<<22 | 3<<12 | 2000)
+	if _, err := Relayout(bigStep, DefaultLayout, wide); err == nil {
+		t.Error("Expected an error for a step that does not fit")
+	}
+
+	if _, err := Relayout(ID(1<<62), Layout{NodeBits: 1, StepBits: 1}, DefaultLayout); err == nil {
+		t.Error("Expected an error for a time that does not fit")
+	}
+}
+
+func TestWithLayout(t *testing.T) {
+	l := Layout{NodeBits: 6, StepBits: 16}
+
+	node, err := NewNode(63, WithLayout(l))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if node.Layout() != l {
+		t.Errorf("Got layout %+v, expected %+v", node.Layout(), l)
+	}
+
+	seen := make(map[ID]bool)
+	for i := 0; i < 20000; i++ {
+		id := node.Generate()
+		if seen[id] {
+			t.Fatalf("Duplicate ID %d", id)
+		}
+		seen[id] = true
+
+		p := node.Decode(id)
+		if p.Node != 63 {
+			t.Fatalf("Got node %d, expected 63", p.Node)
+		}
+
+		if d := time.Since(p.Time); d < 0 || d > time.Second {
+			t.Fatalf("Got time %s, expected close to now", p.Time)
+		}
+	}
+
+	if _, err := NewNode(64, WithLayout(l)); err == nil {
+		t.Error("Expected an error for a node that does not fit the layout")
+	}
+
+	if _, err := NewNode(1, WithLayout(Layout{NodeBits: 31, StepBits: 32})); err == nil {
+		t.Error("Expected an error for an invalid layout")
+	}
+}
+
+func TestSonyflakeLayout(t *testing.T) {
+	now := SonyflakeEpoch + 123456780
+	clock := WithClock(ClockFunc(func() int64 { return now }))
+
+	node, err := NewNode(0xBEEF, WithLayout(SonyflakeLayout), WithEpoch(SonyflakeEpoch), clock)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Sonyflake packs elapsed 10ms units, then the sequence, then the machine.
+	elapsed := int64(12345678)
+	for step := int64(0); step < 3; step++ {
+		id := node.Generate()
+		if expected := ID(elapsed<<24 | step<<16 | 0xBEEF); id != expected {
+			t.Fatalf("Got %d for step %d, expected %d", id, step, expected)
+		}
+	}
+
+	// Other milliseconds within the same 10ms unit continue the sequence.
+	now += 5
+	id := node.Generate()
+	if expected := ID(elapsed<<24 | 3<<16 | 0xBEEF); id != expected {
+		t.Errorf("Got %d, expected %d", id, expected)
+	}
+
+	p := node.Decode(id)
+	if expected := time.UnixMilli(SonyflakeEpoch + elapsed*10); !p.Time.Equal(expected) || p.Node != 0xBEEF || p.Step != 3 {
+		t.Errorf("Got %+v, expected time %s, node 48879 and step 3", p, expected)
+	}
+
+	ms := Layout{NodeBits: 16, StepBits: 8}
+	moved, err := Relayout(id, SonyflakeLayout, ms)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if q := ms.Decode(moved, SonyflakeEpoch); q != p {
+		t.Errorf("Got %+v after relayout, expected %+v", q, p)
+	}
+	if back, err := Relayout(moved, ms, SonyflakeLayout); err != nil || back != id {
+		t.Errorf("Got (%d, %v) relayouting back, expected (%d, nil)", back, err, id)
+	}
+
+	if err := (Layout{NodeBits: 10, StepBits: 12, TimeUnit: 1500 * time.Microsecond}).Validate(); err == nil {
+		t.Error("Expected an error for a fractional millisecond time unit")
+	}
+}
+
+func TestNodeIDFields(t *testing.T) {
+	for _, l := range []Layout{DefaultLayout, {NodeBits: 5, StepBits: 17}, SonyflakeLayout} {
+		node, err := NewNode(21, WithLayout(l), WithEpoch(SonyflakeEpoch))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		id := node.Generate()
+		p := node.Decode(id)
+
+		if got := node.IDTime(id); got != p.Time.UnixMilli() {
+			t.Errorf("Got time %d with layout %+v, expected %d", got, l, p.Time.UnixMilli())
+		}
+		if got := node.IDNode(id); got != 21 {
+			t.Errorf("Got node %d with layout %+v, expected 21", got, l)
+		}
+		if got := node.IDStep(id); got != p.Step {
+			t.Errorf("Got step %d with layout %+v, expected %d", got, l, p.Step)
+		}
+	}
+}
+
+func TestDecompose(t *testing.T) {
+	ms := time.Date(2023, 11, 14, 15, 30, 0, 123000000, time.UTC).UnixMilli()
+	id := ID((ms-Epoch)<<timeShift | 42<<nodeShift | 7)
+
+	p := id.Decompose()
+	if expected := (Parts{Time: time.UnixMilli(ms), Node: 42, Step: 7}); p != expected {
+		t.Errorf("Got %+v, expected %+v", p, expected)
+	}
+
+	expected := "ID " + id.String() + " created 2023-11-14T15:30:00.123Z by node 42 at step 7"
+	if d := id.Describe(); d != expected {
+		t.Errorf("Got %q, expected %q", d, expected)
+	}
+}
+
+func TestLayoutEncode(t *testing.T) {
+	for _, l := range []Layout{DefaultLayout, {NodeBits: 5, StepBits: 17}, SonyflakeLayout} {
+		p := Parts{Time: time.UnixMilli(SonyflakeEpoch + 123456780), Node: 21, Step: 30}
+
+		id, err := l.Encode(p, SonyflakeEpoch)
+		if err != nil {
+			t.Fatalf("Unexpected error with layout %+v: %v", l, err)
+		}
+		if q := l.Decode(id, SonyflakeEpoch); q != p {
+			t.Errorf("Got %+v with layout %+v, expected %+v", q, l, p)
+		}
+	}
+
+	for _, p := range []Parts{
+		{Time: time.UnixMilli(Epoch - 1)},
+		{Time: time.UnixMilli(Epoch), Node: 1024},
+		{Time: time.UnixMilli(Epoch), Step: -1},
+	} {
+		if _, err := DefaultLayout.Encode(p, Epoch); err == nil {
+			t.Errorf("Expected an error encoding %+v", p)
+		}
+	}
+}
+
+type nanoClock struct{ ns int64 }
+
+func (c *nanoClock) Now() int64     { return c.ns / int64(time.Millisecond) }
+func (c *nanoClock) NowNano() int64 { return c.ns }
+
+func TestTimeUnits(t *testing.T) {
+	for _, unit := range []time.Duration{time.Microsecond, 100 * time.Microsecond, 10 * time.Millisecond, time.Second} {
+		if err := (Layout{NodeBits: 10, StepBits: 8, TimeUnit: unit}).Validate(); err != nil {
+			t.Errorf("Unexpected error for a unit of %s: %v", unit, err)
+		}
+	}
+	for _, unit := range []time.Duration{3 * time.Microsecond, 1500 * time.Microsecond, -time.Millisecond} {
+		if err := (Layout{NodeBits: 10, StepBits: 8, TimeUnit: unit}).Validate(); err == nil {
+			t.Errorf("Expected an error for a unit of %s", unit)
+		}
+	}
+
+	micro := Layout{NodeBits: 10, StepBits: 8, TimeUnit: time.Microsecond}
+	clock := &nanoClock{ns: (Epoch + 1000) * int64(time.Millisecond)}
+	node, err := NewNode(5, WithLayout(micro), WithClock(clock))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	first := node.Generate()
+	clock.ns += 1500
+	second := node.Generate()
+
+	if expected := ID(1000000<<18 | 5<<8); first != expected {
+		t.Errorf("Got %d, expected %d", first, expected)
+	}
+	if expected := ID(1000001<<18 | 5<<8); second != expected {
+		t.Errorf("Got %d, expected %d for one more microsecond", second, expected)
+	}
+
+	p := node.Decode(second)
+	if expected := time.UnixMilli(Epoch + 1000).Add(time.Microsecond); !p.Time.Equal(expected) {
+		t.Errorf("Got time %s, expected %s", p.Time, expected)
+	}
+	if id, err := micro.Encode(p, Epoch); err != nil || id != second {
+		t.Errorf("Got (%d, %v) encoding %+v, expected (%d, nil)", id, err, p, second)
+	}
+	if ms := node.IDTime(second); ms != Epoch+1000 {
+		t.Errorf("Got time %d, expected %d", ms, Epoch+1000)
+	}
+
+	moved, err := Relayout(second, micro, DefaultLayout)
+	if err != nil || moved != ID(1000<<timeShift|5<<nodeShift) {
+		t.Errorf("Got (%d, %v) relayouting to milliseconds, expected (%d, nil)", moved, err, ID(1000<<timeShift|5<<nodeShift))
+	}
+
+	seconds := Layout{NodeBits: 10, StepBits: 12, TimeUnit: time.Second}
+	coarse, _ := NewNode(5, WithLayout(seconds))
+	if d, err := coarse.LifetimeAtRate(1); err != nil || d != time.Duration(1<<63-1) {
+		t.Errorf("Got (%s, %v), expected the lifetime to saturate", d, err)
+	}
+	if p := coarse.Decode(coarse.Generate()); time.Since(p.Time) < 0 || time.Since(p.Time) > 2*time.Second {
+		t.Errorf("Got time %s, expected within a second of now", p.Time)
+	}
+}
+
+func TestRebase(t *testing.T) {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 678000000, time.UTC).UnixMilli()
+	id := ID((at-DiscordEpoch)<<timeShift | 9<<nodeShift | 3)
+
+	rebased, err := Rebase(id, DiscordEpoch, Epoch)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if rebased.Time() != at || rebased.Node() != 9 || rebased.Step() != 3 {
+		t.Errorf("Got %s, expected time %d node 9 step 3", rebased.Describe(), at)
+	}
+	if CompareAcrossEpochs(id, DiscordEpoch, rebased, Epoch) != 0 {
+		t.Error("Expected the rebased ID to compare equal to the original")
+	}
+
+	if back, err := Rebase(rebased, Epoch, DiscordEpoch); err != nil || back != id {
+		t.Errorf("Got (%d, %v) rebasing back, expected (%d, nil)", back, err, id)
+	}
+
+	// Twitter's epoch is before Discord's, so its early IDs do not fit.
+	if _, err := Rebase(ID(5<<timeShift), Epoch, DiscordEpoch); err == nil {
+		t.Error("Expected an error for a time before the new epoch")
+	}
+	if _, err := Rebase(ID(1<<63-1), DiscordEpoch, Epoch); err == nil {
+		t.Error("Expected an error for a time past the end of the time bits")
+	}
+}
+
+func TestMicrosecondLayout(t *testing.T) {
+	if bits := MicrosecondLayout.TimeBits(); bits != 52 {
+		t.Errorf("Got %d time bits, expected 52", bits)
+	}
+
+	at := time.Date(2024, 1, 2, 3, 4, 5, 678901234, time.UTC)
+	clock := &nanoClock{ns: at.UnixNano()}
+	node, err := NewNode(100, WithLayout(MicrosecondLayout), WithClock(clock))
+	if err != nil {
+		t.Fatalf("error creating NewNode, %s", err)
+	}
+
+	first := node.Generate()
+	clock.ns += int64(3 * time.Microsecond)
+	second := node.Generate()
+
+	if got := node.IDTimestamp(first); !got.Equal(at.Truncate(time.Microsecond)) {
+		t.Errorf("Got %s, expected %s", got.UTC(), at.Truncate(time.Microsecond))
+	}
+	if d := node.IDTimestamp(second).Sub(node.IDTimestamp(first)); d != 3*time.Microsecond {
+		t.Errorf("Got IDs %s apart, expected 3µs", d)
+	}
+	if node.IDTime(first) != at.UnixMilli() || node.IDNode(second) != 100 {
+		t.Errorf("Got time %d node %d, expected %d and 100", node.IDTime(first), node.IDNode(second), at.UnixMilli())
+	}
+
+	if max := node.MaxTime(); max.Year() < 2150 {
+		t.Errorf("Got a max time of %s, expected over a century", max)
+	}
+}
+
+func TestLayout63TimeBits(t *testing.T) {
+	for _, l := range []Layout{{}, {NodeBits: 1, Unsigned: true}} {
+		if l.TimeBits() != 63 {
+			t.Fatalf("Got %d time bits for %+v, expected 63", l.TimeBits(), l)
+		}
+
+		p := Parts{Time: time.UnixMilli(Epoch + 123456789)}
+		id, err := l.Encode(p, Epoch)
+		if err != nil {
+			t.Fatalf("Unexpected error encoding with %+v: %v", l, err)
+		}
+		if got := l.Decode(id, Epoch); !got.Time.Equal(p.Time) {
+			t.Errorf("Got time %s decoding with %+v, expected %s", got.Time, l, p.Time)
+		}
+
+		if _, err := Relayout(id, l, l); err != nil {
+			t.Errorf("Unexpected error relayouting with %+v: %v", l, err)
+		}
+
+		var warned bool
+		node, err := NewNode(0, WithLayout(l), WithLifetimeWarning(0.5, func(time.Duration) { warned = true }))
+		if err != nil {
+			t.Fatalf("Unexpected error creating a node with %+v: %v", l, err)
+		}
+		if node.warnAt <= 0 {
+			t.Errorf("Got a lifetime warning at %d with %+v, expected a positive time", node.warnAt, l)
+		}
+		if _, err := node.GenerateSafe(); err != nil {
+			t.Errorf("Unexpected error generating with %+v: %v", l, err)
+		}
+		if warned {
+			t.Errorf("Lifetime warning fired early with %+v", l)
+		}
+		if max := node.MaxTime(); !max.After(time.Now().AddDate(1000000, 0, 0)) {
+			t.Errorf("Got a maximum time of %s with %+v, expected far in the future", max, l)
+		}
+		if node.Remaining() != math.MaxInt64 {
+			t.Errorf("Got %s remaining with %+v, expected the largest Duration", node.Remaining(), l)
+		}
+	}
+}
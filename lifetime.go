@@ -0,0 +1,84 @@
+package snowflake
+
+import (
+	"errors"
+	"math"
+	"math/bits"
+	"time"
+)
+
+// MaxTime returns the time at which the node's time field overflows, given
+// its epoch and layout.  IDs can only be generated before it; GenerateSafe
+// returns ErrTimestampOverflow from then on.  With the default layout and
+// Epoch that is 2080-07-10 17:30:30.209 UTC.
+func (n *Node) MaxTime() time.Time {
+	return n.layout.limitTime(n.epoch)
+}
+
+// limitTime returns the time at which the layout's time field overflows.
+// The 2^TimeBits time units of a layout can overflow an int64 of
+// nanoseconds or milliseconds, so the time is counted in 128 bits, and
+// clamped to 2^62 seconds after the unix epoch for layouts reaching past
+// it.
+func (l Layout) limitTime(epoch int64) time.Time {
+	hi, lo := bits.Mul64(l.timeLimit(), uint64(l.unit()))
+	if hi >= 1e9 {
+		return time.Unix(1<<62, 0)
+	}
+
+	sec, ns := bits.Div64(hi, lo, 1e9)
+	if sec >= 1<<62 {
+		return time.Unix(1<<62, 0)
+	}
+	return time.Unix(int64(sec), int64(ns)).Add(time.Duration(epoch) * time.Millisecond)
+}
+
+// Remaining returns the time left until MaxTime by the node's clock, or zero
+// once it has passed.  Durations beyond the range of time.Duration, about
+// 292 years, are reported as the largest Duration.
+func (n *Node) Remaining() time.Duration {
+	since := n.now() - n.epoch*int64(time.Millisecond)
+
+	elapsed := since / n.unit
+	switch {
+	case elapsed < 0:
+		return math.MaxInt64
+	case uint64(elapsed) >= n.layout.timeLimit():
+		return 0
+	}
+
+	units := n.layout.timeLimit() - uint64(elapsed)
+	if units > math.MaxInt64/uint64(n.unit) {
+		return math.MaxInt64
+	}
+
+	return time.Duration(int64(units)*n.unit - since%n.unit)
+}
+
+// WithLifetimeWarning makes the node call fn once, the first time it
+// generates an ID after the given fraction of its time field's range has
+// been used, such as 0.95, passing the time remaining until MaxTime.  fn is
+// called in its own goroutine, so it may log, alert or even generate IDs.
+// fraction must be between 0 and 1.
+func WithLifetimeWarning(fraction float64, fn func(remaining time.Duration)) Option {
+	return func(n *Node) error {
+		if !(fraction > 0 && fraction < 1) {
+			return errors.New("lifetime warning fraction must be between 0 and 1")
+		}
+		if fn == nil {
+			return errors.New("lifetime warning callback must not be nil")
+		}
+
+		n.warnFraction = fraction
+		n.onWarn = fn
+		return nil
+	}
+}
+
+// warnLifetime calls the lifetime warning callback unless it has been called
+// already.
+func (n *Node) warnLifetime() {
+	if n.warned.CompareAndSwap(false, true) {
+		go n.onWarn(n.Remaining())
+	}
+}
@@ -0,0 +1,84 @@
+package snowflake
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestMaxTime(t *testing.T) {
+	node, _ := NewNode(1)
+	if expected := time.Date(2080, 7, 10, 17, 30, 30, 209000000, time.UTC); !node.MaxTime().Equal(expected) {
+		t.Errorf("Got %s, expected %s", node.MaxTime().UTC(), expected)
+	}
+
+	if d := time.Until(node.MaxTime()) - node.Remaining(); d < -time.Second || d > time.Second {
+		t.Errorf("Got %s remaining, expected about %s", node.Remaining(), time.Until(node.MaxTime()))
+	}
+
+	sony, _ := NewNode(1, WithPreset(Preset{Layout: SonyflakeLayout, Epoch: SonyflakeEpoch}))
+	if expected := time.UnixMilli(SonyflakeEpoch).Add(1 << 39 * 10 * time.Millisecond); !sony.MaxTime().Equal(expected) {
+		t.Errorf("Got %s, expected %s", sony.MaxTime(), expected)
+	}
+
+	seconds, _ := NewNode(1, WithLayout(Layout{NodeBits: 10, StepBits: 12, TimeUnit: time.Second}))
+	if r := seconds.Remaining(); r != math.MaxInt64 {
+		t.Errorf("Got %s, expected the largest Duration", r)
+	}
+
+	// A 4 bit time field overflows 16ms after the epoch.
+	now := Epoch + 20
+	tiny, _ := NewNode(1, WithLayout(Layout{NodeBits: 30, StepBits: 29}), WithClock(ClockFunc(func() int64 { return now })))
+	if r := tiny.Remaining(); r != 0 {
+		t.Errorf("Got %s, expected 0 after the overflow", r)
+	}
+	now = Epoch + 10
+	if r := tiny.Remaining(); r != 6*time.Millisecond {
+		t.Errorf("Got %s, expected 6ms", r)
+	}
+}
+
+func TestWithLifetimeWarning(t *testing.T) {
+	now := Epoch
+	warned := make(chan time.Duration, 2)
+
+	// A 6 bit time field spans 64ms, so 75% is crossed after 48ms.
+	node, err := NewNode(1,
+		WithLayout(Layout{NodeBits: 30, StepBits: 27}),
+		WithClock(ClockFunc(func() int64 { return now })),
+		WithLifetimeWarning(0.75, func(remaining time.Duration) { warned <- remaining }),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	now = Epoch + 47
+	node.Generate()
+	select {
+	case <-warned:
+		t.Fatal("Got a warning before crossing the threshold")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	now = Epoch + 50
+	node.Generate()
+	node.Generate()
+
+	if r := <-warned; r != 14*time.Millisecond {
+		t.Errorf("Got %s remaining, expected 14ms", r)
+	}
+	select {
+	case <-warned:
+		t.Error("Got a second warning")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	for _, fraction := range []float64{0, 1, -0.5, math.NaN()} {
+		if _, err := NewNode(1, WithLifetimeWarning(fraction, func(time.Duration) {})); err == nil {
+			t.Errorf("Expected an error for a fraction of %v", fraction)
+		}
+	}
+	if _, err := NewNode(1, WithLifetimeWarning(0.9, nil)); err == nil {
+		t.Error("Expected an error for a nil callback")
+	}
+}
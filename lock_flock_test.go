@@ -0,0 +1,38 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package snowflake
+
+import "testing"
+
+func TestNewNodeFileLocked(t *testing.T) {
+	dir := t.TempDir()
+
+	node, unlock, err := NewNodeFileLocked(7, dir)
+	if err != nil {
+		t.Fatalf("Unexpected error locking node: %v", err)
+	}
+
+	if node.node != 7 {
+		t.Errorf("Got node %d, expected 7", node.node)
+	}
+
+	if _, _, err := NewNodeFileLocked(7, dir); err != ErrNodeLocked {
+		t.Errorf("Got %v, expected ErrNodeLocked", err)
+	}
+
+	if _, unlock8, err := NewNodeFileLocked(8, dir); err != nil {
+		t.Errorf("Unexpected error locking a different node: %v", err)
+	} else {
+		unlock8()
+	}
+
+	if err := unlock(); err != nil {
+		t.Fatalf("Unexpected error unlocking node: %v", err)
+	}
+
+	_, unlock, err = NewNodeFileLocked(7, dir)
+	if err != nil {
+		t.Fatalf("Unexpected error relocking node: %v", err)
+	}
+	unlock()
+}
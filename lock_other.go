@@ -0,0 +1,9 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package snowflake
+
+import "errors"
+
+func lockFile(path string) (func() error, error) {
+	return nil, errors.New("file locking is not supported on this platform")
+}
@@ -0,0 +1,143 @@
+package snowflake
+
+import "math"
+
+// stateLocked is set in the packed time and step word while WithLock or
+// GenerateN hold it, so that compare and swap generators wait instead of
+// interleaving IDs with them.  The word never needs the sign bit, as the
+// time and step bits of a layout take at most 63 bits.
+const stateLocked = math.MinInt64
+
+// WithLockFree makes the node generate IDs with a compare and swap loop on a
+// single packed time and step word instead of taking the node mutex.  This
+// is the default for nodes without a StateStore, so the option only makes
+// that explicit, and it is an error to combine it with WithStateStore.
+//
+// The clock is never allowed to run backwards; if it does, the node keeps
+// counting from its last time and waits for the clock to catch up once the
+// step is exhausted.  WithLock and GenerateN hold the word for as long as
+// they generate, so their IDs stay contiguous.
+func WithLockFree() Option {
+	return func(n *Node) error {
+		n.lockFree = true
+		return nil
+	}
+}
+
+// generateCAS is the lock free counterpart of generateNode.
+func (n *Node) generateCAS(node int64) ID {
+	var waited waitReason
+	for {
+		id, t, wait := n.tryCAS(node)
+		if wait == waitNone {
+			return id
+		}
+
+		waited = n.recordWait(waited, wait)
+		n.pause(wait, t)
+	}
+}
+
+// tryCAS makes one attempt at generating an ID without locking.  n.state
+// holds the time since the epoch shifted above the step, so adding one to it
+// either advances the step or rolls over into the next time unit, which must
+// wait until the clock has reached it.  On failure it returns why, along with
+// the elapsed time the clock must reach before retrying.
+func (n *Node) tryCAS(node int64) (ID, int64, waitReason) {
+	old := n.state.Load()
+	if old&stateLocked != 0 {
+		// Wait for WithLock or GenerateN to release the word.
+		n.Lock()
+		n.Unlock()
+		return 0, 0, waitRetry
+	}
+
+	next, t, wait := n.nextState(old)
+	if wait != waitNone {
+		return 0, t, wait
+	}
+
+	if !n.state.CompareAndSwap(old, next) {
+		return 0, t, waitRetry
+	}
+
+	n.took(old, next, t)
+	n.count.Add(1)
+	return n.compose(next>>n.layout.StepBits, node, next&n.stepMask), 0, waitNone
+}
+
+// nextState returns the packed time and step following old, along with the
+// current elapsed time.  If the step is exhausted it instead returns
+// waitExhausted and the elapsed time the clock must reach.
+func (n *Node) nextState(old int64) (int64, int64, waitReason) {
+	stepBits := n.layout.StepBits
+	now := n.elapsed()
+
+	next := old + 1
+	if floor := now<<stepBits | n.firstStep(); floor > next {
+		next = floor
+	}
+
+	if t := next >> stepBits; t > now && t > old>>stepBits && n.exhaustion != BorrowOnExhausted {
+		return 0, t, waitExhausted
+	}
+	return next, now, waitNone
+}
+
+// took counts a move of the packed word from old to next, made at elapsed
+// time now, in the node's stats.
+func (n *Node) took(old, next, now int64) {
+	stepBits := n.layout.StepBits
+
+	// The clock being behind does not make the lock free path wait, as it
+	// keeps counting from its last time, but it is still reported.
+	if now < old>>stepBits {
+		n.behind.Add(1)
+	}
+
+	if t := next >> stepBits; t > now && t > old>>stepBits {
+		n.borrowed.Add(1)
+	}
+}
+
+// holdState takes the node lock and marks the packed word as held, so that
+// no other goroutine generates IDs until releaseState.  The held value is
+// kept in n.held.
+func (n *Node) holdState() {
+	n.Lock()
+	for {
+		old := n.state.Load()
+		if n.state.CompareAndSwap(old, old|stateLocked) {
+			n.held = old
+			return
+		}
+	}
+}
+
+// releaseState publishes the held word and releases the node lock.
+func (n *Node) releaseState() {
+	n.state.Store(n.held)
+	n.Unlock()
+}
+
+// generateHeld is like generateCAS for a caller holding the packed word.
+func (n *Node) generateHeld(node int64) ID {
+	var waited waitReason
+	for {
+		next, t, wait := n.nextState(n.held)
+		if wait != waitNone {
+			waited = n.recordWait(waited, wait)
+			n.pause(wait, t)
+			continue
+		}
+
+		n.took(n.held, next, t)
+		n.held = next
+		return n.compose(next>>n.layout.StepBits, node, next&n.stepMask)
+	}
+}
+
+// loadState returns the packed time and step word, without the held mark.
+func (n *Node) loadState() int64 {
+	return n.state.Load() &^ stateLocked
+}
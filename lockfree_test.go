@@ -0,0 +1,168 @@
+package snowflake
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithLockFree(t *testing.T) {
+	node, err := NewNode(7, WithLockFree())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	const workers, perWorker = 8, 5000
+
+	var wg sync.WaitGroup
+	results := make([][]ID, workers)
+	for w := range results {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+
+			ids := make([]ID, perWorker)
+			for i := range ids {
+				ids[i] = node.Generate()
+			}
+			results[w] = ids
+		}(w)
+	}
+	wg.Wait()
+
+	seen := make(map[ID]bool, workers*perWorker)
+	for _, ids := range results {
+		for i, id := range ids {
+			if seen[id] {
+				t.Fatalf("Duplicate ID %d", id)
+			}
+			seen[id] = true
+
+			if i > 0 && id <= ids[i-1] {
+				t.Fatalf("ID %d is not greater than %d", id, ids[i-1])
+			}
+
+			if id.Node() != 7 {
+				t.Fatalf("Got node %d, expected 7", id.Node())
+			}
+		}
+	}
+
+	if c := node.TakeCount(); c != workers*perWorker {
+		t.Errorf("Got a count of %d, expected %d", c, workers*perWorker)
+	}
+}
+
+func TestWithLockFreeClockBackwards(t *testing.T) {
+	now := nowMillis()
+	node, _ := NewNode(1, WithLockFree(), WithClock(ClockFunc(func() int64 { return now })))
+
+	first := node.Generate()
+
+	now -= 1000
+	second := node.Generate()
+
+	if second <= first {
+		t.Errorf("ID %d is not greater than %d after the clock went backwards", second, first)
+	}
+
+	if second.Time() != first.Time() {
+		t.Errorf("Got time %d, expected the last time %d", second.Time(), first.Time())
+	}
+}
+
+// withMutex makes the node generate under the node mutex, as nodes with a
+// StateStore do, so tests can cover both paths.
+func withMutex() Option {
+	return func(n *Node) error {
+		n.mutex = true
+		return nil
+	}
+}
+
+func TestLockFreeDefault(t *testing.T) {
+	if node, _ := NewNode(1); !node.lockFree {
+		t.Error("Expected nodes to be lock free by default")
+	}
+	if node, _ := NewNode(1, WithStateStore(&memStore{}, time.Second)); node.lockFree {
+		t.Error("Expected a node with a state store to use the mutex")
+	}
+}
+
+func TestLockFreeContiguous(t *testing.T) {
+	node, _ := NewNode(1, WithLayout(contentionLayout))
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					node.Generate()
+				}
+			}
+		}()
+	}
+
+	// Neither GenerateN nor WithLock may interleave with the concurrent
+	// Generate calls, so their IDs are consecutive steps.
+	consecutive := func(ids []ID) bool {
+		for i := 1; i < len(ids); i++ {
+			if ids[i] != ids[i-1]+1 && node.IDTime(ids[i]) == node.IDTime(ids[i-1]) {
+				return false
+			}
+		}
+		return true
+	}
+	for i := 0; i < 200; i++ {
+		if ids := node.GenerateN(64); !consecutive(ids) {
+			t.Fatalf("GenerateN returned non contiguous IDs %v", ids)
+		}
+
+		var ids []ID
+		node.WithLock(func(gen func() ID) {
+			for j := 0; j < 64; j++ {
+				ids = append(ids, gen())
+			}
+		})
+		if !consecutive(ids) {
+			t.Fatalf("WithLock generated non contiguous IDs %v", ids)
+		}
+	}
+
+	close(stop)
+	wg.Wait()
+}
+
+// The default layout caps a node at 4096 IDs a millisecond, which hides the
+// cost of locking, so these use a layout with a much larger step.
+var contentionLayout = Layout{NodeBits: 2, StepBits: 20}
+
+// benchmarkContended generates IDs from at least 32 goroutines sharing the
+// node.
+func benchmarkContended(b *testing.B, node *Node) {
+	b.SetParallelism((32 + runtime.GOMAXPROCS(0) - 1) / runtime.GOMAXPROCS(0))
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_ = node.Generate()
+		}
+	})
+}
+
+func BenchmarkGenerateMutex32(b *testing.B) {
+	node, _ := NewNode(1, WithLayout(contentionLayout), withMutex())
+	benchmarkContended(b, node)
+}
+
+func BenchmarkGenerateLockFree32(b *testing.B) {
+	node, _ := NewNode(1, WithLayout(contentionLayout))
+	benchmarkContended(b, node)
+}
@@ -0,0 +1,116 @@
+package snowflake
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/binary"
+	"errors"
+	"net"
+	"os"
+)
+
+// machineIDPaths lists the files NewNodeByMachineID reads, in order.
+var machineIDPaths = []string{"/etc/machine-id", "/var/lib/dbus/machine-id"}
+
+// NewNodeByMachineID is a convenience method which creates a new Node based
+// off a hash of the machine's stable hardware identity.  It reads the systemd
+// machine id from /etc/machine-id or /var/lib/dbus/machine-id and falls back
+// to the hardware address of the first non-loopback network interface.  The
+// machine id file only exists on Linux, so other platforms use the MAC
+// address.
+//
+// Like NewNodeByHostname, the identity is hashed into only 1024 node numbers
+// with the default layout, so collisions become likely with a few dozen
+// machines.  Use explicit node numbers where uniqueness matters.  opts are
+// passed to NewNode, and the hash is cut to the node bits of the layout they
+// set, less any region bits.
+func NewNodeByMachineID(opts ...Option) (*Node, error) {
+	id, err := machineID()
+	if err != nil {
+		return nil, err
+	}
+
+	hash := md5.Sum(id)
+	return NewNode(int64(binary.BigEndian.Uint64(hash[:]))&nodeMaskOf(opts), opts...)
+}
+
+// nodeMaskOf returns the mask of the node numbers available to a node
+// created with opts, for the constructors that cut a node number from a
+// larger value.  Invalid layouts return the default mask and are reported by
+// NewNode.
+func nodeMaskOf(opts []Option) int64 {
+	probe := &Node{layout: DefaultLayout}
+	for _, opt := range opts {
+		if opt(probe) != nil {
+			return nodeMax
+		}
+	}
+
+	if probe.layout.Validate() != nil || probe.regionBits > probe.layout.NodeBits {
+		return nodeMax
+	}
+	return -1 ^ (-1 << (probe.layout.NodeBits - probe.regionBits))
+}
+
+// machineID returns the contents of the first non-empty machine id file, or
+// the first non-loopback hardware address.
+func machineID() ([]byte, error) {
+	for _, path := range machineIDPaths {
+		b, err := os.ReadFile(path)
+		if b = bytes.TrimSpace(b); err == nil && len(b) > 0 {
+			return b, nil
+		}
+	}
+
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagLoopback == 0 && len(iface.HardwareAddr) > 0 {
+			return iface.HardwareAddr, nil
+		}
+	}
+
+	return nil, errors.New("no machine id or hardware address found")
+}
+
+// NewNodeFromIP is a convenience method which creates a new Node based off
+// the low 10 bits of a private IPv4 address, like Sonyflake does.  Pod and
+// host addresses are unique within a subnet, so nodes on the same /22 or
+// smaller subnet never collide, unlike hashed hostnames.  opts are passed to
+// NewNode, and a layout with more or fewer node bits, less any region bits,
+// takes that many low bits instead, such as 16 with SonyflakeLayout.
+func NewNodeFromIP(ip net.IP, opts ...Option) (*Node, error) {
+	ip4 := ip.To4()
+	if ip4 == nil || !ip4.IsPrivate() {
+		return nil, errors.New("node IP " + ip.String() + " is not a private IPv4 address")
+	}
+
+	return NewNode(int64(binary.BigEndian.Uint32(ip4))&nodeMaskOf(opts), opts...)
+}
+
+// NewNodeFromInterface is like NewNodeFromIP but uses the first private IPv4
+// address of the named network interface, such as "eth0".
+func NewNodeFromInterface(name string, opts ...Option) (*Node, error) {
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, addr := range addrs {
+		if ipnet, ok := addr.(*net.IPNet); ok {
+			if ip4 := ipnet.IP.To4(); ip4 != nil && ip4.IsPrivate() {
+				return NewNodeFromIP(ip4, opts...)
+			}
+		}
+	}
+
+	return nil, errors.New("interface " + name + " has no private IPv4 address")
+}
@@ -0,0 +1,114 @@
+package snowflake
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewNodeByMachineID(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "machine-id")
+	if err := os.WriteFile(path, []byte("4c4c4544004d3510804cb4c04f4e5931\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	defer func(paths []string) { machineIDPaths = paths }(machineIDPaths)
+	machineIDPaths = []string{filepath.Join(dir, "missing"), path}
+
+	node, err := NewNodeByMachineID()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	hash := md5.Sum([]byte("4c4c4544004d3510804cb4c04f4e5931"))
+	if expected := int64(binary.BigEndian.Uint64(hash[:]) & nodeMax); node.node != expected {
+		t.Errorf("Got node %d, expected %d", node.node, expected)
+	}
+
+	again, _ := NewNodeByMachineID()
+	if again.node != node.node {
+		t.Errorf("Got node %d on the second call, expected %d", again.node, node.node)
+	}
+
+	small, err := NewNodeByMachineID(WithLayout(Layout{NodeBits: 4, StepBits: 18}))
+	if err != nil {
+		t.Fatalf("Unexpected error with a smaller layout: %v", err)
+	}
+	if expected := node.node & 15; small.node != expected {
+		t.Errorf("Got node %d with 4 node bits, expected %d", small.node, expected)
+	}
+}
+
+func TestNewNodeFromIP(t *testing.T) {
+	for _, tc := range []struct {
+		ip   string
+		node int64
+	}{
+		{"10.0.1.5", 261},
+		{"192.168.3.255", 1023},
+		{"172.16.4.0", 0},
+		{"::ffff:10.0.0.7", 7},
+	} {
+		node, err := NewNodeFromIP(net.ParseIP(tc.ip))
+		if err != nil {
+			t.Errorf("Unexpected error for %s: %v", tc.ip, err)
+			continue
+		}
+
+		if id := node.Generate(); id.Node() != tc.node {
+			t.Errorf("Got node %d for %s, expected %d", id.Node(), tc.ip, tc.node)
+		}
+	}
+
+	for _, ip := range []string{"8.8.8.8", "127.0.0.1", "fd00::1"} {
+		if _, err := NewNodeFromIP(net.ParseIP(ip)); err == nil {
+			t.Errorf("Expected an error for %s", ip)
+		}
+	}
+	if _, err := NewNodeFromIP(nil); err == nil {
+		t.Error("Expected an error for a nil IP")
+	}
+
+	ip := net.ParseIP("10.0.5.7")
+	for _, tc := range []struct {
+		opts []Option
+		node int64
+	}{
+		{[]Option{WithLayout(SonyflakeLayout)}, 0x0507},
+		{[]Option{WithLayout(Layout{NodeBits: 8, StepBits: 14})}, 7},
+		{[]Option{WithRegionBits(2)}, 7},
+		{[]Option{WithEpoch(Epoch + 1000)}, 0x107},
+	} {
+		node, err := NewNodeFromIP(ip, tc.opts...)
+		if err != nil {
+			t.Errorf("Unexpected error for %+v: %v", tc.opts, err)
+			continue
+		}
+		if node.node != tc.node {
+			t.Errorf("Got node %d with options %d, expected %d", node.node, len(tc.opts), tc.node)
+		}
+	}
+	if node, _ := NewNodeFromIP(ip, WithEpoch(Epoch+1000)); node.Epoch() != Epoch+1000 {
+		t.Errorf("Got epoch %d, expected the one from the options", node.Epoch())
+	}
+}
+
+func TestNewNodeFromInterface(t *testing.T) {
+	if _, err := NewNodeFromInterface("snowflake-missing0"); err == nil {
+		t.Error("Expected an error for a missing interface")
+	}
+
+	// The loopback interface only holds non-private addresses.
+	ifaces, _ := net.Interfaces()
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagLoopback != 0 {
+			if _, err := NewNodeFromInterface(iface.Name); err == nil {
+				t.Errorf("Expected an error for loopback interface %s", iface.Name)
+			}
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package snowflake
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// ErrInvalidMsgpack is returned by UnmarshalMsg for input that does not
+// start with a msgpack integer that fits an ID.
+var ErrInvalidMsgpack = errors.New("invalid msgpack snowflake ID")
+
+// MarshalMsg appends the snowflake ID to b as a msgpack integer, using the
+// smallest encoding that holds it, which is 9 bytes for IDs generated with
+// the default layout.  This implements msgp.Marshaler from
+// github.com/tinylib/msgp without this package depending on it.
+func (f ID) MarshalMsg(b []byte) ([]byte, error) {
+	v := int64(f)
+	switch {
+	case v >= 0 && v <= math.MaxInt8:
+		return append(b, byte(v)), nil
+	case v >= 0 && v <= math.MaxUint8:
+		return append(b, 0xcc, byte(v)), nil
+	case v >= 0 && v <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xcd), uint16(v)), nil
+	case v >= 0 && v <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, 0xce), uint32(v)), nil
+	case v >= 0:
+		return binary.BigEndian.AppendUint64(append(b, 0xcf), uint64(v)), nil
+	case v >= -32:
+		return append(b, byte(v)), nil
+	}
+	return binary.BigEndian.AppendUint64(append(b, 0xd3), uint64(v)), nil
+}
+
+// UnmarshalMsg reads a msgpack integer of any width from the start of b into
+// the ID and returns the remaining bytes.  A msgpack nil leaves the ID
+// unchanged.  This implements msgp.Unmarshaler.
+func (f *ID) UnmarshalMsg(b []byte) ([]byte, error) {
+	if len(b) == 0 {
+		return b, ErrInvalidMsgpack
+	}
+
+	c := b[0]
+	switch {
+	case c <= 0x7f || c >= 0xe0:
+		*f = ID(int8(c))
+		return b[1:], nil
+	case c == 0xc0:
+		return b[1:], nil
+	}
+
+	var size int
+	switch c {
+	case 0xcc, 0xd0:
+		size = 1
+	case 0xcd, 0xd1:
+		size = 2
+	case 0xce, 0xd2:
+		size = 4
+	case 0xcf, 0xd3:
+		size = 8
+	default:
+		return b, ErrInvalidMsgpack
+	}
+
+	if len(b) < 1+size {
+		return b, ErrInvalidMsgpack
+	}
+
+	var u uint64
+	for _, d := range b[1 : 1+size] {
+		u = u<<8 | uint64(d)
+	}
+
+	var v int64
+	if c <= 0xcf {
+		if u > math.MaxInt64 {
+			return b, ErrInvalidMsgpack
+		}
+		v = int64(u)
+	} else {
+		// Sign extend the signed forms from their width.
+		k := 64 - 8*uint(size)
+		v = int64(u<<k) >> k
+	}
+
+	*f = ID(v)
+	return b[1+size:], nil
+}
+
+// Msgsize returns the largest number of bytes MarshalMsg appends, for
+// msgp.Sizer.
+func (f ID) Msgsize() int {
+	return 9
+}
@@ -0,0 +1,59 @@
+package snowflake
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMsgpack(t *testing.T) {
+	for _, tc := range []struct {
+		id      ID
+		encoded []byte
+	}{
+		{0, []byte{0x00}},
+		{127, []byte{0x7f}},
+		{200, []byte{0xcc, 0xc8}},
+		{13587, []byte{0xcd, 0x35, 0x13}},
+		{1 << 20, []byte{0xce, 0x00, 0x10, 0x00, 0x00}},
+		{0x0102030405060708, []byte{0xcf, 1, 2, 3, 4, 5, 6, 7, 8}},
+		{-5, []byte{0xfb}},
+		{-1000, []byte{0xd3, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xfc, 0x18}},
+	} {
+		b, err := tc.id.MarshalMsg([]byte{0xaa})
+		if err != nil || !bytes.Equal(b, append([]byte{0xaa}, tc.encoded...)) {
+			t.Errorf("Got (%x, %v) marshaling %d, expected aa%x", b, err, tc.id, tc.encoded)
+		}
+		if len(tc.encoded) > tc.id.Msgsize() {
+			t.Errorf("Msgsize %d is below the %d bytes of %d", tc.id.Msgsize(), len(tc.encoded), tc.id)
+		}
+
+		var got ID
+		rest, err := got.UnmarshalMsg(append(tc.encoded, 0xbb))
+		if err != nil || got != tc.id || !bytes.Equal(rest, []byte{0xbb}) {
+			t.Errorf("Got (%d, %x, %v) unmarshaling %x, expected (%d, bb, nil)", got, rest, err, tc.encoded, tc.id)
+		}
+	}
+
+	// Other encoders may pick wider or signed forms.
+	for _, tc := range []struct {
+		encoded  []byte
+		expected ID
+	}{
+		{[]byte{0xd0, 0x05}, 5},
+		{[]byte{0xd1, 0xff, 0xfe}, -2},
+		{[]byte{0xd2, 0x00, 0x00, 0x35, 0x13}, 13587},
+		{[]byte{0xcf, 0, 0, 0, 0, 0, 0, 0, 1}, 1},
+		{[]byte{0xc0}, 42},
+	} {
+		got := ID(42)
+		if _, err := got.UnmarshalMsg(tc.encoded); err != nil || got != tc.expected {
+			t.Errorf("Got (%d, %v) unmarshaling %x, expected (%d, nil)", got, err, tc.encoded, tc.expected)
+		}
+	}
+
+	for _, bad := range [][]byte{nil, {0xcd, 0x01}, {0xcf, 0x80, 0, 0, 0, 0, 0, 0, 0}, {0xa1, 'x'}, {0xca, 0, 0, 0, 0}} {
+		if _, err := new(ID).UnmarshalMsg(bad); err != ErrInvalidMsgpack {
+			t.Errorf("Got %v unmarshaling %x, expected ErrInvalidMsgpack", err, bad)
+		}
+	}
+}
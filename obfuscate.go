@@ -0,0 +1,116 @@
+package snowflake
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// An Obfuscator maps snowflake IDs to non-sequential public IDs and back
+// with a secret key, so URLs and APIs do not reveal when an ID was created,
+// which node created it or how many IDs were created in between.  Every
+// non-negative ID maps to a distinct non-negative ID, so obfuscated IDs can
+// be stored and encoded like any other ID.
+//
+// Obfuscation uses the XTEA block cipher over the 63 bits of the ID.  It
+// hides ordering and rates from users without the key, but obfuscated IDs
+// are not authenticated, so possession of one must not grant access.
+type Obfuscator struct {
+	key [4]uint32
+}
+
+// ErrInvalidToken is returned by Deobfuscate and ParseToken for input that
+// no ID obfuscates to.
+var ErrInvalidToken = errors.New("invalid obfuscated snowflake ID")
+
+// NewObfuscator returns an Obfuscator using a 16 byte secret key.  The same
+// key must be used to reverse the mapping.
+func NewObfuscator(key []byte) (*Obfuscator, error) {
+	if len(key) != 16 {
+		return nil, errors.New("obfuscator key must be 16 bytes")
+	}
+
+	o := &Obfuscator{}
+	for i := range o.key {
+		o.key[i] = binary.BigEndian.Uint32(key[4*i:])
+	}
+	return o, nil
+}
+
+// Obfuscate returns the public ID for id, which must be non-negative.  It
+// panics for a negative ID.
+func (o *Obfuscator) Obfuscate(id ID) ID {
+	if id < 0 {
+		panic("snowflake: cannot obfuscate a negative ID")
+	}
+
+	// XTEA permutes all 64 bit values, so walk the cycle until the result is
+	// back in the 63 bit range of an ID.  Each step stays in range with a
+	// probability of one half.
+	v := uint64(id)
+	for {
+		v = o.encrypt(v)
+		if v < 1<<63 {
+			return ID(v)
+		}
+	}
+}
+
+// Deobfuscate returns the ID that Obfuscate mapped to public.
+func (o *Obfuscator) Deobfuscate(public ID) (ID, error) {
+	if public < 0 {
+		return 0, ErrInvalidToken
+	}
+
+	v := uint64(public)
+	for {
+		v = o.decrypt(v)
+		if v < 1<<63 {
+			return ID(v), nil
+		}
+	}
+}
+
+// Token returns the public ID for id as a base58 string, for use in URLs.
+func (o *Obfuscator) Token(id ID) string {
+	return o.Obfuscate(id).Base58()
+}
+
+// ParseToken returns the ID that Token encoded as s.
+func (o *Obfuscator) ParseToken(s string) (ID, error) {
+	public, err := ParseBase58(s)
+	if err != nil {
+		return 0, ErrInvalidToken
+	}
+	return o.Deobfuscate(public)
+}
+
+const (
+	xteaDelta = 0x9E3779B9
+	xteaSum   = 0xC6EF3720 // xteaDelta * 32 modulo 2^32
+)
+
+func (o *Obfuscator) encrypt(v uint64) uint64 {
+	v0, v1 := uint32(v>>32), uint32(v)
+
+	var sum uint32
+	for i := 0; i < 32; i++ {
+		v0 += (v1<<4 ^ v1>>5 + v1) ^ (sum + o.key[sum&3])
+		sum += xteaDelta
+		v1 += (v0<<4 ^ v0>>5 + v0) ^ (sum + o.key[sum>>11&3])
+	}
+
+	return uint64(v0)<<32 | uint64(v1)
+}
+
+func (o *Obfuscator) decrypt(v uint64) uint64 {
+	v0, v1 := uint32(v>>32), uint32(v)
+
+	sum := uint32(xteaSum)
+	for i := 0; i < 32; i++ {
+		v1 -= (v0<<4 ^ v0>>5 + v0) ^ (sum + o.key[sum>>11&3])
+		sum -= xteaDelta
+		v0 -= (v1<<4 ^ v1>>5 + v1) ^ (sum + o.key[sum&3])
+	}
+
+	return uint64(v0)<<32 | uint64(v1)
+}
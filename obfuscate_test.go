@@ -0,0 +1,78 @@
+package snowflake
+
+import (
+	"math/rand"
+	"testing"
+)
+
+var obfuscatorKey = []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+func TestXTEA(t *testing.T) {
+	o, _ := NewObfuscator(obfuscatorKey)
+
+	// A test vector of golang.org/x/crypto/xtea.
+	const plain, cipher = 0x4142434445464748, 0x497df3d072612cb5
+	if got := o.encrypt(plain); got != cipher {
+		t.Errorf("Got %x, expected %x", got, uint64(cipher))
+	}
+	if got := o.decrypt(cipher); got != plain {
+		t.Errorf("Got %x, expected %x", got, uint64(plain))
+	}
+}
+
+func TestObfuscator(t *testing.T) {
+	o, err := NewObfuscator(obfuscatorKey)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	node, _ := NewNode(1)
+	ids := []ID{0, 1, 1<<63 - 1}
+	for i := 0; i < 1000; i++ {
+		ids = append(ids, node.Generate(), ID(rand.Int63()))
+	}
+
+	seen := make(map[ID]bool)
+	ascending := 0
+	for i, id := range ids {
+		public := o.Obfuscate(id)
+		if public < 0 {
+			t.Fatalf("Got negative %d for %d", public, id)
+		}
+		if seen[public] && id != ids[i-1] {
+			t.Fatalf("Got %d for more than one ID", public)
+		}
+		seen[public] = true
+
+		if got, err := o.Deobfuscate(public); err != nil || got != id {
+			t.Fatalf("Got (%d, %v) deobfuscating %d, expected (%d, nil)", got, err, public, id)
+		}
+		if got, err := o.ParseToken(o.Token(id)); err != nil || got != id {
+			t.Fatalf("Got (%d, %v) parsing token %q, expected (%d, nil)", got, err, o.Token(id), id)
+		}
+
+		if i > 0 && public > o.Obfuscate(ids[i-1]) {
+			ascending++
+		}
+	}
+
+	// Sequential IDs should come out in no particular order.
+	if ascending < len(ids)/3 || ascending > 2*len(ids)/3 {
+		t.Errorf("Got %d of %d obfuscated IDs ascending, expected about half", ascending, len(ids))
+	}
+
+	other, _ := NewObfuscator(make([]byte, 16))
+	if other.Obfuscate(ids[3]) == o.Obfuscate(ids[3]) {
+		t.Error("Expected different keys to give different IDs")
+	}
+
+	if _, err := o.Deobfuscate(-1); err != ErrInvalidToken {
+		t.Errorf("Got %v, expected ErrInvalidToken", err)
+	}
+	if _, err := o.ParseToken("0OIl"); err != ErrInvalidToken {
+		t.Errorf("Got %v, expected ErrInvalidToken", err)
+	}
+	if _, err := NewObfuscator([]byte("short")); err == nil {
+		t.Error("Expected an error for a short key")
+	}
+}
@@ -0,0 +1,66 @@
+package snowflake
+
+import (
+	"math/bits"
+	"time"
+)
+
+// Hash returns a stable 64 bit hash of the snowflake ID, the splitmix64
+// finalizer of its value.  The low bits of an ID are mostly its step, which
+// is usually zero at low rates, so hashing is needed before reducing an ID
+// modulo a bucket count.  The hash does not change between processes or
+// versions of this package, so it can be used for persistent sharding.
+func (f ID) Hash() uint64 {
+	return mix64(uint64(f))
+}
+
+// mix64 is the splitmix64 finalizer.
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// partition maps a hash evenly onto [0, numPartitions).
+func partition(h uint64, numPartitions int) int {
+	if numPartitions <= 0 {
+		panic("snowflake: number of partitions must be positive")
+	}
+
+	hi, _ := bits.Mul64(h, uint64(numPartitions))
+	return int(hi)
+}
+
+// PartitionKey returns the partition, from 0 to numPartitions-1, of a message
+// keyed by the snowflake ID, such as a Kafka or NATS partition.  IDs are
+// spread evenly over the partitions regardless of node or rate, unlike
+// taking the ID modulo numPartitions.  It panics if numPartitions is not
+// positive.
+func (f ID) PartitionKey(numPartitions int) int {
+	return partition(f.Hash(), numPartitions)
+}
+
+// NodePartition is like PartitionKey but sends every ID generated by the same
+// node to the same partition, for consumers that need to see a node's IDs in
+// order.  The node is decoded using DefaultLayout; the partitions are only
+// as even as the spread of traffic across nodes.
+func (f ID) NodePartition(numPartitions int) int {
+	return partition(mix64(uint64(f.Node())), numPartitions)
+}
+
+// TimePartition is like PartitionKey but sends every ID generated within the
+// same window of time to the same partition, for consumers that batch by
+// time.  Windows start at the unix epoch and the time is decoded using
+// DefaultLayout and Epoch.  It panics if window is less than a millisecond
+// or numPartitions is not positive.
+func (f ID) TimePartition(numPartitions int, window time.Duration) int {
+	ms := window.Milliseconds()
+	if ms <= 0 {
+		panic("snowflake: partition window must be at least a millisecond")
+	}
+
+	return partition(mix64(uint64(f.Time()/ms)), numPartitions)
+}
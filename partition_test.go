@@ -0,0 +1,85 @@
+package snowflake
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHash(t *testing.T) {
+	// Hash must stay stable, as partitions are persisted by brokers.
+	for id, expected := range map[ID]uint64{0: 0, 1: 0x5692161d100b05e5, 1417819914141990912: 0xaa2cb666a8c9b52c} {
+		if got := id.Hash(); got != expected {
+			t.Errorf("Got %#x hashing %d, expected %#x", got, int64(id), expected)
+		}
+	}
+}
+
+func TestPartitionKey(t *testing.T) {
+	node, _ := NewNode(1)
+	counts := make([]int, 12)
+
+	// Only the low steps of a single millisecond and node, which all land on
+	// one partition with a plain modulo by a multiple of 4.
+	for _, id := range node.GenerateN(12000) {
+		p := id.PartitionKey(len(counts))
+		if p < 0 || p >= len(counts) {
+			t.Fatalf("Got partition %d, expected 0 to %d", p, len(counts)-1)
+		}
+		counts[p]++
+	}
+
+	for p, c := range counts {
+		if c < 800 || c > 1200 {
+			t.Errorf("Got %d IDs in partition %d, expected about 1000", c, p)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a panic for zero partitions")
+		}
+	}()
+	ID(1).PartitionKey(0)
+}
+
+func TestNodePartition(t *testing.T) {
+	a, _ := NewNode(3)
+	b, _ := NewNode(4)
+
+	ida, idb := a.Generate(), b.Generate()
+	for i := 0; i < 100; i++ {
+		if p := a.Generate().NodePartition(16); p != ida.NodePartition(16) {
+			t.Fatalf("Got partition %d, expected every ID of node 3 in %d", p, ida.NodePartition(16))
+		}
+	}
+
+	if ida.NodePartition(1) != 0 || idb.NodePartition(1) != 0 {
+		t.Error("Expected a single partition to hold every node")
+	}
+}
+
+func TestTimePartition(t *testing.T) {
+	start := time.Date(2024, 1, 2, 3, 4, 0, 0, time.UTC)
+	first := FirstIDForTime(start).TimePartition(64, time.Minute)
+
+	for _, d := range []time.Duration{time.Millisecond, 30 * time.Second, time.Minute - time.Millisecond} {
+		if p := LastIDForTime(start.Add(d)).TimePartition(64, time.Minute); p != first {
+			t.Errorf("Got partition %d at %s, expected %d", p, d, first)
+		}
+	}
+
+	seen := make(map[int]bool)
+	for i := 0; i < 64; i++ {
+		seen[FirstIDForTime(start.Add(time.Duration(i)*time.Minute)).TimePartition(64, time.Minute)] = true
+	}
+	if len(seen) < 32 {
+		t.Errorf("Got %d distinct partitions for 64 windows, expected them spread out", len(seen))
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a panic for a sub millisecond window")
+		}
+	}()
+	first = ID(1).TimePartition(4, time.Microsecond)
+}
@@ -0,0 +1,118 @@
+package snowflake
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// pgCopyHeader is the signature, flags and header extension length that
+// start a PostgreSQL binary COPY stream.
+var pgCopyHeader = []byte("PGCOPY\n\xff\r\n\x00\x00\x00\x00\x00\x00\x00\x00\x00")
+
+// pgCopyChunk is the number of tuples a PGCopyReader encodes at a time.
+const pgCopyChunk = 512
+
+// A PGCopyReader streams snowflake IDs in the PostgreSQL binary COPY format
+// for a single bigint column, so millions of IDs can be loaded with
+//
+//	COPY table (id) FROM STDIN (FORMAT binary)
+//
+// without encoding each value through database/sql or reflection.  With pgx
+// it is passed to conn.PgConn().CopyFrom.  It encodes the IDs in fixed size
+// chunks and never holds the whole stream in memory.
+type PGCopyReader struct {
+	ids    []ID
+	buf    []byte
+	off    int
+	header bool
+	done   bool
+}
+
+// NewPGCopyReader returns a PGCopyReader for ids.  ids must not be changed
+// until the reader has been read to io.EOF.
+func NewPGCopyReader(ids []ID) *PGCopyReader {
+	return &PGCopyReader{ids: ids, buf: make([]byte, 0, len(pgCopyHeader)+pgCopyChunk*14+2)}
+}
+
+// Read implements io.Reader.
+func (r *PGCopyReader) Read(p []byte) (int, error) {
+	if r.off == len(r.buf) {
+		if r.done {
+			return 0, io.EOF
+		}
+		r.fill()
+	}
+
+	n := copy(p, r.buf[r.off:])
+	r.off += n
+	return n, nil
+}
+
+// fill encodes the next chunk of tuples into buf, starting with the header
+// and ending with the trailer once every ID has been encoded.
+func (r *PGCopyReader) fill() {
+	r.buf, r.off = r.buf[:0], 0
+	if !r.header {
+		r.buf = append(r.buf, pgCopyHeader...)
+		r.header = true
+	}
+
+	n := min(len(r.ids), pgCopyChunk)
+	for _, id := range r.ids[:n] {
+		// One field of 8 bytes holding the big endian int8.
+		r.buf = append(r.buf, 0, 1, 0, 0, 0, 8)
+		r.buf = binary.BigEndian.AppendUint64(r.buf, uint64(id))
+	}
+	r.ids = r.ids[n:]
+
+	if len(r.ids) == 0 {
+		r.buf = append(r.buf, 0xff, 0xff)
+		r.done = true
+	}
+}
+
+// A PGCopyTextReader streams snowflake IDs in the PostgreSQL text COPY
+// format, one decimal ID per line, for loading a single varchar or text
+// column with
+//
+//	COPY table (id) FROM STDIN
+//
+// It also loads bigint columns, but PGCopyReader is cheaper for those, as
+// the server does not have to parse the IDs.  Like PGCopyReader it encodes
+// the IDs in fixed size chunks and never holds the whole stream in memory.
+type PGCopyTextReader struct {
+	ids []ID
+	buf []byte
+	off int
+}
+
+// NewPGCopyTextReader returns a PGCopyTextReader for ids.  ids must not be
+// changed until the reader has been read to io.EOF.
+func NewPGCopyTextReader(ids []ID) *PGCopyTextReader {
+	return &PGCopyTextReader{ids: ids, buf: make([]byte, 0, pgCopyChunk*21)}
+}
+
+// Read implements io.Reader.
+func (r *PGCopyTextReader) Read(p []byte) (int, error) {
+	if r.off == len(r.buf) {
+		if len(r.ids) == 0 {
+			return 0, io.EOF
+		}
+		r.fill()
+	}
+
+	n := copy(p, r.buf[r.off:])
+	r.off += n
+	return n, nil
+}
+
+// fill encodes the next chunk of lines into buf.
+func (r *PGCopyTextReader) fill() {
+	r.buf, r.off = r.buf[:0], 0
+
+	n := min(len(r.ids), pgCopyChunk)
+	for _, id := range r.ids[:n] {
+		r.buf = append(id.AppendString(r.buf), '\n')
+	}
+	r.ids = r.ids[n:]
+}
@@ -0,0 +1,97 @@
+package snowflake
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+)
+
+func TestPGCopyReader(t *testing.T) {
+	for _, count := range []int{0, 1, pgCopyChunk, pgCopyChunk*2 + 3} {
+		ids := make([]ID, count)
+		for i := range ids {
+			ids[i] = ID(int64(i)*1000003 - 7)
+		}
+
+		// Read in odd sizes to cross chunk boundaries.
+		var out bytes.Buffer
+		r := NewPGCopyReader(ids)
+		p := make([]byte, 13)
+		for {
+			n, err := r.Read(p)
+			out.Write(p[:n])
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error reading: %v", err)
+			}
+		}
+
+		b := out.Bytes()
+		if !bytes.HasPrefix(b, pgCopyHeader) {
+			t.Fatalf("Stream of %d IDs is missing the header", count)
+		}
+		b = b[len(pgCopyHeader):]
+
+		if want := count*14 + 2; len(b) != want {
+			t.Fatalf("Stream of %d IDs has %d bytes after the header, expected %d", count, len(b), want)
+		}
+		for i, id := range ids {
+			tuple := b[i*14 : i*14+14]
+			if !bytes.Equal(tuple[:6], []byte{0, 1, 0, 0, 0, 8}) {
+				t.Fatalf("Tuple %d has header %x", i, tuple[:6])
+			}
+			if got := ID(binary.BigEndian.Uint64(tuple[6:])); got != id {
+				t.Fatalf("Tuple %d holds %d, expected %d", i, got, id)
+			}
+		}
+		if trailer := b[count*14:]; !bytes.Equal(trailer, []byte{0xff, 0xff}) {
+			t.Errorf("Stream of %d IDs ends with %x, expected ffff", count, trailer)
+		}
+	}
+}
+
+func TestPGCopyTextReader(t *testing.T) {
+	for _, count := range []int{0, 1, pgCopyChunk, pgCopyChunk*2 + 3} {
+		ids := make([]ID, count)
+		var want bytes.Buffer
+		for i := range ids {
+			ids[i] = ID(int64(i)*1000003 - 7)
+			want.WriteString(ids[i].String() + "\n")
+		}
+
+		// Read in odd sizes to cross chunk boundaries.
+		var out bytes.Buffer
+		r := NewPGCopyTextReader(ids)
+		p := make([]byte, 13)
+		for {
+			n, err := r.Read(p)
+			out.Write(p[:n])
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error reading: %v", err)
+			}
+		}
+
+		if !bytes.Equal(out.Bytes(), want.Bytes()) {
+			t.Errorf("Got %q for %d IDs, expected %q", out.Bytes(), count, want.Bytes())
+		}
+	}
+}
+
+func BenchmarkPGCopyReader(b *testing.B) {
+	ids := make([]ID, 100000)
+	for i := range ids {
+		ids[i] = ID(i)
+	}
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(ids) * 14))
+	for i := 0; i < b.N; i++ {
+		io.Copy(io.Discard, NewPGCopyReader(ids))
+	}
+}
@@ -0,0 +1,129 @@
+package snowflake
+
+import (
+	"errors"
+	"runtime"
+	"strconv"
+	"sync/atomic"
+)
+
+// A DuplicatePolicy controls how NewPool handles a node number that appears
+// more than once.
+type DuplicatePolicy int
+
+const (
+	// ErrorOnDuplicate makes NewPool return an error for duplicate node
+	// numbers.  This is the default.
+	ErrorOnDuplicate DuplicatePolicy = iota
+
+	// DedupSilently makes NewPool ignore repeated node numbers.
+	DedupSilently
+)
+
+// PoolOptions holds optional settings for NewPool.  The zero value uses the
+// defaults.
+type PoolOptions struct {
+	// OnDuplicate controls how duplicate node numbers are handled, it
+	// defaults to ErrorOnDuplicate.
+	OnDuplicate DuplicatePolicy
+
+	// NodeOptions are passed to NewNode for each of the pool's nodes, such as
+	// WithEpoch or WithLayout.
+	NodeOptions []Option
+}
+
+// A Pool spreads ID generation across several nodes, sharing the load of
+// Generate calls between them.
+type Pool struct {
+	nodes []*Node
+	next  uint32
+}
+
+// NewPool returns a new Pool with a node for each of the given node numbers.
+// opts may be nil to use the defaults.
+func NewPool(nodes []int64, opts *PoolOptions) (*Pool, error) {
+	if opts == nil {
+		opts = &PoolOptions{}
+	}
+
+	p := &Pool{}
+	seen := make(map[int64]bool, len(nodes))
+	for _, id := range nodes {
+		if seen[id] {
+			if opts.OnDuplicate == DedupSilently {
+				continue
+			}
+			return nil, errors.New("duplicate node number " + strconv.FormatInt(id, 10))
+		}
+		seen[id] = true
+
+		n, err := NewNode(id, opts.NodeOptions...)
+		if err != nil {
+			return nil, err
+		}
+		p.nodes = append(p.nodes, n)
+	}
+
+	if len(p.nodes) == 0 {
+		return nil, errors.New("pool needs at least one node number")
+	}
+
+	return p, nil
+}
+
+// NewPoolRange returns a new Pool with count nodes numbered contiguously from
+// first, such as a range of node numbers leased to one process.  A count of 0
+// uses one node per runtime.GOMAXPROCS, so that on large machines every
+// running goroutine can usually find an idle node.
+func NewPoolRange(first int64, count int, opts *PoolOptions) (*Pool, error) {
+	if count < 0 {
+		return nil, errors.New("pool node count must not be negative")
+	}
+	if count == 0 {
+		count = runtime.GOMAXPROCS(0)
+	}
+
+	nodes := make([]int64, count)
+	for i := range nodes {
+		nodes[i] = first + int64(i)
+	}
+
+	return NewPool(nodes, opts)
+}
+
+// Generate creates and returns a unique snowflake ID from one of the pool's
+// nodes.  Calls start at successive nodes and skip nodes that are busy
+// generating for another goroutine, only waiting once every node is busy,
+// which keeps contention low when the pool has about as many nodes as
+// GOMAXPROCS.  Go does not expose which processor a goroutine runs on, so
+// this stands in for true per processor shards.
+//
+// Like Node.Generate it panics with ErrClosed once the nodes are closed.
+func (p *Pool) Generate() ID {
+	start := atomic.AddUint32(&p.next, 1)
+	size := uint32(len(p.nodes))
+
+	// Lock free nodes never block, so there is nothing to skip.
+	if p.nodes[0].lockFree {
+		return p.nodes[start%size].Generate()
+	}
+
+	for i := uint32(0); i < size; i++ {
+		if r, ok := p.nodes[(start+i)%size].tryNext(); ok {
+			return r
+		}
+	}
+
+	return p.nodes[start%size].Generate()
+}
+
+// Len returns the number of nodes in the pool.
+func (p *Pool) Len() int {
+	return len(p.nodes)
+}
+
+// Decode returns the fields of a snowflake ID generated by the pool, which
+// all of its nodes share the layout and epoch of.
+func (p *Pool) Decode(id ID) Parts {
+	return p.nodes[0].Decode(id)
+}
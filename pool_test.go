@@ -0,0 +1,185 @@
+package snowflake
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNewPoolDuplicates(t *testing.T) {
+	nodes := []int64{1, 2, 2, 3}
+
+	if _, err := NewPool(nodes, nil); err == nil {
+		t.Error("Expected an error for duplicate node numbers by default")
+	}
+
+	if _, err := NewPool(nodes, &PoolOptions{OnDuplicate: ErrorOnDuplicate}); err == nil {
+		t.Error("Expected an error for duplicate node numbers with ErrorOnDuplicate")
+	}
+
+	pool, err := NewPool(nodes, &PoolOptions{OnDuplicate: DedupSilently})
+	if err != nil {
+		t.Fatalf("Unexpected error with DedupSilently: %v", err)
+	}
+
+	if pool.Len() != 3 {
+		t.Errorf("Got %d nodes, expected 3", pool.Len())
+	}
+}
+
+func TestPoolGenerate(t *testing.T) {
+	pool, err := NewPool([]int64{1, 2, 3}, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error creating pool: %v", err)
+	}
+
+	seen := make(map[ID]bool)
+	nodes := make(map[int64]bool)
+	for i := 0; i < 300; i++ {
+		id := pool.Generate()
+		if seen[id] {
+			t.Fatalf("Duplicate ID %d", id)
+		}
+		seen[id] = true
+		nodes[id.Node()] = true
+	}
+
+	if len(nodes) != 3 {
+		t.Errorf("Got IDs from %d nodes, expected 3", len(nodes))
+	}
+}
+
+func TestNewPoolEmpty(t *testing.T) {
+	if _, err := NewPool(nil, nil); err == nil {
+		t.Error("Expected an error for an empty pool")
+	}
+}
+
+func TestNewPoolRange(t *testing.T) {
+	pool, err := NewPoolRange(100, 4, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error creating pool: %v", err)
+	}
+
+	const workers, perWorker = 8, 2000
+
+	var (
+		mu    sync.Mutex
+		wg    sync.WaitGroup
+		seen  = make(map[ID]bool, workers*perWorker)
+		nodes = make(map[int64]bool)
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			ids := make([]ID, perWorker)
+			for i := range ids {
+				ids[i] = pool.Generate()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			for _, id := range ids {
+				if seen[id] {
+					t.Errorf("Duplicate ID %d", id)
+				}
+				seen[id] = true
+				nodes[id.Node()] = true
+			}
+		}()
+	}
+	wg.Wait()
+
+	for node := range nodes {
+		if node < 100 || node > 103 {
+			t.Errorf("Got node %d, expected 100 to 103", node)
+		}
+	}
+
+	if pool, _ := NewPoolRange(0, 0, nil); pool.Len() != runtime.GOMAXPROCS(0) {
+		t.Errorf("Got %d nodes, expected GOMAXPROCS of %d", pool.Len(), runtime.GOMAXPROCS(0))
+	}
+
+	if _, err := NewPoolRange(1020, 8, nil); err == nil {
+		t.Error("Expected an error for a range past the node bits")
+	}
+	if _, err := NewPoolRange(0, -1, nil); err == nil {
+		t.Error("Expected an error for a negative count")
+	}
+}
+
+func BenchmarkPoolGenerateParallel(b *testing.B) {
+	// contentionLayout only has room for 4 nodes, so widen the step without
+	// narrowing the node.
+	l := Layout{NodeBits: 10, StepBits: 16}
+	pool, err := NewPoolRange(0, 0, &PoolOptions{NodeOptions: []Option{WithLayout(l)}})
+	if err != nil {
+		b.Fatalf("Unexpected error creating pool: %v", err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_ = pool.Generate()
+		}
+	})
+}
+
+func TestPoolStateStore(t *testing.T) {
+	var mu sync.Mutex
+	var hooked []ID
+	hook := WithHook(func(id ID) {
+		mu.Lock()
+		hooked = append(hooked, id)
+		mu.Unlock()
+	})
+
+	// Nodes sharing a store would wait out each other's windows, so the pool
+	// has a single node.
+	pool, err := NewPool([]int64{1}, &PoolOptions{NodeOptions: []Option{
+		WithStateStore(&memStore{}, time.Hour),
+		WithRateLimit(1000, 1),
+		hook,
+	}})
+	if err != nil {
+		t.Fatalf("Unexpected error creating pool: %v", err)
+	}
+
+	start := time.Now()
+	var ids []ID
+	for i := 0; i < 20; i++ {
+		ids = append(ids, pool.Generate())
+	}
+
+	if len(hooked) != len(ids) {
+		t.Errorf("Got %d hooked IDs, expected %d", len(hooked), len(ids))
+	}
+	// A node allowing 1000 IDs a second with bursts of one takes about 19ms
+	// for 20 IDs.
+	if d := time.Since(start); d < 10*time.Millisecond {
+		t.Errorf("Generated %d IDs in %s, expected the rate limit to slow them", len(ids), d)
+	}
+}
+
+func TestPoolClosed(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithStateStore(&memStore{}, time.Hour)}} {
+		pool, _ := NewPool([]int64{1}, &PoolOptions{NodeOptions: opts})
+		pool.Generate()
+		for _, n := range pool.nodes {
+			n.Close()
+		}
+
+		func() {
+			defer func() {
+				if r := recover(); r != ErrClosed {
+					t.Errorf("Got panic %v from a closed pool with options %v, expected ErrClosed", r, opts)
+				}
+			}()
+			pool.Generate()
+		}()
+	}
+}
@@ -0,0 +1,122 @@
+package snowflake
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+// TwitterLayout is the layout of Twitter snowflake IDs, with 41 time bits, 10
+// worker bits and 12 sequence bits.  It is the same as DefaultLayout.
+var TwitterLayout = Layout{NodeBits: 10, StepBits: 12}
+
+// TwitterEpoch is Twitter's snowflake epoch, 2010-11-04 01:42:54.657 UTC, in
+// milliseconds since the unix epoch.  It is the default value of Epoch.
+const TwitterEpoch int64 = 1288834974657
+
+// DiscordLayout is the layout of Discord snowflake IDs, with the 5 bit worker
+// and 5 bit process IDs combined into the node and a 12 bit increment as the
+// step.
+var DiscordLayout = Layout{NodeBits: 10, StepBits: 12}
+
+// DiscordEpoch is Discord's epoch, 2015-01-01 00:00:00 UTC, in milliseconds
+// since the unix epoch.
+const DiscordEpoch int64 = 1420070400000
+
+// InstagramLayout is the layout of Instagram IDs, with a 13 bit logical shard
+// ID as the node and a 10 bit sequence as the step.  Instagram uses all 64
+// bits, so IDs past 2045 set the sign bit and do not fit an ID.
+var InstagramLayout = Layout{NodeBits: 13, StepBits: 10}
+
+// InstagramEpoch is Instagram's epoch, 2011-08-24 21:07:01.721 UTC, in
+// milliseconds since the unix epoch.
+const InstagramEpoch int64 = 1314220021721
+
+// A Preset names a layout together with the epoch it is used with, so that
+// IDs from other systems can be decoded without changing the package Epoch.
+type Preset struct {
+	Name   string
+	Layout Layout
+	Epoch  int64
+}
+
+// Decode returns the fields of id according to the preset's layout and
+// epoch.
+func (p Preset) Decode(id ID) Parts {
+	return p.Layout.Decode(id, p.Epoch)
+}
+
+// WithPreset sets both the layout and the epoch of the node to those of p.
+func WithPreset(p Preset) Option {
+	return func(n *Node) error {
+		if err := p.Layout.Validate(); err != nil {
+			return err
+		}
+
+		n.layout = p.Layout
+		n.epoch = p.Epoch
+		return nil
+	}
+}
+
+// ErrUnknownPreset is returned by DecodePreset for a name that has not been
+// registered.
+var ErrUnknownPreset = errors.New("unknown preset")
+
+var presets = struct {
+	sync.RWMutex
+	m map[string]Preset
+}{m: map[string]Preset{
+	"twitter":   {"twitter", TwitterLayout, TwitterEpoch},
+	"discord":   {"discord", DiscordLayout, DiscordEpoch},
+	"instagram": {"instagram", InstagramLayout, InstagramEpoch},
+	"sonyflake": {"sonyflake", SonyflakeLayout, SonyflakeEpoch},
+}}
+
+// RegisterPreset adds p to the registry under p.Name, replacing any preset
+// already registered under that name.  The twitter, discord, instagram and
+// sonyflake presets are registered by default.  It is safe for concurrent
+// use.
+func RegisterPreset(p Preset) error {
+	if p.Name == "" {
+		return errors.New("preset name must not be empty")
+	}
+	if err := p.Layout.Validate(); err != nil {
+		return err
+	}
+
+	presets.Lock()
+	presets.m[p.Name] = p
+	presets.Unlock()
+	return nil
+}
+
+// LookupPreset returns the preset registered under name.
+func LookupPreset(name string) (Preset, bool) {
+	presets.RLock()
+	p, ok := presets.m[name]
+	presets.RUnlock()
+	return p, ok
+}
+
+// PresetNames returns the names of all registered presets in sorted order.
+func PresetNames() []string {
+	presets.RLock()
+	names := make([]string, 0, len(presets.m))
+	for name := range presets.m {
+		names = append(names, name)
+	}
+	presets.RUnlock()
+
+	sort.Strings(names)
+	return names
+}
+
+// DecodePreset decodes id according to the preset registered under name.
+func DecodePreset(name string, id ID) (Parts, error) {
+	p, ok := LookupPreset(name)
+	if !ok {
+		return Parts{}, ErrUnknownPreset
+	}
+	return p.Decode(id), nil
+}
@@ -0,0 +1,69 @@
+package snowflake
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDiscordPreset(t *testing.T) {
+	// The example ID from Discord's API reference.
+	p, err := DecodePreset("discord", 175928847299117063)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	created := time.Date(2016, 4, 30, 11, 18, 25, 796000000, time.UTC)
+	if !p.Time.Equal(created) || p.Node != 1<<5|0 || p.Step != 7 {
+		t.Errorf("Got %+v, expected time %s, worker 1, process 0 and increment 7", p, created)
+	}
+
+	if _, err := DecodePreset("nope", 1); err != ErrUnknownPreset {
+		t.Errorf("Got %v, expected ErrUnknownPreset", err)
+	}
+}
+
+func TestWithPreset(t *testing.T) {
+	preset, _ := LookupPreset("instagram")
+
+	node, err := NewNode(5000, WithPreset(preset))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if node.Layout() != InstagramLayout || node.Epoch() != InstagramEpoch {
+		t.Errorf("Got layout %+v and epoch %d, expected %+v and %d", node.Layout(), node.Epoch(), InstagramLayout, InstagramEpoch)
+	}
+
+	id := node.Generate()
+	if p := preset.Decode(id); p.Node != 5000 || time.Since(p.Time) > time.Second {
+		t.Errorf("Got %+v, expected node 5000 and a time close to now", p)
+	}
+
+	if Epoch != TwitterEpoch || DefaultLayout != TwitterLayout {
+		t.Error("Expected the defaults to match the twitter preset")
+	}
+}
+
+func TestRegisterPreset(t *testing.T) {
+	custom := Preset{Name: "custom", Layout: Layout{NodeBits: 4, StepBits: 8}, Epoch: 1600000000000}
+	if err := RegisterPreset(custom); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if p, ok := LookupPreset("custom"); !ok || p != custom {
+		t.Errorf("Got (%+v, %t), expected (%+v, true)", p, ok, custom)
+	}
+
+	expected := []string{"custom", "discord", "instagram", "sonyflake", "twitter"}
+	if names := PresetNames(); !reflect.DeepEqual(names, expected) {
+		t.Errorf("Got %v, expected %v", names, expected)
+	}
+
+	if err := RegisterPreset(Preset{Layout: DefaultLayout}); err == nil {
+		t.Error("Expected an error for an unnamed preset")
+	}
+	if err := RegisterPreset(Preset{Name: "bad", Layout: Layout{NodeBits: 40, StepBits: 23}}); err == nil {
+		t.Error("Expected an error for an invalid layout")
+	}
+}
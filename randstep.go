@@ -0,0 +1,28 @@
+package snowflake
+
+import "math/rand"
+
+// WithRandomStepStart makes the node start the step of each new time unit at
+// a random value below 2^bits instead of 0, so consecutive IDs are harder to
+// enumerate from a single known one.  Steps still increase from there, so
+// IDs stay unique and ordered within the node, but a time unit can hold up to
+// 2^bits-1 fewer IDs.  For example with the default layout, 8 bits leave at
+// least 3841 of 4096 steps a millisecond.
+//
+// The offsets come from math/rand and only make guessing harder; they are
+// not a substitute for authorization checks.  bits must not exceed the
+// layout's step bits.
+func WithRandomStepStart(bits uint8) Option {
+	return func(n *Node) error {
+		n.randomBits = bits
+		return nil
+	}
+}
+
+// firstStep returns the step the node starts each time unit at.
+func (n *Node) firstStep() int64 {
+	if n.randomBits == 0 {
+		return 0
+	}
+	return rand.Int63n(1 << n.randomBits)
+}
@@ -0,0 +1,46 @@
+package snowflake
+
+import "testing"
+
+func TestWithRandomStepStart(t *testing.T) {
+	for _, opts := range [][]Option{{withMutex()}, nil} {
+		now := nowMillis()
+		clock := WithClock(ClockFunc(func() int64 { return now }))
+
+		node, err := NewNode(1, append(opts, clock, WithRandomStepStart(8))...)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		starts := make(map[int64]bool)
+		var last ID
+		for i := 0; i < 200; i++ {
+			now++
+			for j := 0; j < 4; j++ {
+				id := node.Generate()
+				if id <= last {
+					t.Fatalf("ID %d is not greater than %d", id, last)
+				}
+				last = id
+
+				if j == 0 {
+					if id.Step() >= 1<<8 {
+						t.Fatalf("Got a first step of %d, expected below 256", id.Step())
+					}
+					starts[id.Step()] = true
+				}
+			}
+		}
+
+		if len(starts) < 50 {
+			t.Errorf("Got %d distinct first steps out of 200, expected them to vary", len(starts))
+		}
+	}
+
+	if _, err := NewNode(1, WithRandomStepStart(13)); err == nil {
+		t.Error("Expected an error for more random bits than step bits")
+	}
+	if _, err := NewNode(1, WithRandomStepStart(13), WithLayout(Layout{NodeBits: 5, StepBits: 17})); err != nil {
+		t.Errorf("Unexpected error with a wider step: %v", err)
+	}
+}
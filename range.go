@@ -0,0 +1,147 @@
+package snowflake
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"time"
+)
+
+// FirstIDForTime returns the smallest snowflake ID that can be generated
+// during the millisecond containing t, using DefaultLayout and Epoch.  With
+// LastIDForTime it turns a time range into an ID range for database queries:
+// the IDs created between t1 and t2 inclusive are those from
+// FirstIDForTime(t1) to LastIDForTime(t2).
+func FirstIDForTime(t time.Time) ID {
+	return ID((t.UnixNano()/1000000 - Epoch) << timeShift)
+}
+
+// LastIDForTime returns the largest snowflake ID that can be generated during
+// the millisecond containing t, using DefaultLayout and Epoch.
+func LastIDForTime(t time.Time) ID {
+	return FirstIDForTime(t) | ID(-1^(-1<<timeShift))
+}
+
+// HexPrefixForSecond returns the leading hex digits shared by every ID that
+// can be generated during the second containing t, when IDs are written as
+// fixed width 16 digit lowercase hex (fmt.Sprintf("%016x", id)).  The result
+// can be used directly as a prefix when range scanning a KV store keyed that
+// way.
+//
+// A hex prefix always covers a power of 16 range of IDs, so the scan may also
+// return IDs from neighbouring seconds and callers should still filter on
+// ID.Time.  A second is the finest granularity this is useful for; the IDs of
+// a shorter window often share no more digits than the second does.
+func HexPrefixForSecond(t time.Time) string {
+	start := t.Truncate(time.Second)
+	min := fmt.Sprintf("%016x", FirstIDForTime(start))
+	max := fmt.Sprintf("%016x", LastIDForTime(start.Add(time.Second-time.Millisecond)))
+
+	i := 0
+	for i < len(min) && min[i] == max[i] {
+		i++
+	}
+
+	return min[:i]
+}
+
+// GenerateInBucket returns count unique IDs for this node whose times fall
+// within [start, end), spread evenly across the range.  It is meant for
+// backfilling records into time partitioned stores.
+//
+// The bucket holds at most 2^StepBits IDs per node for every whole time unit
+// of the layout in the range, 4096 per millisecond with the default layout,
+// and an error is returned if count exceeds that.  The IDs are not
+// coordinated with Generate, so a node that is also generating live IDs for
+// the same range may produce duplicates; use a dedicated node number for
+// backfills.  ErrTimestampOverflow is returned if the range reaches past the
+// end of the layout's time field.
+func (n *Node) GenerateInBucket(start, end time.Time, count int) ([]ID, error) {
+	if err := n.open(); err != nil {
+		return nil, err
+	}
+
+	epoch := n.epoch * int64(time.Millisecond)
+	startNs, endNs := start.UnixNano(), end.UnixNano()
+
+	if startNs < epoch {
+		return nil, errors.New("bucket starts before the epoch")
+	}
+
+	first := (startNs - epoch + n.unit - 1) / n.unit
+	span := (endNs-epoch+n.unit-1)/n.unit - first
+	if span <= 0 {
+		return nil, errors.New("bucket must span at least one time unit")
+	}
+
+	if !n.layout.timeFits(first + span - 1) {
+		return nil, ErrTimestampOverflow
+	}
+
+	if count < 0 || uint64(count) > uint64(span)*uint64(n.stepMask+1) {
+		return nil, fmt.Errorf("count %d exceeds the bucket capacity of %d", count, uint64(span)*uint64(n.stepMask+1))
+	}
+
+	ids := make([]ID, count)
+	prev, step := int64(-1), int64(0)
+	for i := range ids {
+		hi, lo := bits.Mul64(uint64(i), uint64(span))
+		q, _ := bits.Div64(hi, lo, uint64(count))
+
+		t := first + int64(q)
+		if t == prev {
+			step++
+		} else {
+			prev, step = t, 0
+		}
+
+		ids[i] = n.compose(t, n.node, step)
+	}
+
+	return ids, nil
+}
+
+// LifetimeAtRate returns how long the node can keep generating perSecond IDs a
+// second before its time field overflows.  An error is returned if perSecond
+// is negative, or above the node's ceiling of 2^StepBits IDs per time unit,
+// 4,096,000 a second with the default layout, unless the node uses
+// BorrowOnExhausted.
+//
+// Below the ceiling the node waits for the clock when the step is exhausted,
+// or borrows only for the length of a burst, so the rate never causes drift
+// and the lifetime is the time left until the time field runs out.  Above the
+// ceiling a node using BorrowOnExhausted runs ahead of the clock by the share
+// of the rate it cannot fit, using up its time field perSecond/ceiling times
+// faster than the clock does, and the lifetime shrinks by the same factor.
+// This assumes that the system clock is correct and that the node is not
+// already ahead of it.
+func (n *Node) LifetimeAtRate(perSecond int64) (time.Duration, error) {
+	ceiling := n.rateCeiling()
+	if perSecond < 0 || perSecond > ceiling && n.exhaustion != BorrowOnExhausted {
+		return 0, fmt.Errorf("rate %d is outside the per node range of 0 to %d IDs a second", perSecond, ceiling)
+	}
+
+	remaining := n.Remaining()
+	if perSecond <= ceiling {
+		return remaining, nil
+	}
+	return time.Duration(float64(remaining) * float64(ceiling) / float64(perSecond)), nil
+}
+
+// rateCeiling returns the most IDs a second the node can generate without
+// running ahead of the clock, 2^StepBits per time unit.  It is counted in 128
+// bits, as fine time units with many step bits overflow an int64, and capped
+// at math.MaxInt64.
+func (n *Node) rateCeiling() int64 {
+	hi, lo := bits.Mul64(uint64(n.stepMask+1), uint64(time.Second))
+	if hi >= uint64(n.unit) {
+		return math.MaxInt64
+	}
+
+	q, _ := bits.Div64(hi, lo, uint64(n.unit))
+	if q > math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return int64(q)
+}
@@ -0,0 +1,181 @@
+package snowflake
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHexPrefixForSecond(t *testing.T) {
+	node, _ := NewNode(1)
+
+	id := node.Generate()
+	sec := time.Unix(0, id.Time()*int64(time.Millisecond))
+	prefix := HexPrefixForSecond(sec)
+
+	if prefix == "" {
+		t.Fatal("Expected a non-empty prefix")
+	}
+
+	for i := 0; i < 1000; i++ {
+		id := node.Generate()
+		if time.Unix(0, id.Time()*int64(time.Millisecond)).Truncate(time.Second) != sec.Truncate(time.Second) {
+			break
+		}
+
+		if hex := fmt.Sprintf("%016x", id); !strings.HasPrefix(hex, prefix) {
+			t.Fatalf("ID %s does not have prefix %s", hex, prefix)
+		}
+	}
+
+	start := sec.Truncate(time.Second)
+	for _, b := range []ID{FirstIDForTime(start), LastIDForTime(start.Add(999 * time.Millisecond))} {
+		if hex := fmt.Sprintf("%016x", b); !strings.HasPrefix(hex, prefix) {
+			t.Errorf("Boundary ID %s does not have prefix %s", hex, prefix)
+		}
+	}
+}
+
+func TestFirstLastIDForTime(t *testing.T) {
+	node, _ := NewNode(1023)
+
+	start := time.Now()
+	var ids []ID
+	for i := 0; i < 100; i++ {
+		ids = append(ids, node.Generate())
+	}
+	end := time.Now()
+
+	first, last := FirstIDForTime(start), LastIDForTime(end)
+	for _, id := range ids {
+		if id < first || id > last {
+			t.Fatalf("ID %d is outside [%d, %d]", id, first, last)
+		}
+	}
+
+	ms := time.UnixMilli(ids[0].Time())
+	if first := FirstIDForTime(ms); first.TimeStd() != ms || first.Node() != 0 || first.Step() != 0 {
+		t.Errorf("Got first ID %s, expected time %s with node and step 0", first.DebugString(), ms)
+	}
+	if last := LastIDForTime(ms); last.TimeStd() != ms || last.Node() != 1023 || last.Step() != 4095 {
+		t.Errorf("Got last ID %s, expected time %s with node 1023 and step 4095", last.DebugString(), ms)
+	}
+	if LastIDForTime(ms)+1 != FirstIDForTime(ms.Add(time.Millisecond)) {
+		t.Error("Expected consecutive milliseconds to have adjacent ID ranges")
+	}
+}
+
+func TestGenerateInBucket(t *testing.T) {
+	node, _ := NewNode(3)
+
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(10 * time.Millisecond)
+
+	ids, err := node.GenerateInBucket(start, end, 10*4096)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	seen := make(map[ID]bool)
+	for _, id := range ids {
+		if seen[id] {
+			t.Fatalf("Duplicate ID %d", id)
+		}
+		seen[id] = true
+
+		ms := time.Unix(0, id.Time()*int64(time.Millisecond))
+		if ms.Before(start) || !ms.Before(end) {
+			t.Fatalf("ID time %s is outside the bucket", ms)
+		}
+
+		if id.Node() != 3 {
+			t.Fatalf("Got node %d, expected 3", id.Node())
+		}
+	}
+
+	ids, _ = node.GenerateInBucket(start, end, 10)
+	for i, id := range ids {
+		if expected := start.UnixNano()/1000000 + int64(i); id.Time() != expected {
+			t.Errorf("Got time %d for ID %d, expected %d", id.Time(), i, expected)
+		}
+	}
+
+	if _, err := node.GenerateInBucket(start, end, 10*4096+1); err == nil {
+		t.Error("Expected an error when exceeding the bucket capacity")
+	}
+
+	if _, err := node.GenerateInBucket(end, start, 1); err == nil {
+		t.Error("Expected an error for an empty bucket")
+	}
+
+	limit := time.UnixMilli(Epoch + 1<<41)
+	if _, err := node.GenerateInBucket(limit.Add(-time.Millisecond), limit.Add(time.Millisecond), 1); err != ErrTimestampOverflow {
+		t.Errorf("Got %v for a bucket past the time field, expected ErrTimestampOverflow", err)
+	}
+	if _, err := node.GenerateInBucket(limit.Add(-time.Millisecond), limit, 1); err != nil {
+		t.Errorf("Unexpected error for a bucket ending with the time field: %v", err)
+	}
+
+	interleaved, _ := NewNode(3, WithBitInterleave())
+	ids, _ = interleaved.GenerateInBucket(start, end, 10)
+	for i, id := range ids {
+		if got := interleaved.IDTime(id); got != start.UnixNano()/1000000+int64(i) {
+			t.Errorf("Got time %d for interleaved ID %d, expected %d", got, i, start.UnixNano()/1000000+int64(i))
+		}
+		if got := interleaved.IDNode(id); got != 3 {
+			t.Errorf("Got node %d for interleaved ID %d, expected 3", got, i)
+		}
+	}
+}
+
+func TestLifetimeAtRate(t *testing.T) {
+	node, _ := NewNode(1)
+
+	d, err := node.LifetimeAtRate(1000)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	end := time.Now().Add(d)
+	expected := time.UnixMilli(Epoch + 1<<41)
+	if diff := end.Sub(expected); diff < -time.Second || diff > time.Second {
+		t.Errorf("Got lifetime ending %s, expected %s", end, expected)
+	}
+
+	if _, err := node.LifetimeAtRate(4096000); err != nil {
+		t.Errorf("Unexpected error at the ceiling: %v", err)
+	}
+
+	if _, err := node.LifetimeAtRate(4096001); err == nil {
+		t.Error("Expected an error above the ceiling")
+	}
+
+	if _, err := node.LifetimeAtRate(-1); err == nil {
+		t.Error("Expected an error for a negative rate")
+	}
+
+	borrowing, _ := NewNode(1, WithExhaustionPolicy(BorrowOnExhausted))
+	if d, err := borrowing.LifetimeAtRate(4096000); err != nil || !near(d, borrowing.Remaining()) {
+		t.Errorf("Got %s, %v borrowing at the ceiling, expected the remaining %s", d, err, borrowing.Remaining())
+	}
+	if d, err := borrowing.LifetimeAtRate(4 * 4096000); err != nil || !near(d, borrowing.Remaining()/4) {
+		t.Errorf("Got %s, %v borrowing at four times the ceiling, expected %s", d, err, borrowing.Remaining()/4)
+	}
+
+	// 2^40 steps a nanosecond overflow an int64 of IDs a second.
+	fine, err := NewNode(0, WithLayout(Layout{StepBits: 40, TimeUnit: time.Nanosecond}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := fine.LifetimeAtRate(math.MaxInt64); err != nil {
+		t.Errorf("Unexpected error below an overflowing ceiling: %v", err)
+	}
+}
+
+// near reports whether the durations a and b are within a second of each
+// other.
+func near(a, b time.Duration) bool {
+	return a-b < time.Second && b-a < time.Second
+}
@@ -0,0 +1,127 @@
+package snowflake
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited is returned by GenerateSafe when the node's rate limit has
+// no IDs left to hand out.
+var ErrRateLimited = errors.New("ID generation rate limit exceeded")
+
+// WithRateLimit caps the rate at which the node hands out IDs to perSecond,
+// with bursts of up to burst IDs, so that a runaway caller cannot burn
+// through the node's sequence space or downstream quotas.  Generate,
+// GenerateUnlocked, GenerateN, GenerateRegion, Reserve and TenantGen.Generate
+// sleep until the limit allows their IDs, GenerateCtx waits unless ctx is
+// done first, and GenerateSafe returns ErrRateLimited instead of waiting.
+// WithLock, ReserveBlock, GenerateInBucket and GenerateAt are not limited.
+//
+// The limit is a token bucket measured against the node's clock.
+func WithRateLimit(perSecond float64, burst int) Option {
+	return func(n *Node) error {
+		if !(perSecond > 0) {
+			return errors.New("rate limit must be positive")
+		}
+		if burst < 1 {
+			return errors.New("rate limit burst must be at least 1")
+		}
+
+		n.limiter = &rateLimiter{
+			rate:   perSecond / float64(time.Second),
+			burst:  float64(burst),
+			tokens: float64(burst),
+		}
+		return nil
+	}
+}
+
+// A rateLimiter is a token bucket.  Reservations may take it below zero, in
+// which case the caller waits for the debt to be repaid.
+type rateLimiter struct {
+	sync.Mutex
+	rate   float64 // tokens per nanosecond
+	burst  float64
+	tokens float64
+	last   int64 // unix time of the last refill in nanoseconds
+}
+
+// refill adds the tokens accrued up to now.  It must be called with the
+// limiter locked.
+func (l *rateLimiter) refill(now int64) {
+	if now > l.last {
+		l.tokens = min(l.burst, l.tokens+float64(now-l.last)*l.rate)
+		l.last = now
+	}
+}
+
+// reserve takes count tokens and returns how long the caller must wait
+// before using them.
+func (l *rateLimiter) reserve(now int64, count int) time.Duration {
+	l.Lock()
+	defer l.Unlock()
+
+	l.refill(now)
+	l.tokens -= float64(count)
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate)
+}
+
+// allow takes count tokens if they are available without waiting.
+func (l *rateLimiter) allow(now int64, count int) bool {
+	l.Lock()
+	defer l.Unlock()
+
+	l.refill(now)
+	if l.tokens < float64(count) {
+		return false
+	}
+	l.tokens -= float64(count)
+	return true
+}
+
+// cancel returns the tokens of an abandoned reservation.
+func (l *rateLimiter) cancel(count int) {
+	l.Lock()
+	l.tokens = min(l.burst, l.tokens+float64(count))
+	l.Unlock()
+}
+
+// throttle waits until the node's rate limit allows count more IDs.
+func (n *Node) throttle(count int) {
+	if n.limiter == nil {
+		return
+	}
+
+	if d := n.limiter.reserve(n.now(), count); d > 0 {
+		time.Sleep(d)
+	}
+}
+
+// throttleCtx is like throttle but returns ctx.Err(), and gives the IDs back
+// to the limit, if ctx is done before they are allowed.
+func (n *Node) throttleCtx(ctx context.Context, count int) error {
+	if n.limiter == nil {
+		return nil
+	}
+
+	d := n.limiter.reserve(n.now(), count)
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		n.limiter.cancel(count)
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,64 @@
+package snowflake
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWithRateLimit(t *testing.T) {
+	node, err := NewNode(1, WithRateLimit(1000, 5))
+	if err != nil {
+		t.Fatalf("error creating NewNode, %s", err)
+	}
+
+	for i := 0; i < 5; i++ {
+		if _, err := node.GenerateSafe(); err != nil {
+			t.Fatalf("Got %v for ID %d of the burst, expected nil", err, i)
+		}
+	}
+	if _, err := node.GenerateSafe(); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("Got %v after the burst, expected ErrRateLimited", err)
+	}
+
+	// 20 more IDs at 1000 a second take about 20ms.
+	start := time.Now()
+	node.GenerateN(10)
+	for i := 0; i < 10; i++ {
+		node.Generate()
+	}
+	if d := time.Since(start); d < 15*time.Millisecond {
+		t.Errorf("Got 20 IDs in %s, expected the limit to slow them to about 20ms", d)
+	}
+
+	for _, opt := range []Option{WithRateLimit(0, 1), WithRateLimit(10, 0)} {
+		if _, err := NewNode(1, opt); err == nil {
+			t.Error("Expected an error for an invalid rate limit")
+		}
+	}
+}
+
+func TestWithRateLimitCtx(t *testing.T) {
+	node, _ := NewNode(1, WithRateLimit(1, 1))
+	if _, err := node.GenerateCtx(context.Background()); err != nil {
+		t.Fatalf("Got %v for the first ID, expected nil", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := node.GenerateCtx(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Got %v, expected context.DeadlineExceeded", err)
+	}
+	if d := time.Since(start); d > 500*time.Millisecond {
+		t.Errorf("Got GenerateCtx returning after %s, expected it to stop at the deadline", d)
+	}
+
+	// The abandoned reservation is given back, so the limit is not pushed
+	// further into the future.
+	if d := node.limiter.reserve(node.now(), 0); d > time.Second {
+		t.Errorf("Got a wait of %s, expected at most a second", d)
+	}
+}
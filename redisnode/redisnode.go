@@ -0,0 +1,216 @@
+// Package redisnode leases unique snowflake node numbers from Redis, so that
+// autoscaled processes sharing a Redis server never run with the same node.
+//
+// A lease is a key holding a random token with a TTL.  It is renewed in the
+// background and released on Close.  The package talks to Redis through the
+// small Client interface rather than a specific client library.
+package redisnode
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+)
+
+// Client is the subset of Redis commands used to lease node numbers.  It is
+// a thin adapter over a client library; with go-redis for example, SetNX is
+// c.SetNX(ctx, key, value, ttl).Result() and Eval is
+// c.Eval(ctx, script, keys, args...).Result().
+type Client interface {
+	// SetNX sets key to value with the given TTL if the key does not exist
+	// and reports whether it was set.
+	SetNX(ctx context.Context, key, value string, ttl time.Duration) (bool, error)
+
+	// Eval runs the Lua script with the given keys and arguments and
+	// returns its result.
+	Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error)
+}
+
+// ErrNoFreeNode is returned by Acquire when every node number is leased.
+var ErrNoFreeNode = errors.New("redisnode: no free node number")
+
+// ErrLeaseLost is sent on Lease.Lost when the lease key expired or was taken
+// over by another owner.
+var ErrLeaseLost = errors.New("redisnode: lease lost")
+
+// renewScript extends the lease key's TTL only while it still holds our
+// token, and releaseScript deletes it only while it still holds our token.
+const (
+	renewScript   = `if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("pexpire", KEYS[1], ARGV[2]) else return 0 end`
+	releaseScript = `if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("del", KEYS[1]) else return 0 end`
+)
+
+// Config configures how node numbers are leased.  The zero value uses the
+// defaults.
+type Config struct {
+	// Prefix is prepended to the node number to form the lease key.  It
+	// defaults to "snowflake:node:".
+	Prefix string
+
+	// TTL is how long a lease lasts without renewal.  It is renewed every
+	// TTL/3 and defaults to 30 seconds.
+	TTL time.Duration
+
+	// Nodes is the number of node numbers to lease from, starting at 0.  It
+	// defaults to 1024, the node range of snowflake.DefaultLayout.
+	Nodes int64
+}
+
+// A Lease holds a leased node number and the Node generating with it.
+type Lease struct {
+	node  *snowflake.Node
+	id    int64
+	key   string
+	token string
+	ttl   time.Duration
+	c     Client
+
+	lost chan error
+	stop chan struct{}
+	done chan struct{}
+
+	releaseOnce sync.Once
+	releaseErr  error
+}
+
+// Acquire leases a free node number and returns a Lease with a Node created
+// from it and opts.  The lease is renewed in the background until Close is
+// called, the Node is closed or the lease is lost.
+func Acquire(ctx context.Context, c Client, cfg Config, opts ...snowflake.Option) (*Lease, error) {
+	if cfg.Prefix == "" {
+		cfg.Prefix = "snowflake:node:"
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = 30 * time.Second
+	}
+	if cfg.Nodes <= 0 {
+		cfg.Nodes = 1024
+	}
+
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return nil, err
+	}
+	token := hex.EncodeToString(b[:])
+
+	// Start scanning at a random node so processes starting together do
+	// not all race for the same keys.
+	start := int64(binary.BigEndian.Uint64(b[:8]) % uint64(cfg.Nodes))
+
+	for i := int64(0); i < cfg.Nodes; i++ {
+		id := (start + i) % cfg.Nodes
+		key := cfg.Prefix + strconv.FormatInt(id, 10)
+
+		ok, err := c.SetNX(ctx, key, token, cfg.TTL)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+
+		var l *Lease
+		closer := snowflake.WithCloser(func() error {
+			return l.release(context.Background())
+		})
+
+		node, err := snowflake.NewNode(id, append(opts[:len(opts):len(opts)], closer)...)
+		if err != nil {
+			c.Eval(ctx, releaseScript, []string{key}, token)
+			return nil, err
+		}
+
+		l = &Lease{
+			node:  node,
+			id:    id,
+			key:   key,
+			token: token,
+			ttl:   cfg.TTL,
+			c:     c,
+			lost:  make(chan error, 1),
+			stop:  make(chan struct{}),
+			done:  make(chan struct{}),
+		}
+		go l.renew()
+
+		return l, nil
+	}
+
+	return nil, ErrNoFreeNode
+}
+
+// Node returns the Node generating IDs with the leased node number.
+func (l *Lease) Node() *snowflake.Node {
+	return l.node
+}
+
+// ID returns the leased node number.
+func (l *Lease) ID() int64 {
+	return l.id
+}
+
+// Lost returns a channel that receives an error if the lease is lost, after
+// which the node number may be handed to another process and the Node must
+// stop generating IDs.  The channel is closed when renewal stops.
+func (l *Lease) Lost() <-chan error {
+	return l.lost
+}
+
+// Close stops renewing the lease, releases the node number and closes the
+// Node.  Closing the Node alone also releases the lease, using a background
+// context.
+func (l *Lease) Close(ctx context.Context) error {
+	err := l.release(ctx)
+	l.node.Close()
+	return err
+}
+
+// release stops renewing the lease and releases the node number, once.
+func (l *Lease) release(ctx context.Context) error {
+	l.releaseOnce.Do(func() {
+		close(l.stop)
+		<-l.done
+
+		_, l.releaseErr = l.c.Eval(ctx, releaseScript, []string{l.key}, l.token)
+	})
+	return l.releaseErr
+}
+
+func (l *Lease) renew() {
+	defer close(l.done)
+	defer close(l.lost)
+
+	t := time.NewTicker(l.ttl / 3)
+	defer t.Stop()
+
+	renewed := time.Now()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-t.C:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), l.ttl/3)
+		res, err := l.c.Eval(ctx, renewScript, []string{l.key}, l.token, l.ttl.Milliseconds())
+		cancel()
+
+		switch {
+		case err == nil && res == int64(1):
+			renewed = time.Now()
+		case err == nil:
+			l.lost <- ErrLeaseLost
+			return
+		case time.Since(renewed) >= l.ttl:
+			l.lost <- err
+			return
+		}
+	}
+}
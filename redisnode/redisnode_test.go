@@ -0,0 +1,188 @@
+package redisnode
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis implements Client in memory, understanding the package's scripts.
+type fakeRedis struct {
+	sync.Mutex
+	values  map[string]string
+	expires map[string]time.Time
+	err     error
+}
+
+func newFakeRedis() *fakeRedis {
+	return &fakeRedis{values: make(map[string]string), expires: make(map[string]time.Time)}
+}
+
+func (r *fakeRedis) get(key string) (string, bool) {
+	if exp, ok := r.expires[key]; ok && time.Now().After(exp) {
+		delete(r.values, key)
+		delete(r.expires, key)
+	}
+	v, ok := r.values[key]
+	return v, ok
+}
+
+func (r *fakeRedis) SetNX(ctx context.Context, key, value string, ttl time.Duration) (bool, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.err != nil {
+		return false, r.err
+	}
+	if _, ok := r.get(key); ok {
+		return false, nil
+	}
+
+	r.values[key] = value
+	r.expires[key] = time.Now().Add(ttl)
+	return true, nil
+}
+
+func (r *fakeRedis) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.err != nil {
+		return nil, r.err
+	}
+	if v, ok := r.get(keys[0]); !ok || v != args[0] {
+		return int64(0), nil
+	}
+
+	switch script {
+	case renewScript:
+		r.expires[keys[0]] = time.Now().Add(time.Duration(args[1].(int64)) * time.Millisecond)
+	case releaseScript:
+		delete(r.values, keys[0])
+		delete(r.expires, keys[0])
+	default:
+		return nil, errors.New("unknown script")
+	}
+	return int64(1), nil
+}
+
+func TestAcquire(t *testing.T) {
+	r := newFakeRedis()
+	ctx := context.Background()
+	cfg := Config{Nodes: 3, TTL: time.Minute}
+
+	seen := make(map[int64]bool)
+	var leases []*Lease
+	for i := 0; i < 3; i++ {
+		l, err := Acquire(ctx, r, cfg)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if seen[l.ID()] {
+			t.Fatalf("Node %d leased twice", l.ID())
+		}
+		seen[l.ID()] = true
+		leases = append(leases, l)
+
+		if id := l.Node().Generate(); id.Node() != l.ID() {
+			t.Errorf("Got node %d, expected %d", id.Node(), l.ID())
+		}
+	}
+
+	if _, err := Acquire(ctx, r, cfg); err != ErrNoFreeNode {
+		t.Fatalf("Got error %v, expected ErrNoFreeNode", err)
+	}
+
+	if err := leases[1].Close(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	l, err := Acquire(ctx, r, cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if l.ID() != leases[1].ID() {
+		t.Errorf("Got node %d, expected the released node %d", l.ID(), leases[1].ID())
+	}
+}
+
+func TestLeaseRenewal(t *testing.T) {
+	r := newFakeRedis()
+	ctx := context.Background()
+
+	l, err := Acquire(ctx, r, Config{Nodes: 1, TTL: 30 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	select {
+	case err := <-l.Lost():
+		t.Fatalf("Lease lost while renewing: %v", err)
+	default:
+	}
+
+	// Another owner taking the key over loses the lease.
+	r.Lock()
+	r.values[l.key] = "other"
+	r.Unlock()
+
+	select {
+	case err := <-l.Lost():
+		if err != ErrLeaseLost {
+			t.Errorf("Got error %v, expected ErrLeaseLost", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Lease loss was not reported")
+	}
+
+	l.Close(ctx)
+}
+
+func TestLeaseRenewalErrors(t *testing.T) {
+	r := newFakeRedis()
+	ctx := context.Background()
+
+	l, err := Acquire(ctx, r, Config{Nodes: 1, TTL: 30 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	down := errors.New("connection refused")
+	r.Lock()
+	r.err = down
+	r.Unlock()
+
+	select {
+	case err := <-l.Lost():
+		if err != down {
+			t.Errorf("Got error %v, expected %v", err, down)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Lease loss was not reported")
+	}
+}
+
+func TestNodeCloseReleasesLease(t *testing.T) {
+	r := newFakeRedis()
+	ctx := context.Background()
+	cfg := Config{Nodes: 1}
+
+	l, err := Acquire(ctx, r, cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := l.Node().Close(); err != nil {
+		t.Fatalf("Unexpected error closing the node: %v", err)
+	}
+	if err := l.Close(ctx); err != nil {
+		t.Errorf("Unexpected error closing the closed lease: %v", err)
+	}
+
+	if _, err := Acquire(ctx, r, cfg); err != nil {
+		t.Errorf("Got %v acquiring the released node, expected nil", err)
+	}
+}
@@ -0,0 +1,72 @@
+package snowflake
+
+import (
+	"errors"
+	"strconv"
+)
+
+// WithRegionBits reserves the top bits of the node field for a region code,
+// so IDs can carry both the region and the node that generated them.  The
+// node number must then fit in the remaining node bits, for example with the
+// default 10 node bits, 4 region bits allow 16 regions of 64 nodes each.
+func WithRegionBits(bits uint8) Option {
+	return func(n *Node) error {
+		n.regionBits = bits
+		return nil
+	}
+}
+
+// GenerateRegion creates and returns a unique snowflake ID carrying the given
+// region code.  The node must have been created with WithRegionBits and region
+// must fit in the reserved bits.
+func (n *Node) GenerateRegion(region int64) (ID, error) {
+	if region < 0 || region >= 1<<n.regionBits || n.regionBits == 0 {
+		return 0, errors.New("region " + strconv.FormatInt(region, 10) + " does not fit in the node's region bits")
+	}
+
+	if err := n.open(); err != nil {
+		return 0, err
+	}
+	n.throttle(1)
+
+	node := region<<(n.layout.NodeBits-n.regionBits) | n.node
+
+	var r ID
+	if n.lockFree {
+		r = n.generateCAS(node)
+	} else {
+		n.Lock()
+		r = n.generateNode(node)
+		n.Unlock()
+	}
+
+	n.runHooks(r)
+	return r, nil
+}
+
+// Region returns the region code of a snowflake ID generated by a node using
+// DefaultLayout and WithRegionBits(bits).  Unlike the layout, which the ID
+// methods assume is DefaultLayout, there is no default width for the region
+// code, and nothing in an ID records it, so it must be given as bits.  Use
+// Node.IDRegion to decode with the width configured on a node.
+func (f ID) Region(bits uint8) int64 {
+	return f.Node() >> (nodeBits - bits)
+}
+
+// RegionNode returns the node number, without the region code, of a snowflake
+// ID generated by a node using DefaultLayout and WithRegionBits(bits).
+func (f ID) RegionNode(bits uint8) int64 {
+	return f.Node() & (1<<(nodeBits-bits) - 1)
+}
+
+// IDRegion is like ID.Region but decodes a snowflake ID generated by this
+// node, according to its layout and region bits.
+func (n *Node) IDRegion(id ID) int64 {
+	return n.IDNode(id) >> (n.layout.NodeBits - n.regionBits)
+}
+
+// IDRegionNode is like ID.RegionNode but decodes a snowflake ID generated by
+// this node, according to its layout and region bits.
+func (n *Node) IDRegionNode(id ID) int64 {
+	return n.IDNode(id) & (1<<(n.layout.NodeBits-n.regionBits) - 1)
+}
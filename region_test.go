@@ -0,0 +1,60 @@
+package snowflake
+
+import "testing"
+
+func TestGenerateRegion(t *testing.T) {
+	a, err := NewNode(63, WithRegionBits(4))
+	if err != nil {
+		t.Fatalf("Unexpected error creating node: %v", err)
+	}
+	b, _ := NewNode(1, WithRegionBits(4))
+
+	seen := make(map[ID]bool)
+	for region := int64(0); region < 16; region++ {
+		for _, n := range []*Node{a, b} {
+			id, err := n.GenerateRegion(region)
+			if err != nil {
+				t.Fatalf("Unexpected error generating region %d: %v", region, err)
+			}
+
+			if seen[id] {
+				t.Fatalf("Duplicate ID %d", id)
+			}
+			seen[id] = true
+
+			if id.Region(4) != region {
+				t.Errorf("Got region %d, expected %d", id.Region(4), region)
+			}
+
+			if id.RegionNode(4) != n.node {
+				t.Errorf("Got node %d, expected %d", id.RegionNode(4), n.node)
+			}
+		}
+	}
+
+	if _, err := a.GenerateRegion(16); err == nil {
+		t.Error("Expected an error for a region that does not fit")
+	}
+
+	if _, err := NewNode(64, WithRegionBits(4)); err == nil {
+		t.Error("Expected an error for a node number that does not fit")
+	}
+
+	plain, _ := NewNode(1)
+	if _, err := plain.GenerateRegion(0); err == nil {
+		t.Error("Expected an error for a node without region bits")
+	}
+}
+
+func TestNodeIDRegion(t *testing.T) {
+	node, _ := NewNode(5, WithLayout(Layout{NodeBits: 6, StepBits: 16}), WithRegionBits(2))
+
+	id, err := node.GenerateRegion(3)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if r, n := node.IDRegion(id), node.IDRegionNode(id); r != 3 || n != 5 {
+		t.Errorf("Got region %d and node %d, expected 3 and 5", r, n)
+	}
+}
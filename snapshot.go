@@ -0,0 +1,134 @@
+package snowflake
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"time"
+)
+
+// A NodeState is a snapshot of a node's configuration and position, for
+// checkpointing a generator inside workflow engines and other systems that
+// serialize their execution state.  A node restored from it continues
+// strictly after the last ID generated before the snapshot was taken.
+//
+// NodeState encodes to a fixed binary form with MarshalBinary, which
+// encoding/gob uses as well.
+type NodeState struct {
+	Node   int64
+	Epoch  int64
+	Layout Layout
+
+	// Time is the time of the node's last ID in units of the layout since
+	// the epoch, or -1 if it has not generated any, and Step is its step.
+	Time int64
+	Step int64
+}
+
+// Snapshot returns the node's current state.  IDs generated after the call
+// are not covered, so take the snapshot at the point being checkpointed.
+func (n *Node) Snapshot() NodeState {
+	s := NodeState{Node: n.node, Epoch: n.epoch, Layout: n.layout, Time: -1}
+
+	if n.lockFree {
+		// Holding the word waits for WithLock and GenerateN, which keep
+		// their position in n.held until they release it.
+		n.holdState()
+		v := n.held
+		n.releaseState()
+
+		if v != 0 {
+			s.Time, s.Step = v>>n.layout.StepBits, v&n.stepMask
+		}
+		return s
+	}
+
+	n.Lock()
+	if n.time != math.MinInt64 {
+		s.Time, s.Step = n.time, n.step
+	}
+	n.Unlock()
+
+	return s
+}
+
+// RestoreNode creates a node from a snapshot taken with Snapshot, using its
+// node number, epoch and layout, and continuing after its last ID.  opts are
+// applied after the snapshot's settings, but may not change the epoch or
+// layout.  If the snapshot is rejected the node is closed, releasing what
+// opts tied to it.
+func RestoreNode(s NodeState, opts ...Option) (*Node, error) {
+	n, err := NewNode(s.Node, append([]Option{WithEpoch(s.Epoch), WithLayout(s.Layout)}, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	if n.epoch != s.Epoch || n.layout != s.Layout {
+		n.Close()
+		return nil, errors.New("options must not change the epoch or layout of a restored node")
+	}
+	if s.Time < 0 {
+		return n, nil
+	}
+	if s.Step < 0 || s.Step > n.stepMask {
+		n.Close()
+		return nil, errors.New("snapshot step does not fit in the layout's step bits")
+	}
+
+	if n.lockFree {
+		n.state.Store(s.Time<<n.layout.StepBits | s.Step)
+	} else if s.Time > n.time || s.Time == n.time && s.Step > n.step {
+		n.time, n.step = s.Time, s.Step
+	}
+	return n, nil
+}
+
+// nodeStateVersion is the first byte of a NodeState's binary form.
+const nodeStateVersion = 1
+
+const nodeStateSize = 1 + 8 + 8 + 3 + 8 + 8 + 8
+
+// MarshalBinary returns the state in a fixed 44 byte binary form.
+func (s NodeState) MarshalBinary() ([]byte, error) {
+	return s.AppendBinary(make([]byte, 0, nodeStateSize))
+}
+
+// AppendBinary appends the binary form of MarshalBinary to b.
+func (s NodeState) AppendBinary(b []byte) ([]byte, error) {
+	var flags byte
+	if s.Layout.NodeLow {
+		flags |= 1
+	}
+	if s.Layout.Unsigned {
+		flags |= 2
+	}
+
+	b = append(b, nodeStateVersion)
+	b = binary.BigEndian.AppendUint64(b, uint64(s.Node))
+	b = binary.BigEndian.AppendUint64(b, uint64(s.Epoch))
+	b = append(b, s.Layout.NodeBits, s.Layout.StepBits, flags)
+	b = binary.BigEndian.AppendUint64(b, uint64(s.Layout.TimeUnit))
+	b = binary.BigEndian.AppendUint64(b, uint64(s.Time))
+	b = binary.BigEndian.AppendUint64(b, uint64(s.Step))
+	return b, nil
+}
+
+// UnmarshalBinary decodes the binary form of MarshalBinary.
+func (s *NodeState) UnmarshalBinary(b []byte) error {
+	if len(b) != nodeStateSize || b[0] != nodeStateVersion {
+		return errors.New("invalid binary node state")
+	}
+
+	s.Node = int64(binary.BigEndian.Uint64(b[1:]))
+	s.Epoch = int64(binary.BigEndian.Uint64(b[9:]))
+	s.Layout = Layout{
+		NodeBits: b[17],
+		StepBits: b[18],
+		NodeLow:  b[19]&1 != 0,
+		Unsigned: b[19]&2 != 0,
+		TimeUnit: time.Duration(binary.BigEndian.Uint64(b[20:])),
+	}
+	s.Time = int64(binary.BigEndian.Uint64(b[28:]))
+	s.Step = int64(binary.BigEndian.Uint64(b[36:]))
+	return nil
+}
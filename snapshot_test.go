@@ -0,0 +1,119 @@
+package snowflake
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+	"time"
+)
+
+func TestGob(t *testing.T) {
+	ids := []ID{0, 1, 1417819914141990912, 1<<63 - 1}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(ids); err != nil {
+		t.Fatalf("error encoding, %s", err)
+	}
+
+	var got []ID
+	if err := gob.NewDecoder(&buf).Decode(&got); err != nil {
+		t.Fatalf("error decoding, %s", err)
+	}
+	for i := range ids {
+		if got[i] != ids[i] {
+			t.Errorf("Got %d, expected %d", got[i], ids[i])
+		}
+	}
+
+	if b, _ := ID(0x0102030405060708).AppendBinary([]byte{0xFF}); !bytes.Equal(b, []byte{0xFF, 1, 2, 3, 4, 5, 6, 7, 8}) {
+		t.Errorf("Got %x, expected ff0102030405060708", b)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	layout := Layout{NodeBits: 8, StepBits: 14, TimeUnit: 10 * time.Millisecond, NodeLow: true}
+
+	for _, lockFree := range []bool{false, true} {
+		opts := []Option{WithLayout(layout), WithEpoch(Epoch + 1000)}
+		if !lockFree {
+			opts = append(opts, withMutex())
+		}
+		node, _ := NewNode(42, opts...)
+
+		if s := node.Snapshot(); s.Time != -1 {
+			t.Errorf("Got time %d before generating, expected -1", s.Time)
+		}
+
+		last := node.GenerateN(1000)[999]
+		s := node.Snapshot()
+		if s.Node != 42 || s.Epoch != Epoch+1000 || s.Layout != layout {
+			t.Errorf("Got %+v, expected the node's settings", s)
+		}
+
+		b, err := s.MarshalBinary()
+		if err != nil || len(b) != 44 {
+			t.Fatalf("Got (%d bytes, %v), expected (44, nil)", len(b), err)
+		}
+
+		var decoded NodeState
+		if err := decoded.UnmarshalBinary(b); err != nil || decoded != s {
+			t.Fatalf("Got (%+v, %v), expected (%+v, nil)", decoded, err, s)
+		}
+
+		var buf bytes.Buffer
+		gob.NewEncoder(&buf).Encode(s)
+		var viaGob NodeState
+		if err := gob.NewDecoder(&buf).Decode(&viaGob); err != nil || viaGob != s {
+			t.Fatalf("Got (%+v, %v) through gob, expected (%+v, nil)", viaGob, err, s)
+		}
+
+		restored, err := RestoreNode(decoded, opts[2:]...)
+		if err != nil {
+			t.Fatalf("error restoring, %s", err)
+		}
+		if next := restored.Generate(); restored.Compare(next, last) <= 0 {
+			t.Errorf("Got %d from the restored node, expected it after %d", next, last)
+		}
+	}
+
+	if _, err := RestoreNode(NodeState{Node: 1, Epoch: Epoch, Layout: DefaultLayout, Time: -1}, WithLayout(layout)); err == nil {
+		t.Error("Expected an error changing the layout of a restored node")
+	}
+
+	var s NodeState
+	if err := s.UnmarshalBinary([]byte{2}); err == nil {
+		t.Error("Expected an error for an invalid binary state")
+	}
+
+	// Rejected snapshots close the node they created.
+	closed := 0
+	closer := WithCloser(func() error { closed++; return nil })
+	RestoreNode(NodeState{Node: 1, Epoch: Epoch, Layout: DefaultLayout, Time: -1}, closer, WithLayout(layout))
+	RestoreNode(NodeState{Node: 1, Epoch: Epoch, Layout: DefaultLayout, Time: 5, Step: 1 << 12}, closer)
+	if closed != 2 {
+		t.Errorf("Got %d closers run for rejected snapshots, expected 2", closed)
+	}
+}
+
+func TestSnapshotDuringWithLock(t *testing.T) {
+	node, _ := NewNode(1)
+
+	var last ID
+	done := make(chan NodeState)
+	node.WithLock(func(gen func() ID) {
+		for i := 0; i < 10; i++ {
+			last = gen()
+		}
+		go func() { done <- node.Snapshot() }()
+		time.Sleep(10 * time.Millisecond)
+		last = gen()
+	})
+
+	restored, err := RestoreNode(<-done)
+	if err != nil {
+		t.Fatalf("Unexpected error restoring: %v", err)
+	}
+	if next := restored.Generate(); next <= last {
+		t.Errorf("Got %d from the restored node, expected it after %d", next, last)
+	}
+}
@@ -2,13 +2,20 @@
 package snowflake
 
 import (
+	"context"
 	"crypto/md5"
 	"encoding/base64"
 	"encoding/binary"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"math"
 	"os"
+	"sort"
 	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -23,6 +30,10 @@ const (
 
 // Epoch is set to the twitter snowflake epoch of 2006-03-21:20:50:14 GMT
 // You may customize this to set a different epoch for your application.
+//
+// NewNode reads Epoch once, so changing it only affects nodes created
+// afterwards and the ID methods.  Use WithEpoch to run nodes with different
+// epochs in the same process.
 var Epoch int64 = 1288834974657
 
 // A Node struct holds the basic information needed for a snowflake generator
@@ -32,132 +43,1166 @@ type Node struct {
 	time int64
 	node int64
 	step int64
+
+	epoch     int64
+	layout    Layout
+	nodeMax   int64
+	stepMask  int64
+	timeShift uint8
+	nodeShift uint8
+	stepShift uint8
+	unit      int64 // nanoseconds
+
+	now        func() int64 // unix nanoseconds
+	clockCache time.Duration
+	regionBits uint8
+	randomBits uint8
+	interleave bool
+	lockFree   bool
+	mutex      bool
+	policy     ClockPolicy
+	exhaustion ExhaustionPolicy
+	state      atomic.Int64
+	held       int64
+
+	unique  func(int64) bool
+	hooks   []func(ID)
+	tenants []*TenantGen
+
+	backfillTime int64
+	backfillStep int64
+
+	limiter *rateLimiter
+
+	closers   []func() error
+	closeOnce sync.Once
+	closeErr  error
+	closed    atomic.Bool
+
+	store       StateStore
+	storeWindow int64
+	reserved    int64
+	storeErr    error
+	storeFailed time.Time
+
+	warnFraction float64
+	warnAt       int64
+	warned       atomic.Bool
+	onWarn       func(remaining time.Duration)
+
+	count     atomic.Uint64
+	taken     atomic.Uint64
+	exhausted atomic.Uint64
+	behind    atomic.Uint64
+	borrowed  atomic.Uint64
 }
 
+// An Option configures optional behaviour of a Node created with NewNode.
+type Option func(*Node) error
+
 // An ID is a custom type used for a snowflake ID.  This is used so we can
 // attach methods onto the ID.
+//
+// Since the underlying type is int64, IDs can be compared with < and satisfy
+// cmp.Ordered, so they can be used directly as keys in generic ordered code.
 type ID int64
 
 // NewNode returns a new snowflake node that can be used to generate snowflake
 // IDs
-func NewNode(node int64) (*Node, error) {
+func NewNode(node int64, opts ...Option) (*Node, error) {
+
+	n := &Node{
+		time:         math.MinInt64,
+		node:         node,
+		step:         0,
+		epoch:        Epoch,
+		layout:       DefaultLayout,
+		now:          nowNanos,
+		reserved:     math.MaxInt64,
+		backfillTime: -1,
+	}
+
+	for _, opt := range opts {
+		if err := opt(n); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := n.layout.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Nodes generate lock free unless they need the mutex for a state store.
+	if n.store == nil && !n.mutex {
+		n.lockFree = true
+	}
+
+	if n.interleave && n.layout.Unsigned {
+		return nil, errors.New("bit interleaving does not support unsigned layouts")
+	}
 
-	if node < 0 || node > nodeMax {
-		return nil, errors.New("Node number must be between 0 and 1023")
+	n.nodeMax = -1 ^ (-1 << n.layout.NodeBits)
+	n.stepMask = -1 ^ (-1 << n.layout.StepBits)
+	n.timeShift = n.layout.NodeBits + n.layout.StepBits
+	n.nodeShift, n.stepShift = n.layout.shifts()
+	n.unit = n.layout.unit()
+
+	if node < 0 || node > n.nodeMax {
+		return nil, errors.New("Node number must be between 0 and " + strconv.FormatInt(n.nodeMax, 10))
+	}
+
+	if n.now() < n.epoch*int64(time.Millisecond) {
+		return nil, ErrEpochInFuture
+	}
+
+	if n.unique != nil && !n.unique(node) {
+		return nil, ErrNodeCollision
+	}
+
+	if n.onWarn != nil {
+		n.warnAt = int64(n.warnFraction * float64(n.layout.timeLimit()))
+	}
+
+	if n.randomBits > n.layout.StepBits {
+		return nil, errors.New("random step bits must be between 0 and " + strconv.Itoa(int(n.layout.StepBits)))
+	}
+
+	if n.regionBits > 0 {
+		if n.regionBits > n.layout.NodeBits {
+			return nil, errors.New("region bits must be between 0 and " + strconv.Itoa(int(n.layout.NodeBits)))
+		}
+
+		if max := n.nodeMax >> n.regionBits; node > max {
+			return nil, errors.New("Node number must be between 0 and " + strconv.FormatInt(max, 10) + " with " + strconv.Itoa(int(n.regionBits)) + " region bits")
+		}
+	}
+
+	// Restoring saves a new window to the store, so it must come after every
+	// check that can still reject the node.
+	if n.store != nil {
+		if err := n.restore(); err != nil {
+			return nil, err
+		}
 	}
 
-	return &Node{
-		time: 0,
-		node: node,
-		step: 0,
-	}, nil
+	if n.clockCache > 0 {
+		n.startClockCache()
+	}
+
+	return n, nil
+}
+
+// WithLayout makes the node generate IDs using layout l instead of
+// DefaultLayout, for example to trade node bits for more IDs per millisecond.
+// The node number must fit in the layout's node bits.  IDs from such a node
+// must be decoded with Node.Decode or Layout.Decode, as the ID methods assume
+// DefaultLayout.
+func WithLayout(l Layout) Option {
+	return func(n *Node) error {
+		n.layout = l
+		return nil
+	}
+}
+
+// WithEpoch makes the node generate IDs relative to epoch, in milliseconds
+// since the unix epoch, instead of the package Epoch.  IDs from such a node
+// must be decoded with Node.Decode or Layout.Decode, as the ID methods use
+// the package Epoch.
+func WithEpoch(epoch int64) Option {
+	return func(n *Node) error {
+		n.epoch = epoch
+		return nil
+	}
+}
+
+// Epoch returns the epoch of the IDs the node generates, in milliseconds
+// since the unix epoch.
+func (n *Node) Epoch() int64 {
+	return n.epoch
+}
+
+// Layout returns the layout of the IDs the node generates.
+func (n *Node) Layout() Layout {
+	return n.layout
+}
+
+// Decode returns the fields of a snowflake ID generated by this node,
+// according to its epoch and layout.  IDs of a node using WithBitInterleave
+// are deinterleaved first, as they are by the other ID methods of the node.
+func (n *Node) Decode(id ID) Parts {
+	return n.layout.Decode(n.canonical(id), n.epoch)
+}
+
+// canonical returns id as the node's layout packs it, undoing
+// WithBitInterleave.
+func (n *Node) canonical(id ID) ID {
+	if n.interleave {
+		return id.Deinterleave()
+	}
+	return id
+}
+
+// IDTime is like ID.Time but decodes a snowflake ID generated by this node,
+// according to its epoch and layout.
+func (n *Node) IDTime(id ID) int64 {
+	t, _, _ := n.layout.fields(n.canonical(id))
+	return n.layout.timeOf(t, n.epoch).UnixMilli()
+}
+
+// IDTimestamp returns the time of a snowflake ID generated by this node as a
+// time.Time, at the full precision of the node's layout, such as the
+// microseconds of MicrosecondLayout, where IDTime rounds to milliseconds.
+func (n *Node) IDTimestamp(id ID) time.Time {
+	t, _, _ := n.layout.fields(n.canonical(id))
+	return n.layout.timeOf(t, n.epoch)
+}
+
+// IDTimeMicros is like ID.TimeMicros but decodes a snowflake ID generated by
+// this node, according to its epoch and layout, so it keeps the microseconds
+// of layouts such as MicrosecondLayout.
+func (n *Node) IDTimeMicros(id ID) int64 {
+	return n.IDTimestamp(id).UnixMicro()
+}
+
+// IDNode is like ID.Node but decodes a snowflake ID generated by this node,
+// according to its layout.
+func (n *Node) IDNode(id ID) int64 {
+	_, node, _ := n.layout.fields(n.canonical(id))
+	return node
+}
+
+// IDStep is like ID.Step but decodes a snowflake ID generated by this node,
+// according to its layout.
+func (n *Node) IDStep(id ID) int64 {
+	_, _, step := n.layout.fields(n.canonical(id))
+	return step
+}
+
+// nowMillis returns the current unix time in milliseconds.
+func nowMillis() int64 {
+	return time.Now().UnixNano() / 1000000
+}
+
+// nowNanos returns the current unix time in nanoseconds.
+func nowNanos() int64 {
+	return time.Now().UnixNano()
 }
 
 // NewNodeByHostname is a convenience method which creates a new Node based
 // off a hash of the machine's hostname.
+//
+// The hash is truncated to 10 bits, so about 38 hosts already have an even
+// chance of two of them colliding.  Use HostnameNodeID with WithUniqueCheck
+// to verify the node number is unique before generating.
 func NewNodeByHostname() (*Node, error) {
-	name, err := os.Hostname()
+	id, err := HostnameNodeID()
 	if err != nil {
 		return nil, err
 	}
 
+	return NewNode(id)
+}
+
+// HostnameNodeID returns the node number NewNodeByHostname derives from the
+// machine's hostname, so operators can check it for collisions.
+func HostnameNodeID() (int64, error) {
+	name, err := os.Hostname()
+	if err != nil {
+		return 0, err
+	}
+
 	hash := md5.Sum([]byte(name))
 	id := binary.BigEndian.Uint64(hash[:]) & 0x3FF // mask to first 10 bits, max of 1023
 
-	return NewNode(int64(id))
+	return int64(id), nil
 }
 
-// Generate creates and returns a unique snowflake ID
+// ErrNodeCollision is returned by NewNode when the probe passed to
+// WithUniqueCheck reports that the node number is already in use.
+var ErrNodeCollision = errors.New("node number is already in use")
+
+// WithUniqueCheck makes NewNode call probe with the node number and fail with
+// ErrNodeCollision unless it returns true, for example after checking a
+// registry of the node numbers in use.
+func WithUniqueCheck(probe func(node int64) bool) Option {
+	return func(n *Node) error {
+		n.unique = probe
+		return nil
+	}
+}
+
+// Number returns the node number of the node.
+func (n *Node) Number() int64 {
+	return n.node
+}
+
+// Generate creates and returns a unique snowflake ID.  It panics with
+// ErrClosed if the node has been closed.
 func (n *Node) Generate() ID {
+	id, err := n.next()
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+// next is like Generate but returns ErrClosed instead of panicking, for
+// the streaming methods that stop once the node is closed.
+func (n *Node) next() (ID, error) {
+	if err := n.open(); err != nil {
+		return 0, err
+	}
+
+	n.throttle(1)
+
+	var r ID
+	if n.lockFree {
+		r = n.generateCAS(n.node)
+	} else {
+		n.Lock()
+		r = n.generate()
+		n.Unlock()
+	}
+
+	n.runHooks(r)
+	return r, nil
+}
+
+// tryNext is like Generate for a node using the mutex, but returns false
+// instead of waiting when another goroutine holds the node lock, so that a
+// Pool can move on to another node.  Like Generate it panics with ErrClosed
+// once the node is closed.  The rate limit is waited out with the
+// lock held, which only delays callers that are waiting for the same limit.
+func (n *Node) tryNext() (ID, bool) {
+	n.mustOpen()
+	if !n.TryLock() {
+		return 0, false
+	}
+
+	n.throttle(1)
+	r := n.generate()
+	n.Unlock()
+
+	n.runHooks(r)
+	return r, true
+}
+
+// GenerateUnlocked is like Generate but skips the node lock of a node with a
+// StateStore, for callers that guarantee the node is only ever used from one
+// goroutine at a time, such as one node per connection or per worker.
+// Calling it concurrently with itself or any other generating method
+// corrupts the node's state and can produce duplicate IDs.  Other nodes are
+// lock free and do not lock anyway, and GenerateUnlocked is the same as
+// Generate for them.
+func (n *Node) GenerateUnlocked() ID {
+	n.mustOpen()
+	n.throttle(1)
+
+	var r ID
+	if n.lockFree {
+		r = n.generateCAS(n.node)
+	} else {
+		r = n.generate()
+	}
+
+	n.runHooks(r)
+	return r
+}
+
+// ErrTimestampOverflow is returned by GenerateSafe once the time since the
+// node's epoch no longer fits in the layout's time bits, about 69 years after
+// the epoch with the default layout.
+var ErrTimestampOverflow = errors.New("timestamp overflows the ID time bits")
+
+// ErrEpochInFuture is returned by NewNode and GenerateSafe when the node's
+// epoch is after the current time.
+var ErrEpochInFuture = errors.New("epoch is in the future")
+
+// GenerateSafe is like Generate but returns an error instead of an invalid
+// or duplicate ID.  It returns ErrTimestampOverflow or ErrEpochInFuture when
+// the time does not fit in the ID, where Generate would silently return
+// wrapped or negative IDs, and ErrClockBackwards instead of waiting when the
+// clock has moved backwards and the node uses ErrorOnBackwardsClock.  It
+// returns ErrSequenceExhausted instead of waiting for the next time unit when
+// the node uses ErrorOnExhausted, and with WithRateLimit ErrRateLimited
+// instead of waiting for the limit, and ErrClosed once the node is closed.
+func (n *Node) GenerateSafe() (ID, error) {
+	if err := n.open(); err != nil {
+		return 0, err
+	}
+
+	now := n.now() - n.epoch*int64(time.Millisecond)
+	switch {
+	case now < 0:
+		return 0, ErrEpochInFuture
+	case !n.layout.timeFits(now / n.unit):
+		return 0, ErrTimestampOverflow
+	}
+
+	if n.limiter != nil && !n.limiter.allow(n.now(), 1) {
+		return 0, ErrRateLimited
+	}
+
+	if n.lockFree {
+		if n.policy == ErrorOnBackwardsClock && n.elapsed() < n.loadState()>>n.layout.StepBits {
+			n.behind.Add(1)
+			return 0, ErrClockBackwards
+		}
+
+		var r ID
+		if n.exhaustion == ErrorOnExhausted {
+			var wait waitReason
+			for r, _, wait = n.tryCAS(n.node); wait != waitNone; r, _, wait = n.tryCAS(n.node) {
+				if wait == waitExhausted {
+					n.exhausted.Add(1)
+					return 0, ErrSequenceExhausted
+				}
+			}
+		} else {
+			r = n.generateCAS(n.node)
+		}
+
+		n.runHooks(r)
+		return r, nil
+	}
 
 	n.Lock()
 
-	now := time.Now().UnixNano() / 1000000
+	if now := n.elapsed(); now > n.reserved && now > n.time && !n.reserve(now) {
+		n.Unlock()
+		return 0, n.storeErr
+	}
+
+	if n.policy == ErrorOnBackwardsClock && n.elapsed() < n.time {
+		n.behind.Add(1)
+		n.Unlock()
+		return 0, ErrClockBackwards
+	}
+
+	if n.exhaustion == ErrorOnExhausted && n.step == n.stepMask && n.elapsed() == n.time {
+		n.exhausted.Add(1)
+		n.Unlock()
+		return 0, ErrSequenceExhausted
+	}
+
+	r := n.generate()
+	n.Unlock()
+
+	n.runHooks(r)
+	return r, nil
+}
 
-	if n.time == now {
-		n.step = (n.step + 1) & stepMask
+// WithLock acquires the node lock once and calls fn with a gen function that
+// generates IDs without locking again.  IDs generated through gen within a
+// single call are monotonic and no other caller can generate IDs from this
+// node in between, which lets callers build multi step critical sections.
+//
+// fn must not call other locking Node methods, such as Generate or WithLock,
+// as the lock is already held and doing so will deadlock.  gen must not be
+// used after fn returns.
+func (n *Node) WithLock(fn func(gen func() ID)) {
+	n.mustOpen()
 
-		if n.step == 0 {
-			for now <= n.time {
-				now = time.Now().UnixNano() / 1000000
+	if n.lockFree {
+		n.holdState()
+		defer n.releaseState()
+
+		fn(func() ID {
+			n.count.Add(1)
+			return n.generateHeld(n.node)
+		})
+		return
+	}
+
+	n.Lock()
+	defer n.Unlock()
+
+	fn(n.generate)
+}
+
+// generate creates and returns a unique snowflake ID.  The caller must hold
+// the node lock.
+func (n *Node) generate() ID {
+	return n.generateNode(n.node)
+}
+
+// generateNode is like generate but stores the given value in the node field.
+func (n *Node) generateNode(node int64) ID {
+	if n.lockFree {
+		return n.generateCAS(node)
+	}
+
+	var waited waitReason
+	for {
+		t, wait := n.advance()
+		if wait == waitNone {
+			n.count.Add(1)
+			return n.compose(t, node, n.step)
+		}
+
+		waited = n.recordWait(waited, wait)
+		n.pause(wait, t)
+	}
+}
+
+// advance moves the node to its next time and step and returns the time.  If
+// the clock is behind the node or the step is exhausted, it instead returns
+// why along with the elapsed time the clock must reach first.  It must be
+// called with the node lock held.
+func (n *Node) advance() (int64, waitReason) {
+	return n.advanceSeq(&n.time, &n.step, n.stepMask, true)
+}
+
+// advanceSeq is advance for the time at and step of a sequence with steps
+// from 0 to last, such as the node's own or a TenantGen's.  randomFirst
+// starts new time units at firstStep rather than 0.  It must be called with
+// the node lock held.
+func (n *Node) advanceSeq(at, step *int64, last int64, randomFirst bool) (int64, waitReason) {
+	now := n.elapsed()
+
+	first := func() int64 {
+		if randomFirst {
+			return n.firstStep()
+		}
+		return 0
+	}
+
+	switch {
+	case now < *at && n.exhaustion != BorrowOnExhausted:
+		return *at, waitClockBehind
+	case now <= *at:
+		if *step < last {
+			*step++
+			return *at, waitNone
+		}
+		if n.exhaustion != BorrowOnExhausted {
+			return *at + 1, waitExhausted
+		}
+		if *at+1 > n.reserved && !n.reserve(*at+1) {
+			return *at + 1, waitStore
+		}
+
+		n.borrowed.Add(1)
+		*at++
+		*step = first()
+		return *at, waitNone
+	default:
+		if now > n.reserved && !n.reserve(now) {
+			return now, waitStore
+		}
+		*at = now
+		*step = first()
+	}
+
+	return now, waitNone
+}
+
+// GenerateCtx is like Generate but sleeps, without holding the node lock,
+// instead of spinning while the step is exhausted or the clock is behind,
+// freeing the CPU for other work.  It returns ctx.Err() if ctx is done
+// before an ID is available, and ErrClosed once the node is closed.
+//
+// Sleeping can overshoot the next millisecond, so latency sensitive callers
+// should keep using Generate.
+func (n *Node) GenerateCtx(ctx context.Context) (ID, error) {
+	if err := n.open(); err != nil {
+		return 0, err
+	}
+	if err := n.throttleCtx(ctx, 1); err != nil {
+		return 0, err
+	}
+
+	var waited waitReason
+	for {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+
+		var (
+			id   ID
+			t    int64
+			wait waitReason
+		)
+		if n.lockFree {
+			id, t, wait = n.tryCAS(n.node)
+		} else {
+			n.Lock()
+			if t, wait = n.advance(); wait == waitNone {
+				n.count.Add(1)
+				id = n.compose(t, n.node, n.step)
+			}
+			n.Unlock()
+		}
+
+		if wait == waitNone {
+			n.runHooks(id)
+			return id, nil
+		}
+		waited = n.recordWait(waited, wait)
+
+		d := time.Duration(n.epoch*int64(time.Millisecond) + t*n.unit - n.now())
+		if wait == waitStore {
+			d = storeRetry
+		}
+		if d > 0 {
+			timer := time.NewTimer(d)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return 0, ctx.Err()
+			case <-timer.C:
 			}
 		}
-	} else {
-		n.step = 0
 	}
+}
 
-	n.time = now
+// GenerateN creates and returns count unique snowflake IDs in ascending
+// order.  The node lock is taken once and the clock is read once per
+// millisecond of IDs, which is much cheaper than calling Generate count
+// times for bulk imports.  It panics with ErrClosed if the node has been
+// closed.
+func (n *Node) GenerateN(count int) []ID {
+	n.mustOpen()
+	n.throttle(count)
 
-	r := ID((now-Epoch)<<timeShift |
-		(n.node << nodeShift) |
-		(n.step),
-	)
+	ids := n.generateN(count)
+	n.runHookN(ids)
+	return ids
+}
+
+// GenerateNCtx is like GenerateN but returns an error instead of panicking,
+// for servers handing out batches: ErrClosed once the node is closed, and
+// ctx.Err() if ctx is done before the node's rate limit allows the IDs or
+// before generation starts.  A negative count is an error.
+func (n *Node) GenerateNCtx(ctx context.Context, count int) ([]ID, error) {
+	if count < 0 {
+		return nil, errors.New("count must not be negative")
+	}
+	if err := n.open(); err != nil {
+		return nil, err
+	}
+	if err := n.throttleCtx(ctx, count); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	ids := n.generateN(count)
+	n.runHookN(ids)
+	return ids, nil
+}
+
+// generateN generates count IDs for GenerateN and GenerateNCtx, without
+// checking the node is open, throttling or calling hooks.
+func (n *Node) generateN(count int) []ID {
+	ids := make([]ID, count)
+
+	if n.lockFree {
+		n.holdState()
+		for i := range ids {
+			ids[i] = n.generateHeld(n.node)
+		}
+		n.releaseState()
+
+		n.count.Add(uint64(count))
+		return ids
+	}
+
+	n.Lock()
+
+	var waited waitReason
+	for i := 0; i < count; {
+		t, wait := n.advance()
+		if wait != waitNone {
+			waited = n.recordWait(waited, wait)
+			n.pause(wait, t)
+			continue
+		}
+		waited = waitNone
+
+		ids[i] = n.compose(t, n.node, n.step)
+		for i++; n.step < n.stepMask && i < count; i++ {
+			n.step++
+			ids[i] = n.compose(t, n.node, n.step)
+		}
+	}
 
 	n.Unlock()
+
+	n.count.Add(uint64(count))
+	return ids
+}
+
+// elapsed returns the time since the node's epoch in units of its layout.
+func (n *Node) elapsed() int64 {
+	return (n.now() - n.epoch*int64(time.Millisecond)) / n.unit
+}
+
+// compose packs the elapsed time, node field and step into an ID.
+func (n *Node) compose(t, node, step int64) ID {
+	r := ID(t<<n.timeShift |
+		(node << n.nodeShift) |
+		(step << n.stepShift),
+	)
+
+	if n.interleave {
+		r = r.interleave()
+	}
+
+	if n.warnAt > 0 && t >= n.warnAt {
+		n.warnLifetime()
+	}
+
 	return r
 }
 
+// TakeCount returns the number of IDs the node has generated since the
+// previous call to TakeCount, or since it was created, and resets the count.
+// It is safe to call concurrently with generation.  The running total
+// reported by Stats is not reset.
+func (n *Node) TakeCount() uint64 {
+	for {
+		taken, total := n.taken.Load(), n.count.Load()
+		if total >= taken && n.taken.CompareAndSwap(taken, total) {
+			return total - taken
+		}
+	}
+}
+
+// Less reports whether a sorts before b, for use as a comparison function.
+// For IDs from the same epoch this orders by time, then node, then step.
+func Less(a, b ID) bool {
+	return a < b
+}
+
 // Int64 returns an int64 of the snowflake ID
 func (f ID) Int64() int64 {
 	return int64(f)
 }
 
+// ParseInt64 converts an int64 into a snowflake ID
+func ParseInt64(id int64) ID {
+	return ID(id)
+}
+
+// Uint64 returns a uint64 of the snowflake ID, such as for a protobuf uint64
+// or fixed64 field.
+func (f ID) Uint64() uint64 {
+	return uint64(f)
+}
+
+// ParseUint64 converts a uint64 into a snowflake ID
+func ParseUint64(id uint64) ID {
+	return ID(id)
+}
+
 // String returns a string of the snowflake ID
 func (f ID) String() string {
 	return strconv.FormatInt(int64(f), 10)
 }
 
+// AppendString appends the decimal string of the snowflake ID, as returned by
+// String, to dst and returns the extended buffer.  It does not allocate when
+// dst has room for the digits.
+func (f ID) AppendString(dst []byte) []byte {
+	return strconv.AppendInt(dst, int64(f), 10)
+}
+
+// ParseString converts a string returned by String into a snowflake ID
+func ParseString(id string) (ID, error) {
+	i, err := strconv.ParseInt(id, 10, 64)
+	return ID(i), err
+}
+
 // Base2 returns a string base2 of the snowflake ID
 func (f ID) Base2() string {
 	return strconv.FormatInt(int64(f), 2)
 }
 
+// ParseBase2 converts a base2 string returned by Base2 into a snowflake ID
+func ParseBase2(id string) (ID, error) {
+	i, err := strconv.ParseInt(id, 2, 64)
+	return ID(i), err
+}
+
 // Base36 returns a base36 string of the snowflake ID
 func (f ID) Base36() string {
 	return strconv.FormatInt(int64(f), 36)
 }
 
-// Base64 returns a base64 string of the snowflake ID
+// AppendBase36 appends the base36 string of the snowflake ID, as returned by
+// Base36, to dst and returns the extended buffer.
+func (f ID) AppendBase36(dst []byte) []byte {
+	return strconv.AppendInt(dst, int64(f), 36)
+}
+
+// ParseBase36 converts a base36 string returned by Base36 into a snowflake ID
+func ParseBase36(id string) (ID, error) {
+	i, err := strconv.ParseInt(id, 36, 64)
+	return ID(i), err
+}
+
+// Base64 returns a base64 string of the snowflake ID.  It encodes the
+// decimal string returned by Bytes with padding; Base64URL and Base64Raw
+// encode the 8 byte value instead and are shorter.
 func (f ID) Base64() string {
 	return base64.StdEncoding.EncodeToString(f.Bytes())
 }
 
+// ParseBase64 converts a base64 string returned by Base64 into a snowflake ID
+func ParseBase64(id string) (ID, error) {
+	b, err := base64.StdEncoding.DecodeString(id)
+	if err != nil {
+		return -1, err
+	}
+	return ParseBytes(b)
+}
+
+// Base64URL returns the 8 big endian bytes of the snowflake ID in unpadded
+// URL safe base64, an 11 character string of A-Z, a-z, 0-9, - and _.
+func (f ID) Base64URL() string {
+	b := f.IntBytes()
+	return base64.RawURLEncoding.EncodeToString(b[:])
+}
+
+// ParseBase64URL converts a string returned by Base64URL into a snowflake ID
+func ParseBase64URL(id string) (ID, error) {
+	return parseBase64Int(base64.RawURLEncoding, id)
+}
+
+// Base64Raw returns the 8 big endian bytes of the snowflake ID in unpadded
+// standard base64, for systems that expect + and / rather than - and _.
+func (f ID) Base64Raw() string {
+	b := f.IntBytes()
+	return base64.RawStdEncoding.EncodeToString(b[:])
+}
+
+// ParseBase64Raw converts a string returned by Base64Raw into a snowflake ID
+func ParseBase64Raw(id string) (ID, error) {
+	return parseBase64Int(base64.RawStdEncoding, id)
+}
+
+func parseBase64Int(enc *base64.Encoding, id string) (ID, error) {
+	b, err := enc.DecodeString(id)
+	if err != nil {
+		return -1, err
+	}
+
+	var f ID
+	if err := f.UnmarshalBinary(b); err != nil {
+		return -1, err
+	}
+	return f, nil
+}
+
 // Bytes returns a byte array of the snowflake ID
 func (f ID) Bytes() []byte {
 	return []byte(f.String())
 }
 
-// Time returns an int64 unix timestamp of the snowflake ID time
+// ParseBytes converts a byte array returned by Bytes into a snowflake ID
+func ParseBytes(id []byte) (ID, error) {
+	i, err := strconv.ParseInt(string(id), 10, 64)
+	return ID(i), err
+}
+
+// IntBytes returns an array of bytes of the snowflake ID, encoded as a big
+// endian integer.  Unlike Bytes it is the raw 8 byte value, which sorts the
+// same as the ID and suits binary keys.
+func (f ID) IntBytes() [8]byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], uint64(f))
+	return b
+}
+
+// ParseIntBytes converts an array of bytes encoded as big endian integer as
+// a snowflake ID
+func ParseIntBytes(id [8]byte) ID {
+	return ID(int64(binary.BigEndian.Uint64(id[:])))
+}
+
+// MarshalBinary returns the snowflake ID as 8 big endian bytes, as IntBytes
+// does.
+func (f ID) MarshalBinary() ([]byte, error) {
+	b := f.IntBytes()
+	return b[:], nil
+}
+
+// AppendBinary appends the snowflake ID as 8 big endian bytes to b, as
+// MarshalBinary returns them.
+func (f ID) AppendBinary(b []byte) ([]byte, error) {
+	return binary.BigEndian.AppendUint64(b, uint64(f)), nil
+}
+
+// GobEncode returns the snowflake ID as 8 big endian bytes for encoding/gob.
+func (f ID) GobEncode() ([]byte, error) {
+	return f.MarshalBinary()
+}
+
+// GobDecode converts the 8 big endian bytes of GobEncode into an ID type.
+func (f *ID) GobDecode(b []byte) error {
+	return f.UnmarshalBinary(b)
+}
+
+// UnmarshalBinary converts 8 big endian bytes into an ID type.
+func (f *ID) UnmarshalBinary(b []byte) error {
+	if len(b) != 8 {
+		return errors.New("invalid binary snowflake ID length " + strconv.Itoa(len(b)) + ", expected 8")
+	}
+
+	*f = ID(int64(binary.BigEndian.Uint64(b)))
+	return nil
+}
+
+// Time returns an int64 unix timestamp of the snowflake ID time.  It assumes
+// DefaultLayout and Epoch; use Node.IDTime for other layouts and epochs.
 func (f ID) Time() int64 {
 	return (int64(f) >> 22) + Epoch
 }
 
-// Node returns an int64 of the snowflake ID node number
+// TimeStd returns the time of the snowflake ID as a time.Time.  Like Time it
+// assumes DefaultLayout and Epoch.
+func (f ID) TimeStd() time.Time {
+	return time.UnixMilli(f.Time())
+}
+
+// GuessEpoch returns the epoch that would make the snowflake ID's time equal
+// assumedTime.  It is a best effort diagnostic for IDs from unknown sources
+// and is only as accurate as the reference time the caller supplies, such as
+// the known creation time of the record the ID belongs to.
+func GuessEpoch(id ID, assumedTime time.Time) int64 {
+	return assumedTime.UnixNano()/1000000 - int64(id)>>timeShift
+}
+
+// TimeMicros returns an int64 unix timestamp in microseconds of the snowflake
+// ID time.  DefaultLayout records milliseconds, so the result is always a
+// whole number of milliseconds; use Node.IDTimeMicros for IDs of a finer
+// layout such as MicrosecondLayout.
+func (f ID) TimeMicros() int64 {
+	return f.Time() * 1000
+}
+
+// TimeWindow returns the half open interval [start, end) during which the
+// snowflake ID was generated.  IDs only record milliseconds, so the window is
+// always one millisecond wide.
+func (f ID) TimeWindow() (start, end time.Time) {
+	start = time.Unix(0, f.Time()*int64(time.Millisecond))
+	return start, start.Add(time.Millisecond)
+}
+
+// Node returns an int64 of the snowflake ID node number.  It assumes
+// DefaultLayout; use Node.IDNode for other layouts.
 func (f ID) Node() int64 {
 	return int64(f) & 0x00000000003FF000 >> nodeShift
 }
 
-// Step returns an int64 of the snowflake step (or sequence) number
+// Step returns an int64 of the snowflake step (or sequence) number.  It
+// assumes DefaultLayout; use Node.IDStep for other layouts.
 func (f ID) Step() int64 {
 	return int64(f) & 0x0000000000000FFF
 }
 
-// MarshalJSON returns a json byte array string of the snowflake ID.
+// debugTimeLayout is the time layout used by DebugString.
+const debugTimeLayout = "20060102T150405.000"
+
+// DebugString returns a human readable string of the snowflake ID made of its
+// UTC time, node and step, such as 20231114T153000.123-n42-s7.
+func (f ID) DebugString() string {
+	t := time.Unix(0, f.Time()*int64(time.Millisecond)).UTC()
+	return t.Format(debugTimeLayout) + "-n" + strconv.FormatInt(f.Node(), 10) + "-s" + strconv.FormatInt(f.Step(), 10)
+}
+
+// ParseDebugString converts a string returned by DebugString back into a
+// snowflake ID.
+func ParseDebugString(s string) (ID, error) {
+	invalid := fmt.Errorf("invalid debug string %q", s)
+
+	if len(s) < len(debugTimeLayout) {
+		return 0, invalid
+	}
+
+	t, err := time.Parse(debugTimeLayout, s[:len(debugTimeLayout)])
+	if err != nil {
+		return 0, invalid
+	}
+
+	rest := s[len(debugTimeLayout):]
+	if !strings.HasPrefix(rest, "-n") {
+		return 0, invalid
+	}
+
+	nodeStr, stepStr, ok := strings.Cut(rest[2:], "-s")
+	if !ok {
+		return 0, invalid
+	}
+
+	node, err := strconv.ParseInt(nodeStr, 10, 64)
+	if err != nil || node < 0 || node > nodeMax {
+		return 0, invalid
+	}
+
+	step, err := strconv.ParseInt(stepStr, 10, 64)
+	if err != nil || step < 0 || step > stepMask {
+		return 0, invalid
+	}
+
+	ms := t.UnixMilli() - Epoch
+	if ms < 0 || ms >= 1<<(63-timeShift) {
+		return 0, invalid
+	}
+
+	return ID(ms<<timeShift | node<<nodeShift | step), nil
+}
+
+// MarshalJSON returns a json byte array string of the snowflake ID, or
+// another encoding selected by SetJSONEncoding.
 func (f ID) MarshalJSON() ([]byte, error) {
-	buff := make([]byte, 0, 22)
-	buff = append(buff, '"')
-	buff = strconv.AppendInt(buff, int64(f), 10)
-	buff = append(buff, '"')
-	return buff, nil
+	return f.AppendJSON(make([]byte, 0, 22)), nil
+}
+
+// AppendQuotedJSON appends the JSON encoding of the snowflake ID as a quoted
+// decimal string, as returned by MarshalJSON by default, to dst and returns
+// the extended buffer, for serializers that write into a reused buffer.
+func (f ID) AppendQuotedJSON(dst []byte) []byte {
+	dst = append(dst, '"')
+	dst = strconv.AppendInt(dst, int64(f), 10)
+	return append(dst, '"')
 }
 
 // UnmarshalJSON converts a json byte array of a snowflake ID into an ID type.
+// It accepts the quoted string MarshalJSON produces, in the encoding set by
+// SetJSONEncoding, as well as a bare number, and like encoding/json it
+// leaves the ID unchanged for null.
 func (f *ID) UnmarshalJSON(b []byte) error {
-	i, err := strconv.ParseInt(string(b[1:len(b)-1]), 10, 64)
+	if string(b) == "null" {
+		return nil
+	}
+
+	var (
+		id  ID
+		err error
+	)
+	if len(b) >= 2 && b[0] == '"' && b[len(b)-1] == '"' {
+		id, err = parseJSONString(b[1 : len(b)-1])
+	} else {
+		id, err = parseID(b)
+	}
 	if err != nil {
 		return err
 	}
 
-	*f = ID(i)
+	*f = id
 	return nil
 }
+
+// ErrInvalidID matches, with errors.Is, the errors returned for malformed
+// snowflake IDs by UnmarshalJSON and UnmarshalText.
+var ErrInvalidID = errors.New("invalid snowflake ID")
+
+// An InvalidIDError describes input that could not be unmarshaled as a
+// snowflake ID.  It matches ErrInvalidID with errors.Is.
+type InvalidIDError struct {
+	// Input is the rejected input, without JSON quotes.
+	Input string
+
+	// Err is the reason it was rejected, such as strconv.ErrSyntax or
+	// strconv.ErrRange.
+	Err error
+}
+
+func (e *InvalidIDError) Error() string {
+	return "invalid snowflake ID " + strconv.Quote(e.Input) + ": " + e.Err.Error()
+}
+
+// Is reports whether target is ErrInvalidID.
+func (e *InvalidIDError) Is(target error) bool {
+	return target == ErrInvalidID
+}
+
+// Unwrap returns e.Err.
+func (e *InvalidIDError) Unwrap() error {
+	return e.Err
+}
+
+var errNegativeID = errors.New("negative value")
+
+// parseID parses a non-negative decimal snowflake ID, returning an
+// InvalidIDError for anything else.
+func parseID(b []byte) (ID, error) {
+	i, err := strconv.ParseInt(string(b), 10, 64)
+	if err != nil {
+		if ne, ok := err.(*strconv.NumError); ok {
+			err = ne.Err
+		}
+		return 0, &InvalidIDError{Input: string(b), Err: err}
+	}
+
+	if i < 0 {
+		return 0, &InvalidIDError{Input: string(b), Err: errNegativeID}
+	}
+
+	return ID(i), nil
+}
+
+// A NumericID is a snowflake ID that marshals to JSON as a number rather than
+// a string, for consumers that expect numeric IDs.  JavaScript numbers cannot
+// represent every snowflake ID exactly, so prefer ID for browser clients.
+// Convert with NumericID(id) and ID(n).
+type NumericID ID
+
+// MarshalJSON returns the snowflake ID as a bare JSON number.
+func (f NumericID) MarshalJSON() ([]byte, error) {
+	return strconv.AppendInt(nil, int64(f), 10), nil
+}
+
+// UnmarshalJSON accepts the same input as ID.UnmarshalJSON.
+func (f *NumericID) UnmarshalJSON(b []byte) error {
+	return (*ID)(f).UnmarshalJSON(b)
+}
+
+// MarshalText returns the decimal string of the snowflake ID as a byte array,
+// so IDs can be used as map keys in encoding/json and with other text based
+// encoders.
+func (f ID) MarshalText() ([]byte, error) {
+	return strconv.AppendInt(nil, int64(f), 10), nil
+}
+
+// UnmarshalText converts a decimal string byte array into an ID type.
+func (f *ID) UnmarshalText(b []byte) error {
+	id, err := parseID(b)
+	if err != nil {
+		return err
+	}
+
+	*f = id
+	return nil
+}
+
+// ParseSortedSet parses a JSON array of quoted snowflake IDs, such as
+// ["13587","13586"], and returns the distinct IDs sorted in ascending order.
+// An error describing the offending element is returned if any element is not
+// a valid ID.
+func ParseSortedSet(data []byte) ([]ID, error) {
+	var strs []string
+	if err := json.Unmarshal(data, &strs); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[ID]bool, len(strs))
+	ids := make([]ID, 0, len(strs))
+	for i, str := range strs {
+		v, err := strconv.ParseInt(str, 10, 64)
+		if err != nil || v < 0 {
+			return nil, fmt.Errorf("invalid ID %q at index %d", str, i)
+		}
+
+		if id := ID(v); !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, nil
+}
@@ -1,6 +1,17 @@
 package snowflake
 
-import "testing"
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
 
 func TestGeneratesWithHostname(t *testing.T) {
 	// quick sanity test, nothing too crazy...
@@ -14,6 +25,65 @@ func TestGeneratesWithHostname(t *testing.T) {
 	}
 }
 
+func TestParseEncodings(t *testing.T) {
+	node, _ := NewNode(1)
+	id := node.Generate()
+
+	for _, tc := range []struct {
+		name  string
+		parse func() (ID, error)
+	}{
+		{"String", func() (ID, error) { return ParseString(id.String()) }},
+		{"Base2", func() (ID, error) { return ParseBase2(id.Base2()) }},
+		{"Base36", func() (ID, error) { return ParseBase36(id.Base36()) }},
+		{"Base64", func() (ID, error) { return ParseBase64(id.Base64()) }},
+		{"Base64URL", func() (ID, error) { return ParseBase64URL(id.Base64URL()) }},
+		{"Base64Raw", func() (ID, error) { return ParseBase64Raw(id.Base64Raw()) }},
+		{"Bytes", func() (ID, error) { return ParseBytes(id.Bytes()) }},
+		{"Int64", func() (ID, error) { return ParseInt64(id.Int64()), nil }},
+		{"Uint64", func() (ID, error) { return ParseUint64(id.Uint64()), nil }},
+	} {
+		got, err := tc.parse()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+		} else if got != id {
+			t.Errorf("%s: got %d, expected %d", tc.name, got, id)
+		}
+	}
+
+	for _, tc := range []struct {
+		name  string
+		parse func(string) (ID, error)
+		input string
+	}{
+		{"String", ParseString, "12a"},
+		{"String", ParseString, "9223372036854775808"},
+		{"Base2", ParseBase2, "102"},
+		{"Base36", ParseBase36, "1y2p0ij32e8e8"},
+		{"Base64", ParseBase64, "not base64"},
+		{"Base64", ParseBase64, "YWJj"},
+		{"Base64URL", ParseBase64URL, "AAAAAAAAAA="},
+		{"Base64URL", ParseBase64URL, "AAAAAAAAA"},
+		{"Base64URL", ParseBase64URL, "AAAAAAAAA+A"},
+		{"Base64Raw", ParseBase64Raw, "AAAAAAAAA_A"},
+	} {
+		if _, err := tc.parse(tc.input); err == nil {
+			t.Errorf("%s: expected an error parsing %q", tc.name, tc.input)
+		}
+	}
+}
+
+func TestBase64URL(t *testing.T) {
+	id := ID(0x0FFBFF0000000001)
+
+	if got := id.Base64URL(); got != "D_v_AAAAAAE" {
+		t.Errorf("Got %q, expected D_v_AAAAAAE", got)
+	}
+	if got := id.Base64Raw(); got != "D/v/AAAAAAE" {
+		t.Errorf("Got %q, expected D/v/AAAAAAE", got)
+	}
+}
+
 func TestMarshalJSON(t *testing.T) {
 	id := ID(13587)
 	expected := "\"13587\""
@@ -28,6 +98,30 @@ func TestMarshalJSON(t *testing.T) {
 	}
 }
 
+func TestAppend(t *testing.T) {
+	id := ID(1417819914141990912)
+	prefix := []byte("id=")
+
+	if got := string(id.AppendString(prefix)); got != "id="+id.String() {
+		t.Errorf("Got %s, expected id=%s", got, id)
+	}
+	if got := string(id.AppendBase36(prefix)); got != "id="+id.Base36() {
+		t.Errorf("Got %s, expected id=%s", got, id.Base36())
+	}
+
+	json, _ := id.MarshalJSON()
+	if got := string(id.AppendQuotedJSON(prefix)); got != "id="+string(json) {
+		t.Errorf("Got %s, expected id=%s", got, json)
+	}
+
+	buf := make([]byte, 0, 64)
+	if allocs := testing.AllocsPerRun(100, func() {
+		buf = id.AppendQuotedJSON(id.AppendBase36(id.AppendString(buf[:0])))
+	}); allocs != 0 {
+		t.Errorf("Got %v allocations, expected none", allocs)
+	}
+}
+
 func TestUnmarshalJSON(t *testing.T) {
 	strID := "\"13587\""
 	expected := ID(13587)
@@ -43,6 +137,210 @@ func TestUnmarshalJSON(t *testing.T) {
 	}
 }
 
+func TestUnmarshalJSONForms(t *testing.T) {
+	for _, tc := range []struct {
+		input    string
+		expected ID
+	}{
+		{`"13587"`, 13587},
+		{`13587`, 13587},
+		{`null`, 42},
+	} {
+		id := ID(42)
+		if err := id.UnmarshalJSON([]byte(tc.input)); err != nil || id != tc.expected {
+			t.Errorf("Got (%d, %v) unmarshaling %s, expected (%d, nil)", id, err, tc.input, tc.expected)
+		}
+	}
+
+	for _, bad := range []string{``, `"`, `""`, `"5`, `5"`, `"12a"`, `1.5`, `true`, `"null"`, `"-5"`, `-5`, `"9223372036854775808"`} {
+		id := ID(42)
+		err := id.UnmarshalJSON([]byte(bad))
+		if !errors.Is(err, ErrInvalidID) {
+			t.Errorf("Got %v unmarshaling %q, expected ErrInvalidID", err, bad)
+		}
+		if id != 42 {
+			t.Errorf("Got %d after failing to unmarshal %q, expected it unchanged", id, bad)
+		}
+	}
+
+	var invalid *InvalidIDError
+	err := new(ID).UnmarshalJSON([]byte(`"9223372036854775808"`))
+	if !errors.As(err, &invalid) || invalid.Input != "9223372036854775808" || !errors.Is(err, strconv.ErrRange) {
+		t.Errorf("Got %v, expected an InvalidIDError wrapping strconv.ErrRange", err)
+	}
+
+	var v struct {
+		ID  ID  `json:"id"`
+		Ptr *ID `json:"ptr"`
+	}
+	if err := json.Unmarshal([]byte(`{"id":13587,"ptr":null}`), &v); err != nil || v.ID != 13587 || v.Ptr != nil {
+		t.Errorf("Got (%+v, %v), expected id 13587 and a nil ptr", v, err)
+	}
+}
+
+func TestNumericID(t *testing.T) {
+	v := struct {
+		ID NumericID `json:"id"`
+	}{NumericID(13587)}
+
+	b, err := json.Marshal(v)
+	if err != nil || string(b) != `{"id":13587}` {
+		t.Errorf("Got (%s, %v), expected {\"id\":13587}", b, err)
+	}
+
+	for _, input := range []string{`{"id":13588}`, `{"id":"13588"}`} {
+		if err := json.Unmarshal([]byte(input), &v); err != nil || ID(v.ID) != 13588 {
+			t.Errorf("Got (%d, %v) unmarshaling %s, expected (13588, nil)", v.ID, err, input)
+		}
+	}
+}
+
+func TestWithLock(t *testing.T) {
+	node, _ := NewNode(1)
+
+	var ids []ID
+	node.WithLock(func(gen func() ID) {
+		for i := 0; i < 100; i++ {
+			ids = append(ids, gen())
+		}
+	})
+
+	for i := 1; i < len(ids); i++ {
+		if ids[i] <= ids[i-1] {
+			t.Fatalf("ID %d is not greater than the ID before it", i)
+		}
+
+		if ids[i].Time() == ids[i-1].Time() && ids[i].Step() != ids[i-1].Step()+1 {
+			t.Errorf("ID %d is not contiguous with the ID before it", i)
+		}
+	}
+}
+
+func TestGuessEpoch(t *testing.T) {
+	const epoch int64 = 1420070400000
+	created := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	id := ID((created.UnixNano()/1000000-epoch)<<timeShift | 5<<nodeShift | 9)
+	if e := GuessEpoch(id, created); e != epoch {
+		t.Errorf("Got epoch %d, expected %d", e, epoch)
+	}
+}
+
+func TestTimeWindow(t *testing.T) {
+	node, _ := NewNode(1)
+
+	before := time.Now()
+	id := node.Generate()
+	after := time.Now()
+
+	start, end := id.TimeWindow()
+	if end.Sub(start) != time.Millisecond {
+		t.Errorf("Got a window of %s, expected 1ms", end.Sub(start))
+	}
+
+	if start.After(after) || !end.After(before.Truncate(time.Millisecond)) {
+		t.Errorf("Window [%s, %s) does not contain the generation time", start, end)
+	}
+}
+
+func TestDebugString(t *testing.T) {
+	node, _ := NewNode(42)
+
+	id := node.Generate()
+	str := id.DebugString()
+
+	if !strings.HasSuffix(str, "-n42-s"+strconv.FormatInt(id.Step(), 10)) {
+		t.Errorf("Got %s, expected node and step suffix", str)
+	}
+
+	parsed, err := ParseDebugString(str)
+	if err != nil {
+		t.Fatalf("Unexpected error parsing %s: %v", str, err)
+	}
+
+	if parsed != id {
+		t.Errorf("Got %d, expected %d", parsed, id)
+	}
+
+	for _, bad := range []string{"", "20231114T153000.123", "20231114T153000.123-n42", "20231114T153000.123-n2000-s1", "20231114T153000.123-n1-s5000", "2023-11-14-n1-s1"} {
+		if _, err := ParseDebugString(bad); err == nil {
+			t.Errorf("Expected an error parsing %q", bad)
+		}
+	}
+}
+
+func TestMarshalText(t *testing.T) {
+	id := ID(13587)
+
+	b, err := id.MarshalText()
+	if err != nil || string(b) != "13587" {
+		t.Errorf("Got (%s, %v), expected (13587, nil)", b, err)
+	}
+
+	var parsed ID
+	if err := parsed.UnmarshalText([]byte("13587")); err != nil || parsed != id {
+		t.Errorf("Got (%d, %v), expected (%d, nil)", parsed, err, id)
+	}
+
+	for _, bad := range []string{"x", "-1"} {
+		if err := parsed.UnmarshalText([]byte(bad)); !errors.Is(err, ErrInvalidID) {
+			t.Errorf("Got %v unmarshaling %q, expected ErrInvalidID", err, bad)
+		}
+	}
+
+	m := map[ID]string{id: "a"}
+	data, err := json.Marshal(m)
+	if err != nil || string(data) != `{"13587":"a"}` {
+		t.Fatalf("Got (%s, %v) marshaling a map", data, err)
+	}
+
+	var back map[ID]string
+	if err := json.Unmarshal(data, &back); err != nil || back[id] != "a" {
+		t.Errorf("Got (%v, %v) unmarshaling a map", back, err)
+	}
+}
+
+func TestParseSortedSet(t *testing.T) {
+	ids, err := ParseSortedSet([]byte(`["30","10","20","10","30"]`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []ID{10, 20, 30}
+	if !reflect.DeepEqual(ids, expected) {
+		t.Errorf("Got %v, expected %v", ids, expected)
+	}
+
+	for _, bad := range []string{`["10","x"]`, `["-1"]`, `[10]`, `{}`} {
+		if _, err := ParseSortedSet([]byte(bad)); err == nil {
+			t.Errorf("Expected an error parsing %s", bad)
+		}
+	}
+}
+
+func maxOf[T cmp.Ordered](s []T) T {
+	m := s[0]
+	for _, v := range s[1:] {
+		if v > m {
+			m = v
+		}
+	}
+	return m
+}
+
+func TestOrdered(t *testing.T) {
+	node, _ := NewNode(1)
+
+	ids := []ID{node.Generate(), node.Generate(), node.Generate()}
+	if m := maxOf(ids); m != ids[2] {
+		t.Errorf("Got %d, expected %d", m, ids[2])
+	}
+
+	if !Less(ids[0], ids[1]) || Less(ids[1], ids[0]) {
+		t.Error("Expected Less to order IDs by generation")
+	}
+}
+
 func BenchmarkGenerate(b *testing.B) {
 
 	node, _ := NewNode(1)
@@ -55,6 +353,43 @@ func BenchmarkGenerate(b *testing.B) {
 	}
 }
 
+// BenchmarkGeneratePaths compares the locked, unlocked and lock free paths
+// on a single goroutine.  It uses contentionLayout so the cost of the paths
+// is not hidden behind step exhaustion waits.
+func BenchmarkGeneratePaths(b *testing.B) {
+	for _, bc := range []struct {
+		name string
+		opts []Option
+		gen  func(*Node) ID
+	}{
+		{"Locked", []Option{withMutex()}, (*Node).Generate},
+		{"Unlocked", []Option{withMutex()}, (*Node).GenerateUnlocked},
+		{"Atomic", []Option{WithLockFree()}, (*Node).Generate},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			node, _ := NewNode(1, append(bc.opts, WithLayout(contentionLayout))...)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				_ = bc.gen(node)
+			}
+		})
+	}
+}
+
+func BenchmarkGenerateN(b *testing.B) {
+
+	node, _ := NewNode(1)
+
+	b.ReportAllocs()
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n += 1000 {
+		_ = node.GenerateN(1000)
+	}
+}
+
 func BenchmarkUnmarshal(b *testing.B) {
 	// Generate the ID to unmarshal
 	node, _ := NewNode(1)
@@ -81,3 +416,313 @@ func BenchmarkMarshal(b *testing.B) {
 		_, _ = id.MarshalJSON()
 	}
 }
+
+func BenchmarkAppendQuotedJSON(b *testing.B) {
+	node, _ := NewNode(1)
+	id := node.Generate()
+	buf := make([]byte, 0, 22)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		buf = id.AppendQuotedJSON(buf[:0])
+	}
+}
+
+func TestHostnameNodeID(t *testing.T) {
+	id, err := HostnameNodeID()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	node, err := NewNodeByHostname()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if node.Number() != id {
+		t.Errorf("Got node number %d, expected %d", node.Number(), id)
+	}
+}
+
+func TestWithUniqueCheck(t *testing.T) {
+	inUse := map[int64]bool{7: true}
+	probe := func(node int64) bool { return !inUse[node] }
+
+	if _, err := NewNode(7, WithUniqueCheck(probe)); err != ErrNodeCollision {
+		t.Errorf("Got error %v, expected ErrNodeCollision", err)
+	}
+
+	node, err := NewNode(8, WithUniqueCheck(probe))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if node.Number() != 8 {
+		t.Errorf("Got node number %d, expected 8", node.Number())
+	}
+}
+
+func TestIntBytes(t *testing.T) {
+	id := ID(0x0102030405060708)
+
+	b := id.IntBytes()
+	if expected := [8]byte{1, 2, 3, 4, 5, 6, 7, 8}; b != expected {
+		t.Errorf("Got %v, expected %v", b, expected)
+	}
+
+	if got := ParseIntBytes(b); got != id {
+		t.Errorf("Got %d, expected %d", got, id)
+	}
+
+	bin, err := id.MarshalBinary()
+	if err != nil || string(bin) != string(b[:]) {
+		t.Errorf("Got (%v, %v), expected (%v, nil)", bin, err, b)
+	}
+
+	var got ID
+	if err := got.UnmarshalBinary(bin); err != nil || got != id {
+		t.Errorf("Got (%d, %v), expected (%d, nil)", got, err, id)
+	}
+
+	if err := got.UnmarshalBinary(bin[:7]); err == nil {
+		t.Error("Expected an error for a short value")
+	}
+
+	// Big endian bytes sort in the same order as the IDs.
+	node, _ := NewNode(1)
+	x, y := node.Generate().IntBytes(), node.Generate().IntBytes()
+	if string(x[:]) >= string(y[:]) {
+		t.Errorf("Expected %v to sort before %v", x, y)
+	}
+}
+
+func TestGenerateSafe(t *testing.T) {
+	now := nowMillis()
+	clock := WithClock(ClockFunc(func() int64 { return now }))
+
+	node, err := NewNode(1, clock)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if id, err := node.GenerateSafe(); err != nil || id.Time() != now {
+		t.Errorf("Got (%d, %v), expected an ID at %d", id, err, now)
+	}
+
+	// 41 time bits run out 2^41 milliseconds after the epoch.
+	now = Epoch + 1<<41
+	if _, err := node.GenerateSafe(); err != ErrTimestampOverflow {
+		t.Errorf("Got error %v, expected ErrTimestampOverflow", err)
+	}
+
+	now = Epoch - 1
+	if _, err := node.GenerateSafe(); err != ErrEpochInFuture {
+		t.Errorf("Got error %v, expected ErrEpochInFuture", err)
+	}
+
+	if _, err := NewNode(1, WithEpoch(nowMillis()+time.Hour.Milliseconds())); err != ErrEpochInFuture {
+		t.Errorf("Got error %v, expected ErrEpochInFuture", err)
+	}
+}
+
+func TestGenerateCtx(t *testing.T) {
+	small := WithLayout(Layout{NodeBits: 10, StepBits: 2})
+
+	for _, opts := range [][]Option{{small, withMutex()}, {small}} {
+		node, _ := NewNode(1, opts...)
+
+		// Four steps a millisecond make GenerateCtx wait for later ones.
+		var last ID
+		for i := 0; i < 20; i++ {
+			id, err := node.GenerateCtx(context.Background())
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if id <= last {
+				t.Fatalf("ID %d is not greater than %d", id, last)
+			}
+			last = id
+		}
+
+		now := nowMillis()
+		frozen, _ := NewNode(1, append(opts, WithClock(ClockFunc(func() int64 { return now })))...)
+		for i := 0; i < 4; i++ {
+			if _, err := frozen.GenerateCtx(context.Background()); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		if _, err := frozen.GenerateCtx(ctx); err != context.DeadlineExceeded {
+			t.Errorf("Got error %v, expected context.DeadlineExceeded", err)
+		}
+		cancel()
+
+		ctx, cancel = context.WithCancel(context.Background())
+		cancel()
+		if _, err := frozen.GenerateCtx(ctx); err != context.Canceled {
+			t.Errorf("Got error %v, expected context.Canceled", err)
+		}
+	}
+}
+
+func TestGenerateN(t *testing.T) {
+	node, _ := NewNode(1)
+
+	before := node.Generate()
+	ids := node.GenerateN(10000)
+	after := node.Generate()
+
+	if len(ids) != 10000 {
+		t.Fatalf("Got %d IDs, expected 10000", len(ids))
+	}
+
+	last := before
+	for _, id := range ids {
+		if id <= last {
+			t.Fatalf("ID %d is not greater than %d", id, last)
+		}
+		last = id
+	}
+
+	if after <= last {
+		t.Errorf("Generated ID %d is not greater than the batch's last %d", after, last)
+	}
+
+	if c := node.TakeCount(); c != 10002 {
+		t.Errorf("Got a count of %d, expected 10002", c)
+	}
+
+	if ids := node.GenerateN(0); len(ids) != 0 {
+		t.Errorf("Got %d IDs, expected none", len(ids))
+	}
+}
+
+func TestTakeCount(t *testing.T) {
+	node, _ := NewNode(1)
+
+	const workers, perWorker = 8, 5000
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < perWorker; j++ {
+				node.Generate()
+			}
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	var total uint64
+	for running := true; running; {
+		select {
+		case <-done:
+			running = false
+		case <-time.After(time.Millisecond):
+		}
+		total += node.TakeCount()
+	}
+
+	if total != workers*perWorker {
+		t.Errorf("Got a total of %d, expected %d", total, workers*perWorker)
+	}
+
+	if c := node.TakeCount(); c != 0 {
+		t.Errorf("Got %d after reset, expected 0", c)
+	}
+}
+
+func TestWithEpoch(t *testing.T) {
+	const discord int64 = 1420070400000
+
+	a, _ := NewNode(1, WithEpoch(discord))
+	b, _ := NewNode(1)
+
+	if a.Epoch() != discord || b.Epoch() != Epoch {
+		t.Errorf("Got epochs %d and %d, expected %d and %d", a.Epoch(), b.Epoch(), discord, Epoch)
+	}
+
+	now := time.Now()
+	for _, n := range []*Node{a, b} {
+		p := n.Decode(n.Generate())
+		if d := p.Time.Sub(now); d < -time.Second || d > time.Second {
+			t.Errorf("Got time %s for epoch %d, expected close to %s", p.Time, n.Epoch(), now)
+		}
+	}
+
+	if ida, idb := a.Generate(), b.Generate(); ida >= idb {
+		t.Errorf("Expected an ID with the later epoch %d to be smaller than %d", ida, idb)
+	}
+}
+
+func TestTimeMicros(t *testing.T) {
+	node, _ := NewNode(1)
+
+	id := node.Generate()
+	if id.TimeMicros() != id.Time()*1000 {
+		t.Errorf("Got %d, expected %d", id.TimeMicros(), id.Time()*1000)
+	}
+	if got := node.IDTimeMicros(id); got != id.TimeMicros() {
+		t.Errorf("Got %d from the node, expected %d", got, id.TimeMicros())
+	}
+
+	created := time.Date(2030, 1, 1, 0, 0, 0, 123456000, time.UTC)
+	micro, _ := NewNode(1, WithLayout(MicrosecondLayout), WithClock(&nanoClock{ns: created.UnixNano()}))
+	if got := micro.IDTimeMicros(micro.Generate()); got != created.UnixMicro() {
+		t.Errorf("Got %d with MicrosecondLayout, expected %d", got, created.UnixMicro())
+	}
+}
+
+func TestGenerateUnlocked(t *testing.T) {
+	for _, opts := range [][]Option{{withMutex()}, nil} {
+		node, _ := NewNode(3, opts...)
+
+		var last ID
+		for i := 0; i < 10000; i++ {
+			id := node.GenerateUnlocked()
+			if id <= last {
+				t.Fatalf("ID %d is not greater than %d", id, last)
+			}
+			last = id
+		}
+
+		if id := node.Generate(); id <= last || id.Node() != 3 {
+			t.Errorf("Got %d after GenerateUnlocked, expected an ID of node 3 greater than %d", id, last)
+		}
+	}
+}
+
+func TestGenerateNCtx(t *testing.T) {
+	var hooked int
+	node, _ := NewNode(1, WithHook(func(ID) { hooked++ }))
+
+	ids, err := node.GenerateNCtx(context.Background(), 5)
+	if err != nil || len(ids) != 5 {
+		t.Fatalf("Got %d IDs and error %v, expected 5 IDs", len(ids), err)
+	}
+	for i := 1; i < len(ids); i++ {
+		if ids[i] <= ids[i-1] {
+			t.Errorf("ID %d is not greater than %d", ids[i], ids[i-1])
+		}
+	}
+	if hooked != 5 {
+		t.Errorf("Got %d hook calls, expected 5", hooked)
+	}
+
+	if _, err := node.GenerateNCtx(context.Background(), -1); err == nil {
+		t.Error("Expected an error for a negative count")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := node.GenerateNCtx(ctx, 1); err != context.Canceled {
+		t.Errorf("Got %v with a cancelled context, expected context.Canceled", err)
+	}
+}
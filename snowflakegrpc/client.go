@@ -0,0 +1,65 @@
+package snowflakegrpc
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/bwmarrin/snowflake"
+)
+
+// A BatchSource returns blocks of IDs, typically by calling the
+// GenerateBatch RPC.  Service is a BatchSource too.
+type BatchSource interface {
+	GenerateBatch(ctx context.Context, count int) ([]snowflake.ID, error)
+}
+
+// ErrEmptyBatch is returned by CachingClient.Generate when its BatchSource
+// returns no IDs and no error.
+var ErrEmptyBatch = errors.New("batch source returned no IDs")
+
+// A CachingClient hands out IDs from blocks reserved from a BatchSource, so
+// only one call in every block size IDs goes over the network.  It is safe
+// for concurrent use.
+//
+// IDs from a block keep the time they were reserved at, so a cached ID can
+// be older than the IDs generated since, and IDs left in the cache when the
+// process exits are never used.
+type CachingClient struct {
+	src  BatchSource
+	size int
+
+	mu  sync.Mutex
+	ids []snowflake.ID
+}
+
+// NewCachingClient returns a CachingClient reserving blockSize IDs at a time
+// from src.
+func NewCachingClient(src BatchSource, blockSize int) *CachingClient {
+	if blockSize < 1 {
+		blockSize = 1
+	}
+	return &CachingClient{src: src, size: blockSize}
+}
+
+// Generate returns the next cached ID, reserving a new block when the cache
+// is empty.
+func (c *CachingClient) Generate(ctx context.Context) (snowflake.ID, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.ids) == 0 {
+		ids, err := c.src.GenerateBatch(ctx, c.size)
+		if err != nil {
+			return 0, err
+		}
+		if len(ids) == 0 {
+			return 0, ErrEmptyBatch
+		}
+		c.ids = ids
+	}
+
+	id := c.ids[0]
+	c.ids = c.ids[1:]
+	return id, nil
+}
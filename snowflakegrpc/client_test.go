@@ -0,0 +1,72 @@
+package snowflakegrpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bwmarrin/snowflake"
+)
+
+// countingSource records how many batches were requested.
+type countingSource struct {
+	BatchSource
+	calls int
+	err   error
+}
+
+func (s *countingSource) GenerateBatch(ctx context.Context, count int) ([]snowflake.ID, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.BatchSource.GenerateBatch(ctx, count)
+}
+
+func TestCachingClient(t *testing.T) {
+	node, _ := snowflake.NewNode(4)
+	src := &countingSource{BatchSource: NewService(node)}
+	c := NewCachingClient(src, 10)
+	ctx := context.Background()
+
+	var last snowflake.ID
+	for i := 0; i < 25; i++ {
+		id, err := c.Generate(ctx)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if id <= last {
+			t.Fatalf("ID %d is not greater than %d", id, last)
+		}
+		last = id
+	}
+
+	if src.calls != 3 {
+		t.Errorf("Got %d batch calls, expected 3", src.calls)
+	}
+
+	down := errors.New("unavailable")
+	src.err = down
+	for i := 0; i < 5; i++ {
+		if _, err := c.Generate(ctx); err != nil {
+			t.Fatalf("Unexpected error while IDs are cached: %v", err)
+		}
+	}
+	if _, err := c.Generate(ctx); err != down {
+		t.Errorf("Got error %v, expected %v", err, down)
+	}
+}
+
+// emptySource returns no IDs and no error.
+type emptySource struct{}
+
+func (emptySource) GenerateBatch(ctx context.Context, count int) ([]snowflake.ID, error) {
+	return nil, nil
+}
+
+func TestCachingClientEmptyBatch(t *testing.T) {
+	c := NewCachingClient(emptySource{}, 10)
+	if _, err := c.Generate(context.Background()); err != ErrEmptyBatch {
+		t.Errorf("Got error %v from an empty batch, expected ErrEmptyBatch", err)
+	}
+}
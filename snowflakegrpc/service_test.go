@@ -0,0 +1,58 @@
+package snowflakegrpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bwmarrin/snowflake"
+)
+
+func TestService(t *testing.T) {
+	node, _ := snowflake.NewNode(4)
+	s := NewService(node)
+	ctx := context.Background()
+
+	id, err := s.Generate(ctx)
+	if err != nil || id.Node() != 4 {
+		t.Fatalf("Got (%d, %v), expected an ID from node 4", id, err)
+	}
+
+	ids, err := s.GenerateBatch(ctx, 100)
+	if err != nil || len(ids) != 100 || ids[0] <= id {
+		t.Fatalf("Got %d IDs and error %v, expected 100 IDs after %d", len(ids), err, id)
+	}
+
+	for _, count := range []int{0, s.MaxBatch + 1} {
+		if _, err := s.GenerateBatch(ctx, count); err == nil {
+			t.Errorf("Expected an error for a batch of %d", count)
+		}
+	}
+
+	p, err := s.Decode(ctx, id)
+	if err != nil || p != node.Decode(id) {
+		t.Errorf("Got (%+v, %v), expected (%+v, nil)", p, err, node.Decode(id))
+	}
+	if _, err := s.Decode(ctx, -1); err == nil {
+		t.Error("Expected an error for a negative ID")
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := s.Generate(cancelled); err != context.Canceled {
+		t.Errorf("Got error %v, expected context.Canceled", err)
+	}
+}
+
+func TestServiceClosed(t *testing.T) {
+	node, _ := snowflake.NewNode(4)
+	s := NewService(node)
+	node.Close()
+
+	ctx := context.Background()
+	if _, err := s.Generate(ctx); err != snowflake.ErrClosed {
+		t.Errorf("Got error %v from Generate, expected ErrClosed", err)
+	}
+	if _, err := s.GenerateBatch(ctx, 10); err != snowflake.ErrClosed {
+		t.Errorf("Got error %v from GenerateBatch, expected ErrClosed", err)
+	}
+}
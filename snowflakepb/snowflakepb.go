@@ -0,0 +1,103 @@
+// Package snowflakepb lets snowflake IDs be used directly in gogo/protobuf
+// messages as a custom type, without importing any protobuf library.
+//
+// The simplest mapping needs no custom type: declare the field as uint64 or
+// fixed64 and convert with snowflake.ID.Uint64 and snowflake.ParseUint64.
+// fixed64 is cheaper for IDs, which are large numbers and would take 9 bytes
+// as a varint.
+//
+// To use the ID type in generated gogo structs instead, declare a bytes field
+// with the customtype option, which stores the ID as 8 big endian bytes:
+//
+//	import "github.com/gogo/protobuf/gogoproto/gogo.proto";
+//
+//	message User {
+//	  bytes id = 1 [
+//	    (gogoproto.customtype) = "github.com/bwmarrin/snowflake/snowflakepb.ID",
+//	    (gogoproto.nullable) = false
+//	  ];
+//	}
+//
+// See example.proto for both mappings.
+package snowflakepb
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/bwmarrin/snowflake"
+)
+
+// ID is a snowflake ID implementing the gogo/protobuf custom type methods.
+type ID snowflake.ID
+
+// ErrInvalidLength is returned by Unmarshal when the data is not 8 bytes.
+var ErrInvalidLength = errors.New("snowflakepb: ID must be 8 bytes")
+
+// Snowflake returns the ID as a snowflake.ID.
+func (id ID) Snowflake() snowflake.ID {
+	return snowflake.ID(id)
+}
+
+// Marshal returns the ID as 8 big endian bytes.
+func (id ID) Marshal() ([]byte, error) {
+	b := snowflake.ID(id).IntBytes()
+	return b[:], nil
+}
+
+// MarshalTo writes the ID as 8 big endian bytes to data, which must be at
+// least Size bytes long, and returns the number of bytes written.
+func (id ID) MarshalTo(data []byte) (int, error) {
+	if len(data) < 8 {
+		return 0, ErrInvalidLength
+	}
+
+	binary.BigEndian.PutUint64(data, uint64(id))
+	return 8, nil
+}
+
+// Unmarshal sets the ID from 8 big endian bytes.  Empty data, as sent for an
+// unset proto3 field, sets the ID to zero.
+func (id *ID) Unmarshal(data []byte) error {
+	switch len(data) {
+	case 0:
+		*id = 0
+	case 8:
+		*id = ID(binary.BigEndian.Uint64(data))
+	default:
+		return ErrInvalidLength
+	}
+	return nil
+}
+
+// Size returns the number of bytes Marshal produces.
+func (id ID) Size() int {
+	return 8
+}
+
+// MarshalJSON returns the ID as a JSON string, as snowflake.ID does.
+func (id ID) MarshalJSON() ([]byte, error) {
+	return snowflake.ID(id).MarshalJSON()
+}
+
+// UnmarshalJSON sets the ID from a JSON string, as snowflake.ID does.
+func (id *ID) UnmarshalJSON(data []byte) error {
+	return (*snowflake.ID)(id).UnmarshalJSON(data)
+}
+
+// Equal reports whether the IDs are equal, for gogo's generated Equal methods.
+func (id ID) Equal(other ID) bool {
+	return id == other
+}
+
+// Compare returns -1, 0 or 1 as the ID sorts before, the same as or after
+// other, for gogo's generated Compare methods.
+func (id ID) Compare(other ID) int {
+	switch {
+	case id < other:
+		return -1
+	case id > other:
+		return 1
+	}
+	return 0
+}
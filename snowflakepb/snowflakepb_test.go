@@ -0,0 +1,71 @@
+package snowflakepb
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/snowflake"
+)
+
+func TestMarshalUnmarshal(t *testing.T) {
+	node, _ := snowflake.NewNode(1)
+	id := ID(node.Generate())
+
+	b, err := id.Marshal()
+	if err != nil || len(b) != id.Size() {
+		t.Fatalf("Got (%v, %v), expected %d bytes", b, err, id.Size())
+	}
+
+	buf := make([]byte, 10)
+	if n, err := id.MarshalTo(buf); err != nil || n != 8 || string(buf[:8]) != string(b) {
+		t.Errorf("Got (%d, %v) writing %v, expected (8, nil) writing %v", n, err, buf[:8], b)
+	}
+
+	if _, err := id.MarshalTo(buf[:7]); err != ErrInvalidLength {
+		t.Errorf("Got error %v, expected ErrInvalidLength", err)
+	}
+
+	var got ID
+	if err := got.Unmarshal(b); err != nil || got != id {
+		t.Errorf("Got (%d, %v), expected (%d, nil)", got, err, id)
+	}
+	if got.Snowflake() != snowflake.ID(id) {
+		t.Errorf("Got %d, expected %d", got.Snowflake(), id)
+	}
+
+	if err := got.Unmarshal(nil); err != nil || got != 0 {
+		t.Errorf("Got (%d, %v) for empty data, expected (0, nil)", got, err)
+	}
+
+	if err := got.Unmarshal(b[:3]); err != ErrInvalidLength {
+		t.Errorf("Got error %v, expected ErrInvalidLength", err)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	id := ID(13587)
+
+	b, err := id.MarshalJSON()
+	if err != nil || string(b) != `"13587"` {
+		t.Fatalf("Got (%s, %v), expected (\"13587\", nil)", b, err)
+	}
+
+	var got ID
+	if err := got.UnmarshalJSON(b); err != nil || got != id {
+		t.Errorf("Got (%d, %v), expected (%d, nil)", got, err, id)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	if !ID(3).Equal(3) || ID(3).Equal(4) {
+		t.Error("Equal does not compare IDs")
+	}
+
+	for _, tc := range []struct {
+		a, b ID
+		want int
+	}{{1, 2, -1}, {2, 2, 0}, {3, 2, 1}} {
+		if got := tc.a.Compare(tc.b); got != tc.want {
+			t.Errorf("Got %d comparing %d to %d, expected %d", got, tc.a, tc.b, tc.want)
+		}
+	}
+}
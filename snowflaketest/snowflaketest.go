@@ -0,0 +1,150 @@
+// Package snowflaketest provides deterministic snowflake generators for
+// tests, so that tests can assert exact ID values and ordering, and golden
+// files containing IDs stay stable.
+//
+// NewNode returns a real snowflake.Node driven by a Clock that only moves
+// when the test moves it:
+//
+//	node, clock := snowflaketest.NewNode(t, 1, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+//	first := node.Generate()  // step 0 of 2024-01-01T00:00:00Z
+//	clock.Advance(time.Millisecond)
+//
+// A Sequence instead returns a scripted list of IDs, for code that only
+// needs some snowflake.Generator.
+package snowflaketest
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+)
+
+// A Clock is a snowflake.Clock that reports a time set by the test.  It is
+// safe for concurrent use.
+type Clock struct {
+	ms   atomic.Int64
+	tick atomic.Int64
+}
+
+// NewClock returns a Clock stopped at t.
+func NewClock(t time.Time) *Clock {
+	c := &Clock{}
+	c.Set(t)
+	return c
+}
+
+// Now returns the clock's time in unix milliseconds, then advances it by the
+// duration set with SetTick.
+func (c *Clock) Now() int64 {
+	tick := c.tick.Load()
+	return c.ms.Add(tick) - tick
+}
+
+// Time returns the clock's time without advancing it.
+func (c *Clock) Time() time.Time {
+	return time.UnixMilli(c.ms.Load())
+}
+
+// Set moves the clock to t, which may be before its current time to simulate
+// the clock stepping backwards.
+func (c *Clock) Set(t time.Time) {
+	c.ms.Store(t.UnixMilli())
+}
+
+// Advance moves the clock forwards by d, or backwards if d is negative.
+func (c *Clock) Advance(d time.Duration) {
+	c.ms.Add(int64(d / time.Millisecond))
+}
+
+// SetTick makes every call to Now advance the clock by d afterwards, so code
+// that waits for the clock, such as a node whose step is exhausted, makes
+// progress.  The default of zero keeps the clock stopped.
+func (c *Clock) SetTick(d time.Duration) {
+	c.tick.Store(int64(d / time.Millisecond))
+}
+
+// NewNode returns a snowflake.Node for node number node whose clock starts
+// stopped at start, along with the clock.  opts are applied after the clock,
+// so they may change the layout or epoch but should not replace the clock.
+// It fails the test if the node cannot be created.
+func NewNode(tb testing.TB, node int64, start time.Time, opts ...snowflake.Option) (*snowflake.Node, *Clock) {
+	tb.Helper()
+
+	c := NewClock(start)
+	n, err := snowflake.NewNode(node, append([]snowflake.Option{snowflake.WithClock(c)}, opts...)...)
+	if err != nil {
+		tb.Fatalf("snowflaketest: creating node %d: %v", node, err)
+	}
+
+	return n, c
+}
+
+// ID returns the snowflake ID with the given time, node and step in the
+// default layout and the package Epoch, for writing expected values.  It
+// fails the test if a field does not fit.
+func ID(tb testing.TB, t time.Time, node, step int64) snowflake.ID {
+	tb.Helper()
+
+	id, err := snowflake.DefaultLayout.Encode(snowflake.Parts{Time: t, Node: node, Step: step}, snowflake.Epoch)
+	if err != nil {
+		tb.Fatalf("snowflaketest: %v", err)
+	}
+	return id
+}
+
+// A Sequence is a snowflake.Generator that returns scripted IDs in order.
+// It is safe for concurrent use.
+type Sequence struct {
+	mu  sync.Mutex
+	ids []snowflake.ID
+	pos int
+
+	// Layout and Epoch are used by Decode.  NewSequence sets them to
+	// snowflake.DefaultLayout and snowflake.Epoch.
+	Layout snowflake.Layout
+	Epoch  int64
+}
+
+// NewSequence returns a Sequence that generates ids in order.
+func NewSequence(ids ...snowflake.ID) *Sequence {
+	return &Sequence{
+		ids:    ids,
+		Layout: snowflake.DefaultLayout,
+		Epoch:  snowflake.Epoch,
+	}
+}
+
+// Generate returns the next scripted ID.  It panics once the script is
+// exhausted, as that means the code under test generated more IDs than the
+// test expected.
+func (s *Sequence) Generate() snowflake.ID {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.pos >= len(s.ids) {
+		panic("snowflaketest: Sequence exhausted after " + strconv.Itoa(len(s.ids)) + " IDs")
+	}
+
+	id := s.ids[s.pos]
+	s.pos++
+	return id
+}
+
+// Decode returns the fields of id according to the sequence's Layout and
+// Epoch.
+func (s *Sequence) Decode(id snowflake.ID) snowflake.Parts {
+	return s.Layout.Decode(id, s.Epoch)
+}
+
+// Remaining returns the number of scripted IDs not yet generated.
+func (s *Sequence) Remaining() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.ids) - s.pos
+}
+
+var _ snowflake.Generator = (*Sequence)(nil)
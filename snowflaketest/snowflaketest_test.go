@@ -0,0 +1,77 @@
+package snowflaketest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+)
+
+var start = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestNewNode(t *testing.T) {
+	node, clock := NewNode(t, 7, start)
+
+	for step := int64(0); step < 3; step++ {
+		if id, expected := node.Generate(), ID(t, start, 7, step); id != expected {
+			t.Errorf("Got %d, expected %d", id, expected)
+		}
+	}
+
+	clock.Advance(5 * time.Millisecond)
+	if id, expected := node.Generate(), ID(t, start.Add(5*time.Millisecond), 7, 0); id != expected {
+		t.Errorf("Got %d, expected %d", id, expected)
+	}
+
+	if got := clock.Time(); !got.Equal(start.Add(5 * time.Millisecond)) {
+		t.Errorf("Got clock time %s, expected %s", got, start.Add(5*time.Millisecond))
+	}
+}
+
+func TestNewNodeOptions(t *testing.T) {
+	l := snowflake.Layout{NodeBits: 4, StepBits: 2}
+	node, clock := NewNode(t, 3, start, snowflake.WithLayout(l))
+
+	// Exhausting the 4 steps waits for the clock, which SetTick moves on.
+	clock.SetTick(time.Millisecond)
+
+	var last snowflake.ID
+	for i := 0; i < 12; i++ {
+		id := node.Generate()
+		if id <= last {
+			t.Fatalf("ID %d is not greater than %d", id, last)
+		}
+		last = id
+	}
+
+	if p := node.Decode(last); p.Node != 3 || p.Time.Before(start) {
+		t.Errorf("Got %+v, expected node 3 after %s", p, start)
+	}
+}
+
+func TestSequence(t *testing.T) {
+	a, b := ID(t, start, 1, 0), ID(t, start.Add(time.Second), 2, 5)
+
+	var gen snowflake.Generator = NewSequence(a, b)
+	if got := gen.Generate(); got != a {
+		t.Errorf("Got %d, expected %d", got, a)
+	}
+	if got := gen.Generate(); got != b {
+		t.Errorf("Got %d, expected %d", got, b)
+	}
+
+	if p := gen.Decode(b); !p.Time.Equal(start.Add(time.Second)) || p.Node != 2 || p.Step != 5 {
+		t.Errorf("Got %+v, expected time %s, node 2 and step 5", p, start.Add(time.Second))
+	}
+
+	if r := gen.(*Sequence).Remaining(); r != 0 {
+		t.Errorf("Got %d remaining, expected 0", r)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected an exhausted Sequence to panic")
+		}
+	}()
+	gen.Generate()
+}
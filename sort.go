@@ -0,0 +1,72 @@
+package snowflake
+
+// Compare returns -1 if a was generated before b, 1 if it was generated after
+// b, and 0 if they are equal, ordering snowflake IDs by their timestamp, then
+// node, then step according to DefaultLayout.  With DefaultLayout this matches
+// ordering the IDs as integers.
+//
+// IDs from nodes with different epochs or layouts are not comparable this
+// way; decode them with their own epoch first, or use Node.Compare for IDs of
+// a node with a custom layout.
+func Compare(a, b ID) int {
+	return compareFields(a.Time(), a.Node(), a.Step(), b.Time(), b.Node(), b.Step())
+}
+
+// Compare is like the package level Compare but decodes a and b according to
+// the node's layout, so that IDs of layouts that place the node below the
+// step, such as SonyflakeLayout, still order by timestamp first.
+func (n *Node) Compare(a, b ID) int {
+	at, an, as := n.layout.fields(n.canonical(a))
+	bt, bn, bs := n.layout.fields(n.canonical(b))
+	return compareFields(at, an, as, bt, bn, bs)
+}
+
+// CompareAcrossEpochs is like Compare for snowflake IDs generated with
+// different epochs, in milliseconds since the unix epoch, such as when
+// merging the ID spaces of two systems.  It orders a and b by their absolute
+// time, then node, then step, assuming DefaultLayout for both.
+func CompareAcrossEpochs(a ID, epochA int64, b ID, epochB int64) int {
+	at := int64(a)>>timeShift + epochA
+	bt := int64(b)>>timeShift + epochB
+	return compareFields(at, a.Node(), a.Step(), bt, b.Node(), b.Step())
+}
+
+func compareFields(at, an, as, bt, bn, bs int64) int {
+	switch {
+	case at != bt:
+		return sign(at < bt)
+	case an != bn:
+		return sign(an < bn)
+	case as != bs:
+		return sign(as < bs)
+	}
+	return 0
+}
+
+func sign(less bool) int {
+	if less {
+		return -1
+	}
+	return 1
+}
+
+// Before reports whether the snowflake ID was generated before o, as ordered
+// by Compare.
+func (f ID) Before(o ID) bool {
+	return Compare(f, o) < 0
+}
+
+// After reports whether the snowflake ID was generated after o, as ordered by
+// Compare.
+func (f ID) After(o ID) bool {
+	return Compare(f, o) > 0
+}
+
+// IDSlice attaches the methods of sort.Interface to []ID, ordering by Compare.
+//
+//	sort.Sort(snowflake.IDSlice(ids))
+type IDSlice []ID
+
+func (s IDSlice) Len() int           { return len(s) }
+func (s IDSlice) Less(i, j int) bool { return Compare(s[i], s[j]) < 0 }
+func (s IDSlice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
@@ -0,0 +1,81 @@
+package snowflake
+
+import (
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestCompare(t *testing.T) {
+	a := ID(100<<timeShift | 5<<nodeShift | 9)
+	b := ID(100<<timeShift | 6<<nodeShift | 0)
+	c := ID(101<<timeShift | 0<<nodeShift | 0)
+
+	for _, tc := range []struct {
+		x, y     ID
+		expected int
+	}{
+		{a, b, -1},
+		{b, a, 1},
+		{b, c, -1},
+		{c, a, 1},
+		{a, a, 0},
+	} {
+		if got := Compare(tc.x, tc.y); got != tc.expected {
+			t.Errorf("Got %d comparing %d and %d, expected %d", got, tc.x, tc.y, tc.expected)
+		}
+	}
+
+	if !a.Before(b) || a.After(b) || !c.After(b) || a.Before(a) || a.After(a) {
+		t.Error("Before and After disagree with Compare")
+	}
+
+	ids := IDSlice{c, a, b}
+	sort.Sort(ids)
+	if ids[0] != a || ids[1] != b || ids[2] != c {
+		t.Errorf("Got %v after sorting, expected [%d %d %d]", ids, a, b, c)
+	}
+}
+
+func TestNodeCompare(t *testing.T) {
+	node, _ := NewNode(0xBEEF, WithLayout(SonyflakeLayout), WithEpoch(SonyflakeEpoch))
+
+	early := ID(7<<24 | 1<<16 | 0xBEEF)
+	late := ID(8<<24 | 0<<16 | 0x0001)
+
+	if node.Compare(early, late) != -1 || node.Compare(late, early) != 1 || node.Compare(late, late) != 0 {
+		t.Errorf("Got %d and %d, expected -1 and 1", node.Compare(early, late), node.Compare(late, early))
+	}
+
+	// With the node in the low bits a later step of a lower node is a larger
+	// integer, but still orders first by node.
+	sameTime := ID(7<<24 | 2<<16 | 0x0001)
+	if node.Compare(sameTime, early) != -1 {
+		t.Errorf("Got %d, expected the lower node to sort first", node.Compare(sameTime, early))
+	}
+}
+
+func TestCompareAcrossEpochs(t *testing.T) {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC).UnixMilli()
+	epochB := DiscordEpoch
+
+	a := ID((at-Epoch)<<timeShift | 5<<nodeShift)
+	b := ID((at-epochB)<<timeShift | 5<<nodeShift)
+
+	if c := CompareAcrossEpochs(a, Epoch, b, epochB); c != 0 {
+		t.Errorf("Got %d comparing the same time, node and step, expected 0", c)
+	}
+
+	// As raw numbers b sorts before a, but it was created a millisecond
+	// later.
+	later := b + 1<<timeShift
+	if later >= a {
+		t.Fatalf("Expected the later ID %d to be numerically smaller than %d", later, a)
+	}
+	if c := CompareAcrossEpochs(a, Epoch, later, epochB); c != -1 {
+		t.Errorf("Got %d, expected -1", c)
+	}
+	if c := CompareAcrossEpochs(later, epochB, a+1, Epoch); c != 1 {
+		t.Errorf("Got %d comparing by time before step, expected 1", c)
+	}
+}
@@ -0,0 +1,46 @@
+package snowflake
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+)
+
+// Scan implements sql.Scanner so IDs can be read directly from bigint, and
+// text, columns.  src may be an int64, or a []byte or string holding the
+// decimal ID.
+func (f *ID) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case int64:
+		*f = ID(v)
+		return nil
+	case []byte:
+		i, err := strconv.ParseInt(string(v), 10, 64)
+		if err != nil {
+			return err
+		}
+		*f = ID(i)
+		return nil
+	case string:
+		i, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return err
+		}
+		*f = ID(i)
+		return nil
+	case nil:
+		return fmt.Errorf("cannot scan NULL into a snowflake ID")
+	}
+
+	return fmt.Errorf("cannot scan %T into a snowflake ID", src)
+}
+
+// Value implements driver.Valuer, storing the ID as an int64.  Drivers such
+// as pgx send it as an int8 to bigint columns and as its decimal text to
+// varchar columns.  The package does not register a pgx codec, so that it
+// does not depend on pgx; pgx uses Value and Scan for IDs instead.  Bulk
+// loads should use a PGCopyReader, or a PGCopyTextReader for varchar
+// columns.
+func (f ID) Value() (driver.Value, error) {
+	return int64(f), nil
+}
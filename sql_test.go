@@ -0,0 +1,32 @@
+package snowflake
+
+import "testing"
+
+func TestScan(t *testing.T) {
+	for _, src := range []interface{}{int64(13587), []byte("13587"), "13587"} {
+		var id ID
+		if err := id.Scan(src); err != nil {
+			t.Errorf("Unexpected error scanning %T: %v", src, err)
+		} else if id != 13587 {
+			t.Errorf("Got %d scanning %T, expected 13587", id, src)
+		}
+	}
+
+	for _, src := range []interface{}{nil, "abc", []byte("1.5"), 1.5, true} {
+		var id ID
+		if err := id.Scan(src); err == nil {
+			t.Errorf("Expected an error scanning %#v", src)
+		}
+	}
+}
+
+func TestValue(t *testing.T) {
+	v, err := ID(13587).Value()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if i, ok := v.(int64); !ok || i != 13587 {
+		t.Errorf("Got %#v, expected int64(13587)", v)
+	}
+}
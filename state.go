@@ -0,0 +1,148 @@
+package snowflake
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A StateStore persists how far ahead a node has reserved its time, so a
+// restarted node never reuses a time, and with it an ID, from before the
+// restart.  Implementations may write to disk, Redis or any other storage
+// that survives the process, and must be safe for concurrent use.
+type StateStore interface {
+	// Load returns the last time saved, or the zero Time if nothing has
+	// been saved yet.
+	Load() (time.Time, error)
+
+	// Save durably records that IDs up to until may have been generated.
+	Save(until time.Time) error
+}
+
+// storeRetry is how long generation waits before saving to a failing
+// StateStore again.
+const storeRetry = 10 * time.Millisecond
+
+// WithStateStore makes the node reserve its time in s in windows of window,
+// so IDs stay unique and increasing across restarts, even a restart within
+// the same millisecond.  NewNode loads the saved time and, until the clock
+// has passed it, the node waits as it does when the clock moves backwards.
+// It then saves the time window ahead before returning, and saves again each
+// time generation moves past the saved time.
+//
+// A longer window saves less often but can delay the first IDs after a
+// restart by up to window.  While Save fails, Generate keeps retrying and
+// GenerateSafe and ReserveBlock return the error.  Reserving needs the node
+// lock, so the node generates under its mutex rather than lock free, and
+// WithStateStore cannot be combined with WithLockFree.
+func WithStateStore(s StateStore, window time.Duration) Option {
+	return func(n *Node) error {
+		if s == nil {
+			return errors.New("state store must not be nil")
+		}
+		if window <= 0 {
+			return errors.New("state store window must be positive")
+		}
+
+		n.store = s
+		n.storeWindow = int64(window)
+		return nil
+	}
+}
+
+// restore resumes the node after the time saved in its store and reserves
+// the first window.
+func (n *Node) restore() error {
+	if n.lockFree {
+		return errors.New("state store cannot be used with a lock free node")
+	}
+
+	saved, err := n.store.Load()
+	if err != nil {
+		return err
+	}
+
+	if !saved.IsZero() {
+		n.time = saved.Sub(time.UnixMilli(n.epoch)).Nanoseconds() / n.unit
+		n.step = n.stepMask
+	}
+
+	t := n.elapsed()
+	if n.time > t {
+		t = n.time
+	}
+
+	if !n.reserve(t) {
+		return n.storeErr
+	}
+	return nil
+}
+
+// reserve saves the time a window after elapsed time t in the node's store
+// and reports whether it succeeded.  After a failure it fails without saving
+// again until storeRetry has passed.  It must be called with the node lock
+// held, or during NewNode.
+func (n *Node) reserve(t int64) bool {
+	if n.storeErr != nil && time.Since(n.storeFailed) < storeRetry {
+		return false
+	}
+
+	until := t + (n.storeWindow+n.unit-1)/n.unit
+	if err := n.store.Save(n.layout.timeOf(until, n.epoch)); err != nil {
+		n.storeErr, n.storeFailed = err, time.Now()
+		return false
+	}
+
+	n.reserved, n.storeErr = until, nil
+	return true
+}
+
+// A FileStateStore is a StateStore that keeps the saved time in a file, as
+// decimal unix nanoseconds.  Saves write a temporary file next to it and
+// rename it into place, so a crash never leaves a partial file behind.
+type FileStateStore struct {
+	Path string
+}
+
+// Load reads the saved time, returning the zero Time if the file does not
+// exist.
+func (s FileStateStore) Load() (time.Time, error) {
+	b, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return time.Time{}, nil
+	} else if err != nil {
+		return time.Time{}, err
+	}
+
+	ns, err := strconv.ParseInt(strings.TrimSpace(string(b)), 10, 64)
+	if err != nil {
+		return time.Time{}, errors.New("invalid snowflake state in " + s.Path)
+	}
+	return time.Unix(0, ns), nil
+}
+
+// Save writes until to the file and syncs it to disk.
+func (s FileStateStore) Save(until time.Time) error {
+	f, err := os.CreateTemp(filepath.Dir(s.Path), filepath.Base(s.Path)+".tmp")
+	if err != nil {
+		return err
+	}
+
+	_, err = f.WriteString(strconv.FormatInt(until.UnixNano(), 10) + "\n")
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), s.Path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
@@ -0,0 +1,158 @@
+package snowflake
+
+import (
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+type memStore struct {
+	sync.Mutex
+	until time.Time
+	saves int
+	err   error
+}
+
+func (s *memStore) Load() (time.Time, error) {
+	s.Lock()
+	defer s.Unlock()
+	return s.until, nil
+}
+
+func (s *memStore) Save(until time.Time) error {
+	s.Lock()
+	defer s.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.until = until
+	s.saves++
+	return nil
+}
+
+func TestWithStateStore(t *testing.T) {
+	store := &memStore{}
+	now := nowMillis()
+	clock := WithClock(ClockFunc(func() int64 { return now }))
+
+	node, err := NewNode(1, clock, WithStateStore(store, 100*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := time.UnixMilli(now + 100); !store.until.Equal(expected) {
+		t.Errorf("Got a reservation until %s, expected %s", store.until, expected)
+	}
+
+	node.Generate()
+	now += 50
+	node.Generate()
+	if store.saves != 1 {
+		t.Errorf("Got %d saves within the window, expected 1", store.saves)
+	}
+
+	// Generating past the window reserves the next one.
+	now += 51
+	last := node.Generate()
+	if expected := time.UnixMilli(now + 100); store.saves != 2 || !store.until.Equal(expected) {
+		t.Errorf("Got %d saves until %s, expected 2 until %s", store.saves, store.until, expected)
+	}
+
+	// A restart with the clock still inside the reserved window resumes
+	// after it instead of reusing the current millisecond.
+	reads := 0
+	restarted, err := NewNode(1, WithStateStore(store, 100*time.Millisecond), WithClock(ClockFunc(func() int64 {
+		if reads++; reads > 5 {
+			return now + 200
+		}
+		return now
+	})))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if id := restarted.Generate(); id <= last || id.Time() <= now+100 {
+		t.Errorf("Got %d at %d after restarting, expected an ID after the reserved %d", id, id.Time(), now+100)
+	}
+}
+
+func TestStateStoreErrors(t *testing.T) {
+	failing := errors.New("store is down")
+	store := &memStore{err: failing}
+
+	if _, err := NewNode(1, WithStateStore(store, time.Second)); err != failing {
+		t.Errorf("Got %v, expected the store error from NewNode", err)
+	}
+
+	store.err = nil
+	now := nowMillis()
+	node, _ := NewNode(1, WithClock(ClockFunc(func() int64 { return now })), WithStateStore(store, time.Millisecond))
+
+	store.err = failing
+	now += 10
+	if _, err := node.GenerateSafe(); err != failing {
+		t.Errorf("Got %v from GenerateSafe, expected the store error", err)
+	}
+	if _, err := node.ReserveBlock(5000); err != failing {
+		t.Errorf("Got %v from ReserveBlock, expected the store error", err)
+	}
+
+	// Generate keeps retrying until the store recovers.
+	done := make(chan ID)
+	go func() { done <- node.Generate() }()
+
+	time.Sleep(3 * storeRetry)
+	store.Lock()
+	store.err = nil
+	store.Unlock()
+
+	select {
+	case id := <-done:
+		if id.Time() != now {
+			t.Errorf("Got time %d, expected %d", id.Time(), now)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Generate did not recover after the store did")
+	}
+
+	if _, err := NewNode(1, WithLockFree(), WithStateStore(store, time.Second)); err == nil {
+		t.Error("Expected an error combining a state store with WithLockFree")
+	}
+	if _, err := NewNode(1, WithStateStore(nil, time.Second)); err == nil {
+		t.Error("Expected an error for a nil store")
+	}
+	if _, err := NewNode(1, WithStateStore(store, 0)); err == nil {
+		t.Error("Expected an error for a zero window")
+	}
+	// A node rejected after its options are applied must not reserve a
+	// window in the store.
+	unused := &memStore{}
+	for _, opt := range []Option{WithRandomStepStart(13), WithRegionBits(11)} {
+		if _, err := NewNode(1, WithStateStore(unused, time.Hour), opt); err == nil {
+			t.Error("Expected an error for an invalid option")
+		}
+	}
+	if unused.saves != 0 {
+		t.Errorf("Got %d saves from rejected nodes, expected none", unused.saves)
+	}
+}
+
+func TestFileStateStore(t *testing.T) {
+	s := FileStateStore{Path: filepath.Join(t.TempDir(), "snowflake.state")}
+
+	if got, err := s.Load(); err != nil || !got.IsZero() {
+		t.Errorf("Got (%s, %v) before saving, expected the zero Time", got, err)
+	}
+
+	until := time.Unix(1700000000, 123456789)
+	if err := s.Save(until); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got, err := s.Load(); err != nil || !got.Equal(until) {
+		t.Errorf("Got (%s, %v), expected (%s, nil)", got, err, until)
+	}
+
+	if matches, _ := filepath.Glob(s.Path + ".tmp*"); len(matches) != 0 {
+		t.Errorf("Got leftover temporary files %v", matches)
+	}
+}
@@ -0,0 +1,83 @@
+package snowflake
+
+import (
+	"runtime"
+	"time"
+)
+
+// waitReason tells why an ID could not be generated yet.
+type waitReason uint8
+
+const (
+	waitNone waitReason = iota
+	waitRetry
+	waitExhausted
+	waitClockBehind
+	waitStore
+)
+
+// pause yields before generation is retried after waiting for the reason,
+// until the elapsed time t.
+func (n *Node) pause(wait waitReason, t int64) {
+	switch {
+	case wait == waitStore:
+		time.Sleep(storeRetry)
+	case wait == waitExhausted && n.exhaustion == SleepOnExhausted:
+		time.Sleep(time.Duration(n.epoch*int64(time.Millisecond) + t*n.unit - n.now()))
+	default:
+		runtime.Gosched()
+	}
+}
+
+// recordWait counts the wait of an ID in the node's stats the first time the
+// ID has to wait for the reason, given the reason it last waited for, and
+// returns the new reason.
+func (n *Node) recordWait(last, wait waitReason) waitReason {
+	if wait == last {
+		return wait
+	}
+
+	switch wait {
+	case waitExhausted:
+		n.exhausted.Add(1)
+	case waitClockBehind:
+		n.behind.Add(1)
+	}
+	return wait
+}
+
+// Stats holds running totals of a node's activity since it was created.  The
+// totals only ever grow, so they can be exported directly as counters, for
+// example with expvar:
+//
+//	expvar.Publish("snowflake", expvar.Func(func() any { return node.Stats() }))
+type Stats struct {
+	// Generated is the number of IDs generated.
+	Generated uint64
+
+	// Exhausted is the number of times an ID had to wait for the next time
+	// unit because the step of the current one was used up.  A rising rate
+	// means the node is close to its ceiling of 2^StepBits IDs per time
+	// unit, 4096 a millisecond with the default layout.
+	Exhausted uint64
+
+	// ClockBackwards is the number of times an ID found the clock behind the
+	// time of the node's last ID, such as after an NTP step.
+	ClockBackwards uint64
+
+	// Borrowed is the number of times a node using BorrowOnExhausted moved
+	// on to a time unit ahead of the clock because the step of the current
+	// one was used up.
+	Borrowed uint64
+}
+
+// Stats returns the node's running totals.  It is safe to call concurrently
+// with generation.
+func (n *Node) Stats() Stats {
+	return Stats{
+		Generated:      n.count.Load(),
+		Exhausted:      n.exhausted.Load(),
+		ClockBackwards: n.behind.Load(),
+		Borrowed:       n.borrowed.Load(),
+	}
+}
@@ -0,0 +1,59 @@
+package snowflake
+
+import "testing"
+
+func TestStats(t *testing.T) {
+	for _, opts := range [][]Option{{withMutex()}, nil} {
+		now, reads := nowMillis(), 0
+		clock := ClockFunc(func() int64 {
+			// The clock only moves on after being read a few times, so every
+			// exhausted step waits and the wait is counted exactly once.
+			if reads++; reads%10 == 0 {
+				now++
+			}
+			return now
+		})
+
+		node, _ := NewNode(1, append(opts, WithLayout(Layout{NodeBits: 10, StepBits: 2}), WithClock(clock))...)
+		for i := 0; i < 8; i++ {
+			node.Generate()
+		}
+
+		s := node.Stats()
+		if s.Generated != 8 || s.Exhausted == 0 || s.ClockBackwards != 0 {
+			t.Errorf("Got %+v, expected 8 generated, some exhausted and no clock backwards", s)
+		}
+
+		if c := node.TakeCount(); c != 8 {
+			t.Errorf("Got a count of %d, expected 8", c)
+		}
+		if s := node.Stats(); s.Generated != 8 {
+			t.Errorf("Got %d generated after TakeCount, expected 8", s.Generated)
+		}
+	}
+}
+
+func TestStatsClockBackwards(t *testing.T) {
+	for _, opts := range [][]Option{{withMutex()}, nil} {
+		now, reads := nowMillis(), -1
+		clock := ClockFunc(func() int64 {
+			if reads >= 0 {
+				if reads++; reads > 10 {
+					return now + 100
+				}
+			}
+			return now
+		})
+
+		node, _ := NewNode(1, append(opts, WithClock(clock))...)
+		node.Generate()
+
+		now -= 100
+		reads = 0
+		node.Generate()
+
+		if s := node.Stats(); s.Generated != 2 || s.ClockBackwards != 1 {
+			t.Errorf("Got %+v, expected 2 generated and 1 clock backwards", s)
+		}
+	}
+}
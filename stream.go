@@ -0,0 +1,155 @@
+package snowflake
+
+import (
+	"context"
+	"time"
+)
+
+// BatchStream returns a channel that receives slices of up to batchSize IDs.
+// A batch is sent as soon as it is full, and a partial batch is sent when
+// interval elapses without one filling up.  BatchStream panics unless
+// batchSize and interval are positive.
+//
+// Generation pauses while a batch is waiting to be received, so a slow reader
+// applies backpressure rather than IDs piling up in memory.  When ctx is
+// cancelled or the node is closed any pending IDs are offered as a final
+// batch for up to interval and the channel is closed, so readers should keep
+// receiving until the channel is closed.  A final batch nobody receives in
+// that time is dropped, which lets the stream stop even once its reader has
+// gone.
+func (n *Node) BatchStream(ctx context.Context, batchSize int, interval time.Duration) <-chan []ID {
+	if batchSize <= 0 {
+		panic("snowflake: non-positive batch size for BatchStream")
+	}
+	if interval <= 0 {
+		panic("snowflake: non-positive interval for BatchStream")
+	}
+
+	ch := make(chan []ID)
+
+	go func() {
+		defer close(ch)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		size := batchSize
+		if size > 4096 {
+			size = 4096
+		}
+
+		batch := make([]ID, 0, size)
+		send := func() bool {
+			select {
+			case ch <- batch:
+				batch = make([]ID, 0, size)
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		// The final batch is sent after ctx is done or the node closed,
+		// so it waits at most interval for the reader.
+		defer func() {
+			if len(batch) == 0 {
+				return
+			}
+
+			t := time.NewTimer(interval)
+			defer t.Stop()
+
+			select {
+			case ch <- batch:
+			case <-t.C:
+			}
+		}()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if len(batch) > 0 && !send() {
+					return
+				}
+			default:
+				id, err := n.next()
+				if err != nil {
+					return
+				}
+
+				batch = append(batch, id)
+				if len(batch) == batchSize && !send() {
+					return
+				}
+			}
+		}
+	}()
+
+	return ch
+}
+
+// Stream returns a channel that receives IDs generated on demand until ctx is
+// cancelled or the node is closed, after which the channel is closed.
+func (n *Node) Stream(ctx context.Context) <-chan ID {
+	ch := make(chan ID)
+
+	go func() {
+		defer close(ch)
+
+		for {
+			id, err := n.next()
+			if err != nil {
+				return
+			}
+
+			select {
+			case ch <- id:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch
+}
+
+// An IDReader is an io.Reader of newline delimited decimal snowflake IDs
+// generated on demand by a Node, for piping IDs into load testing tools
+// without buffering them.  It never returns io.EOF, so wrap it in an
+// io.LimitReader or stop reading when enough IDs have been consumed.  Once
+// the node is closed Read returns ErrClosed.  An IDReader is not safe for
+// concurrent use, but several readers may share a node.
+type IDReader struct {
+	n       *Node
+	line    [21]byte
+	pending []byte
+}
+
+// Reader returns an IDReader generating IDs from the node.
+func (n *Node) Reader() *IDReader {
+	return &IDReader{n: n}
+}
+
+// Read fills p with newline terminated IDs.  An ID that does not fit in the
+// rest of p is continued by the next Read, so only whole lines are ever
+// seen by a reader that reads to the end of each line.
+func (r *IDReader) Read(p []byte) (int, error) {
+	total := 0
+	for total < len(p) {
+		if len(r.pending) == 0 {
+			id, err := r.n.next()
+			if err != nil {
+				return total, err
+			}
+			r.pending = append(id.AppendString(r.line[:0]), '\n')
+		}
+
+		c := copy(p[total:], r.pending)
+		r.pending = r.pending[c:]
+		total += c
+	}
+
+	return total, nil
+}
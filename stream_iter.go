@@ -0,0 +1,25 @@
+//go:build go1.23
+
+package snowflake
+
+import "iter"
+
+// IDs returns an iterator over IDs generated on demand by the node, for
+// range over func loops.  The sequence only ends once the node is closed, so
+// the loop must break once it has enough IDs:
+//
+//	for id := range node.IDs() {
+//		if !send(id) {
+//			break
+//		}
+//	}
+func (n *Node) IDs() iter.Seq[ID] {
+	return func(yield func(ID) bool) {
+		for {
+			id, err := n.next()
+			if err != nil || !yield(id) {
+				return
+			}
+		}
+	}
+}
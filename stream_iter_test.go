@@ -0,0 +1,23 @@
+//go:build go1.23
+
+package snowflake
+
+import "testing"
+
+func TestIDs(t *testing.T) {
+	node, _ := NewNode(1)
+
+	var ids []ID
+	for id := range node.IDs() {
+		ids = append(ids, id)
+		if len(ids) == 100 {
+			break
+		}
+	}
+
+	for i := 1; i < len(ids); i++ {
+		if ids[i] <= ids[i-1] {
+			t.Fatalf("ID %d is not greater than %d", ids[i], ids[i-1])
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package snowflake
+
+import (
+	"context"
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBatchStreamSize(t *testing.T) {
+	node, _ := NewNode(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := node.BatchStream(ctx, 10, time.Hour)
+
+	var last ID
+	for i := 0; i < 5; i++ {
+		batch := <-ch
+		if len(batch) != 10 {
+			t.Fatalf("Got a batch of %d IDs, expected 10", len(batch))
+		}
+
+		for _, id := range batch {
+			if id <= last {
+				t.Fatalf("ID %d is not greater than %d", id, last)
+			}
+			last = id
+		}
+	}
+
+	cancel()
+	for range ch {
+	}
+}
+
+func TestBatchStreamInterval(t *testing.T) {
+	node, _ := NewNode(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := node.BatchStream(ctx, 1<<20, 5*time.Millisecond)
+
+	batch := <-ch
+	if len(batch) == 0 || len(batch) >= 1<<20 {
+		t.Errorf("Got a batch of %d IDs, expected a partial batch", len(batch))
+	}
+
+	cancel()
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case _, ok := <-ch:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("Channel was not closed after cancellation")
+		}
+	}
+}
+
+func TestBatchStreamAbandoned(t *testing.T) {
+	node, _ := NewNode(1)
+	before := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := node.BatchStream(ctx, 1<<20, 5*time.Millisecond)
+	<-ch
+	cancel()
+
+	// Nothing receives the final batch, so it must be dropped.
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		t.Errorf("Got %d goroutines after abandoning the stream, expected %d", n, before)
+	}
+}
+
+func TestBatchStreamInvalid(t *testing.T) {
+	node, _ := NewNode(1)
+
+	for _, args := range []struct {
+		size     int
+		interval time.Duration
+	}{{0, time.Second}, {1, 0}, {1, -time.Second}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected a panic for a batch size of %d and an interval of %s", args.size, args.interval)
+				}
+			}()
+			node.BatchStream(context.Background(), args.size, args.interval)
+		}()
+	}
+}
+
+func TestStream(t *testing.T) {
+	node, _ := NewNode(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := node.Stream(ctx)
+
+	var last ID
+	for i := 0; i < 100; i++ {
+		id := <-ch
+		if id <= last {
+			t.Fatalf("ID %d is not greater than %d", id, last)
+		}
+		last = id
+	}
+
+	cancel()
+	for range ch {
+	}
+}
+
+func TestIDReader(t *testing.T) {
+	node, _ := NewNode(1)
+	r := node.Reader()
+
+	// Read in small chunks so lines are split across calls.
+	var out []byte
+	chunk := make([]byte, 7)
+	for len(out) < 20*100 {
+		c, err := r.Read(chunk)
+		if err != nil || c != len(chunk) {
+			t.Fatalf("Got (%d, %v), expected (%d, nil)", c, err, len(chunk))
+		}
+		out = append(out, chunk[:c]...)
+	}
+
+	lines := strings.Split(string(out), "\n")
+	lines = lines[:len(lines)-1]
+
+	var last ID
+	for _, line := range lines {
+		id, err := ParseString(line)
+		if err != nil {
+			t.Fatalf("Got %v parsing line %q", err, line)
+		}
+		if id <= last {
+			t.Fatalf("ID %d is not greater than %d", id, last)
+		}
+		last = id
+	}
+
+	if n, err := io.ReadFull(io.LimitReader(node.Reader(), 5), make([]byte, 5)); n != 5 || err != nil {
+		t.Errorf("Got (%d, %v) reading through a LimitReader, expected (5, nil)", n, err)
+	}
+}
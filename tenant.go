@@ -0,0 +1,107 @@
+package snowflake
+
+import (
+	"errors"
+	"math"
+	"strconv"
+)
+
+// A TenantGen generates snowflake IDs for one tenant of a Node using only
+// that tenant's share of the step space, so the tenant can be recovered from
+// any of its IDs with ID.TenantFromStep.
+type TenantGen struct {
+	n     *Node
+	base  int64
+	width int64
+
+	// time and step are guarded by the node lock.
+	time int64
+	step int64
+}
+
+// Tenant returns the generator for tenant id, numbered from 0, out of
+// totalTenants tenants sharing the node.  Each tenant gets
+// 2^StepBits/totalTenants steps per millisecond, 4096/totalTenants with the
+// default layout, which is also its maximum throughput per millisecond.  An
+// error is returned if totalTenants exceeds the step space.
+//
+// The node keeps one generator per tenant, so calling Tenant again for the
+// same tenant returns the same generator, and every call must use the same
+// totalTenants.  Tenant generators share the node number, clock, layout,
+// policies, hooks, rate limit and state store, but not the node's own time
+// and step, so IDs must be generated either through tenants or through the
+// Node, never both.
+func (n *Node) Tenant(id int, totalTenants int) (*TenantGen, error) {
+	if totalTenants < 1 || int64(totalTenants) > n.stepMask+1 {
+		return nil, errors.New("total tenants must be between 1 and " + strconv.FormatInt(n.stepMask+1, 10))
+	}
+
+	if id < 0 || id >= totalTenants {
+		return nil, errors.New("tenant must be between 0 and " + strconv.Itoa(totalTenants-1))
+	}
+
+	n.Lock()
+	defer n.Unlock()
+
+	if n.tenants == nil {
+		n.tenants = make([]*TenantGen, totalTenants)
+	} else if len(n.tenants) != totalTenants {
+		return nil, errors.New("node already has " + strconv.Itoa(len(n.tenants)) + " tenants")
+	}
+
+	if g := n.tenants[id]; g != nil {
+		return g, nil
+	}
+
+	width := (n.stepMask + 1) / int64(totalTenants)
+	g := &TenantGen{n: n, base: int64(id) * width, width: width, time: math.MinInt64}
+	n.tenants[id] = g
+	return g, nil
+}
+
+// Generate creates and returns a unique snowflake ID for the tenant.  Like
+// Node.Generate, it waits while the clock is behind the tenant's last ID or
+// the tenant's steps for the current time are used up, and panics with
+// ErrClosed once the node has been closed.
+func (g *TenantGen) Generate() ID {
+	n := g.n
+	n.mustOpen()
+	n.throttle(1)
+
+	var waited waitReason
+	for {
+		n.Lock()
+		t, wait := n.advanceSeq(&g.time, &g.step, g.width-1, false)
+		if wait == waitNone {
+			n.count.Add(1)
+			id := n.compose(t, n.node, g.base+g.step)
+			n.Unlock()
+
+			n.runHooks(id)
+			return id
+		}
+		n.Unlock()
+
+		waited = n.recordWait(waited, wait)
+		n.pause(wait, t)
+	}
+}
+
+// TenantFromStep returns the tenant that generated the snowflake ID, given
+// the totalTenants passed to Node.Tenant of a node using DefaultLayout.  It
+// returns -1 if totalTenants is not between 1 and 2^StepBits.
+func (f ID) TenantFromStep(totalTenants int) int {
+	if totalTenants < 1 || int64(totalTenants) > stepMask+1 {
+		return -1
+	}
+	return int(f.Step() / ((stepMask + 1) / int64(totalTenants)))
+}
+
+// IDTenant is like ID.TenantFromStep but decodes a snowflake ID generated by
+// a tenant of this node, according to its layout.
+func (n *Node) IDTenant(id ID, totalTenants int) int {
+	if totalTenants < 1 || int64(totalTenants) > n.stepMask+1 {
+		return -1
+	}
+	return int(n.IDStep(id) / ((n.stepMask + 1) / int64(totalTenants)))
+}
@@ -0,0 +1,137 @@
+package snowflake
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTenant(t *testing.T) {
+	node, _ := NewNode(1)
+
+	const total = 3
+	seen := make(map[ID]bool)
+	for tenant := 0; tenant < total; tenant++ {
+		gen, err := node.Tenant(tenant, total)
+		if err != nil {
+			t.Fatalf("Unexpected error creating tenant %d: %v", tenant, err)
+		}
+
+		for i := 0; i < 3000; i++ {
+			id := gen.Generate()
+			if seen[id] {
+				t.Fatalf("Duplicate ID %d", id)
+			}
+			seen[id] = true
+
+			if got := id.TenantFromStep(total); got != tenant {
+				t.Fatalf("Got tenant %d from step %d, expected %d", got, id.Step(), tenant)
+			}
+
+			if id.Node() != 1 {
+				t.Fatalf("Got node %d, expected 1", id.Node())
+			}
+		}
+	}
+
+	if _, err := node.Tenant(0, 4097); err == nil {
+		t.Error("Expected an error when tenants exceed the step space")
+	}
+
+	if _, err := node.Tenant(3, 3); err == nil {
+		t.Error("Expected an error for an out of range tenant")
+	}
+}
+
+func TestNodeIDTenant(t *testing.T) {
+	node, _ := NewNode(1, WithLayout(Layout{NodeBits: 4, StepBits: 18}))
+
+	gen, err := node.Tenant(5, 7)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if got := node.IDTenant(gen.Generate(), 7); got != 5 {
+		t.Errorf("Got tenant %d, expected 5", got)
+	}
+}
+
+func TestTenantCached(t *testing.T) {
+	node, _ := NewNode(1)
+
+	a, _ := node.Tenant(2, 4)
+	b, _ := node.Tenant(2, 4)
+	if a != b {
+		t.Error("Expected the same generator for the same tenant")
+	}
+
+	seen := make(map[ID]bool)
+	for i := 0; i < 2000; i++ {
+		for _, g := range []*TenantGen{a, b} {
+			id := g.Generate()
+			if seen[id] {
+				t.Fatalf("Duplicate ID %d", id)
+			}
+			seen[id] = true
+		}
+	}
+
+	if _, err := node.Tenant(0, 8); err == nil {
+		t.Error("Expected an error for a different number of tenants")
+	}
+}
+
+func TestTenantClockBackwards(t *testing.T) {
+	reads, now := 0, nowMillis()
+	node, _ := NewNode(1, WithClock(ClockFunc(func() int64 {
+		// After going back a second, the clock jumps forward again once
+		// the tenant has waited for it for a while.
+		if reads++; reads > 50 && now < nowMillis() {
+			now += 2000
+		}
+		return now
+	})))
+	gen, _ := node.Tenant(1, 2)
+
+	first := gen.Generate()
+	now -= 1000
+	reads = 0
+	if second := gen.Generate(); second <= first {
+		t.Errorf("ID %d is not greater than %d after the clock went backwards", second, first)
+	}
+	if node.Stats().ClockBackwards == 0 {
+		t.Error("Expected the backwards clock to be counted")
+	}
+}
+
+func TestTenantNodeFeatures(t *testing.T) {
+	var hooked []ID
+	node, _ := NewNode(1, WithHook(func(id ID) { hooked = append(hooked, id) }))
+	gen, _ := node.Tenant(0, 2)
+
+	id := gen.Generate()
+	if len(hooked) != 1 || hooked[0] != id {
+		t.Errorf("Got hooked IDs %v, expected [%d]", hooked, id)
+	}
+
+	node.Close()
+	defer func() {
+		if r := recover(); r == nil || !errors.Is(r.(error), ErrClosed) {
+			t.Errorf("Got panic %v after Close, expected ErrClosed", r)
+		}
+	}()
+	gen.Generate()
+}
+
+func TestTenantFromStepInvalid(t *testing.T) {
+	node, _ := NewNode(1)
+	id := node.Generate()
+
+	for _, total := range []int{0, -1, 4097} {
+		if got := id.TenantFromStep(total); got != -1 {
+			t.Errorf("Got tenant %d from %d tenants, expected -1", got, total)
+		}
+		if got := node.IDTenant(id, total); got != -1 {
+			t.Errorf("Got node tenant %d from %d tenants, expected -1", got, total)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package snowflake
+
+import (
+	"math"
+	"time"
+)
+
+// AddTime returns the snowflake ID with d added to its time, keeping its
+// node and step, using DefaultLayout.  d is rounded towards zero to whole
+// milliseconds.  The time is clamped to the range of the time field rather
+// than wrapping, so adding a large negative duration gives a time at Epoch.
+func (f ID) AddTime(d time.Duration) ID {
+	return DefaultLayout.addTime(f, d)
+}
+
+// TruncateTime returns the snowflake ID with its time rounded down to a
+// multiple of bucket since the unix epoch, keeping its node and step, using
+// DefaultLayout and Epoch.  With the step and node cleared as well, such as
+// FirstIDForTime(id.TruncateTime(time.Hour).TimeStd()), it builds time
+// bucketed keys.  A bucket of zero or less returns the ID unchanged.
+func (f ID) TruncateTime(bucket time.Duration) ID {
+	return DefaultLayout.truncateTime(f, Epoch, bucket)
+}
+
+// AddTime is like ID.AddTime but for a snowflake ID generated by this node,
+// according to its layout.  d is rounded towards zero to the layout's time
+// unit.
+func (n *Node) AddTime(id ID, d time.Duration) ID {
+	return n.restyle(n.layout.addTime(n.canonical(id), d))
+}
+
+// TruncateTime is like ID.TruncateTime but for a snowflake ID generated by
+// this node, according to its epoch and layout.
+func (n *Node) TruncateTime(id ID, bucket time.Duration) ID {
+	return n.restyle(n.layout.truncateTime(n.canonical(id), n.epoch, bucket))
+}
+
+// restyle is the inverse of canonical, interleaving id again for a node
+// using WithBitInterleave.
+func (n *Node) restyle(id ID) ID {
+	if n.interleave {
+		return id.interleave()
+	}
+	return id
+}
+
+// withTime returns id with its time field replaced by t, clamped to the
+// field's range.
+func (l Layout) withTime(id ID, t int64) ID {
+	switch max := int64(l.timeLimit() - 1); {
+	case t < 0:
+		t = 0
+	case t > max:
+		t = max
+	}
+
+	shift := l.NodeBits + l.StepBits
+	return ID(t<<shift | int64(id)&(1<<shift-1))
+}
+
+func (l Layout) addTime(id ID, d time.Duration) ID {
+	t, _, _ := l.fields(id)
+
+	ticks := int64(d) / l.unit()
+	if ticks > math.MaxInt64-t {
+		return l.withTime(id, math.MaxInt64)
+	}
+	return l.withTime(id, t+ticks)
+}
+
+func (l Layout) truncateTime(id ID, epoch int64, bucket time.Duration) ID {
+	if bucket <= 0 {
+		return id
+	}
+
+	t, _, _ := l.fields(id)
+	at := l.timeOf(t, epoch)
+
+	// Round down relative to the unix epoch, so buckets line up across
+	// epochs and layouts.
+	rem := time.Duration(at.UnixNano() % int64(bucket))
+	if rem < 0 {
+		rem += bucket
+	}
+
+	since := at.Add(-rem).Sub(time.UnixMilli(epoch))
+	if since < 0 {
+		return l.withTime(id, 0)
+	}
+	return l.withTime(id, int64(since)/l.unit())
+}
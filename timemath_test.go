@@ -0,0 +1,66 @@
+package snowflake
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAddTime(t *testing.T) {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 678000000, time.UTC)
+	id := FirstIDForTime(at) | 42<<nodeShift | 7
+
+	later := id.AddTime(90 * time.Minute)
+	if got := time.UnixMilli(later.Time()); !got.Equal(at.Add(90 * time.Minute)) {
+		t.Errorf("Got %s, expected %s", got.UTC(), at.Add(90*time.Minute))
+	}
+	if later.Node() != 42 || later.Step() != 7 {
+		t.Errorf("Got node %d step %d, expected 42 and 7", later.Node(), later.Step())
+	}
+
+	if back := later.AddTime(-90 * time.Minute); back != id {
+		t.Errorf("Got %d adding the duration back, expected %d", back, id)
+	}
+	if got := id.AddTime(1500 * time.Microsecond); got.Time() != id.Time()+1 {
+		t.Errorf("Got time %d, expected the duration rounded to 1ms", got.Time())
+	}
+
+	if got := id.AddTime(-100 * 365 * 24 * time.Hour); got.Time() != Epoch || got.Node() != 42 {
+		t.Errorf("Got time %d node %d, expected it clamped to the epoch", got.Time(), got.Node())
+	}
+	if got := id.AddTime(1<<63 - 1); got < 0 || got.Time() != Epoch+1<<41-1 {
+		t.Errorf("Got %d, expected the time clamped to the end of its range", got)
+	}
+
+	node, _ := NewNode(3, WithPreset(Preset{Layout: SonyflakeLayout, Epoch: SonyflakeEpoch}))
+	sid := node.Generate()
+	if got := node.Decode(node.AddTime(sid, time.Second)); !got.Time.Equal(node.Decode(sid).Time.Add(time.Second)) || got.Node != 3 {
+		t.Errorf("Got %+v, expected a second later on node 3", got)
+	}
+}
+
+func TestTruncateTime(t *testing.T) {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 678000000, time.UTC)
+	id := FirstIDForTime(at) | 42<<nodeShift | 7
+
+	for bucket, expected := range map[time.Duration]time.Time{
+		time.Second: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		time.Hour:   time.Date(2024, 1, 2, 3, 0, 0, 0, time.UTC),
+		0:           at,
+	} {
+		got := id.TruncateTime(bucket)
+		if !time.UnixMilli(got.Time()).Equal(expected) || got.Node() != 42 || got.Step() != 7 {
+			t.Errorf("Got %s node %d step %d truncating to %s, expected %s node 42 step 7", time.UnixMilli(got.Time()).UTC(), got.Node(), got.Step(), bucket, expected)
+		}
+	}
+
+	node, _ := NewNode(3, WithPreset(Preset{Layout: SonyflakeLayout, Epoch: SonyflakeEpoch}))
+	sid, _ := SonyflakeLayout.Encode(Parts{Time: at, Node: 3, Step: 9}, SonyflakeEpoch)
+	if got := node.Decode(node.TruncateTime(sid, time.Minute)); !got.Time.Equal(at.Truncate(time.Minute)) || got.Node != 3 || got.Step != 9 {
+		t.Errorf("Got %+v, expected %s node 3 step 9", got, at.Truncate(time.Minute))
+	}
+
+	// Buckets reaching before the epoch are clamped to it.
+	if got := FirstIDForTime(time.UnixMilli(Epoch + 5)).TruncateTime(24 * time.Hour); got.Time() != Epoch {
+		t.Errorf("Got time %d, expected the epoch", got.Time())
+	}
+}
@@ -0,0 +1,76 @@
+package snowflake
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// ErrInvalidTraceID is returned by FromTraceID and FromSpanID for a trace or
+// span ID that was not derived from a snowflake ID.
+var ErrInvalidTraceID = errors.New("trace ID not derived from a snowflake ID")
+
+// ToTraceID derives a W3C trace context trace ID from the snowflake ID, so a
+// request's primary entity ID can double as its trace ID and traces can be
+// found from the entity alone.  The result converts directly to an
+// OpenTelemetry trace.TraceID.  A propagated context is built as
+//
+//	sc := trace.NewSpanContext(trace.SpanContextConfig{
+//		TraceID:    snowflake.ToTraceID(orderID),
+//		SpanID:     snowflake.ToSpanID(node.Generate()),
+//		TraceFlags: trace.FlagsSampled,
+//	})
+//	ctx = trace.ContextWithRemoteSpanContext(ctx, sc)
+//
+// and injected with the usual propagation.TraceContext propagator.
+//
+// The high 8 bytes hold the ID and the low 8 bytes its Hash, so ratio based
+// samplers, which look at the low bytes, still sample evenly.  Distinct IDs
+// always give distinct trace IDs, but one ID used for several requests puts
+// all of them in the same trace, so only derive trace IDs from IDs created
+// once per request.  Random trace IDs from other services collide with a
+// derived one with the usual negligible probability, and are recognised by
+// FromTraceID, as their low bytes do not match the hash.  The ID must not be
+// zero, which gives the invalid all zero trace ID.
+func ToTraceID(id ID) [16]byte {
+	var t [16]byte
+	binary.BigEndian.PutUint64(t[:8], uint64(id))
+	binary.BigEndian.PutUint64(t[8:], id.Hash())
+	return t
+}
+
+// FromTraceID returns the snowflake ID a trace ID was derived from by
+// ToTraceID, or ErrInvalidTraceID if it was not derived from an ID.
+func FromTraceID(t [16]byte) (ID, error) {
+	id := ID(binary.BigEndian.Uint64(t[:8]))
+	if id == 0 || binary.BigEndian.Uint64(t[8:]) != id.Hash() {
+		return 0, ErrInvalidTraceID
+	}
+
+	return id, nil
+}
+
+// ToSpanID derives a W3C trace context span ID from the snowflake ID, the
+// ID's 8 big endian bytes.  The result converts directly to an OpenTelemetry
+// trace.SpanID.  Span IDs only need to be unique within a trace, so IDs from
+// a node make span IDs that cannot collide with each other, though they can
+// collide with random span IDs made by other services in the same trace
+// with the usual 64 bit probability.  The ID must not be zero, which gives
+// the invalid all zero span ID.
+func ToSpanID(id ID) [8]byte {
+	var s [8]byte
+	binary.BigEndian.PutUint64(s[:], uint64(id))
+	return s
+}
+
+// FromSpanID returns the snowflake ID of a span ID made by ToSpanID.  Any
+// span ID converts back to an ID, so the result is only meaningful for span
+// IDs known to come from ToSpanID.  ErrInvalidTraceID is returned for the
+// invalid all zero span ID.
+func FromSpanID(s [8]byte) (ID, error) {
+	id := ID(binary.BigEndian.Uint64(s[:]))
+	if id == 0 {
+		return 0, ErrInvalidTraceID
+	}
+
+	return id, nil
+}
@@ -0,0 +1,44 @@
+package snowflake
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestTraceID(t *testing.T) {
+	id := ID(1417819914141990912)
+	tid := ToTraceID(id)
+	if got, want := hex.EncodeToString(tid[:]), "13ad1bb331e88000aa2cb666a8c9b52c"; got != want {
+		t.Errorf("Got trace ID %s, expected %s", got, want)
+	}
+
+	back, err := FromTraceID(tid)
+	if err != nil || back != id {
+		t.Errorf("Got %d, %v converting back, expected %d", back, err, id)
+	}
+
+	tid[15] ^= 1
+	if _, err := FromTraceID(tid); err != ErrInvalidTraceID {
+		t.Errorf("Got %v for a trace ID with a changed hash, expected ErrInvalidTraceID", err)
+	}
+	if _, err := FromTraceID([16]byte{}); err != ErrInvalidTraceID {
+		t.Errorf("Got %v for the zero trace ID, expected ErrInvalidTraceID", err)
+	}
+}
+
+func TestSpanID(t *testing.T) {
+	id := ID(1417819914141990912)
+	sid := ToSpanID(id)
+	if got, want := hex.EncodeToString(sid[:]), "13ad1bb331e88000"; got != want {
+		t.Errorf("Got span ID %s, expected %s", got, want)
+	}
+
+	back, err := FromSpanID(sid)
+	if err != nil || back != id {
+		t.Errorf("Got %d, %v converting back, expected %d", back, err, id)
+	}
+
+	if _, err := FromSpanID([8]byte{}); err != ErrInvalidTraceID {
+		t.Errorf("Got %v for the zero span ID, expected ErrInvalidTraceID", err)
+	}
+}
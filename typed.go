@@ -0,0 +1,88 @@
+package snowflake
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"log/slog"
+)
+
+// A Typed is a snowflake ID tagged with the entity type T it identifies, so
+// that IDs of different entities are distinct types the compiler will not
+// let callers mix up:
+//
+//	type UserID = snowflake.Typed[User]
+//	type OrderID = snowflake.Typed[Order]
+//
+// T is only a marker and is never instantiated.  A Typed encodes, parses and
+// stores exactly like an ID; use ID to get at the other ID methods.
+type Typed[T any] ID
+
+// GenerateTyped creates and returns a unique snowflake ID from n, typed for
+// entity T.
+func GenerateTyped[T any](n *Node) Typed[T] {
+	return Typed[T](n.Generate())
+}
+
+// ParseTyped converts a decimal string, as returned by String, into a typed
+// snowflake ID.  Errors are the same as for ID.UnmarshalText.
+func ParseTyped[T any](s string) (Typed[T], error) {
+	var t Typed[T]
+	err := t.UnmarshalText([]byte(s))
+	return t, err
+}
+
+// ID returns the untyped snowflake ID.
+func (t Typed[T]) ID() ID {
+	return ID(t)
+}
+
+// Int64 returns an int64 of the snowflake ID.
+func (t Typed[T]) Int64() int64 {
+	return int64(t)
+}
+
+// String returns the decimal string of the snowflake ID.
+func (t Typed[T]) String() string {
+	return ID(t).String()
+}
+
+// Format formats the snowflake ID like ID.Format.
+func (t Typed[T]) Format(s fmt.State, verb rune) {
+	ID(t).Format(s, verb)
+}
+
+// LogValue implements slog.LogValuer like ID.LogValue.
+func (t Typed[T]) LogValue() slog.Value {
+	return ID(t).LogValue()
+}
+
+// MarshalJSON returns the snowflake ID in the same encoding as
+// ID.MarshalJSON.
+func (t Typed[T]) MarshalJSON() ([]byte, error) {
+	return ID(t).MarshalJSON()
+}
+
+// UnmarshalJSON accepts the same input as ID.UnmarshalJSON.
+func (t *Typed[T]) UnmarshalJSON(b []byte) error {
+	return (*ID)(t).UnmarshalJSON(b)
+}
+
+// MarshalText returns the decimal string of the snowflake ID.
+func (t Typed[T]) MarshalText() ([]byte, error) {
+	return ID(t).MarshalText()
+}
+
+// UnmarshalText accepts the same input as ID.UnmarshalText.
+func (t *Typed[T]) UnmarshalText(b []byte) error {
+	return (*ID)(t).UnmarshalText(b)
+}
+
+// Scan implements sql.Scanner like ID.Scan.
+func (t *Typed[T]) Scan(src interface{}) error {
+	return (*ID)(t).Scan(src)
+}
+
+// Value implements driver.Valuer, storing the snowflake ID as an int64.
+func (t Typed[T]) Value() (driver.Value, error) {
+	return ID(t).Value()
+}
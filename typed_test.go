@@ -0,0 +1,61 @@
+package snowflake
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+type testUser struct{}
+
+type testOrder struct{}
+
+func TestTyped(t *testing.T) {
+	node, _ := NewNode(1)
+	user := GenerateTyped[testUser](node)
+	order := GenerateTyped[testOrder](node)
+
+	if user.ID() >= order.ID() {
+		t.Errorf("Got %d and %d, expected typed IDs to keep generation order", user, order)
+	}
+
+	if s := fmt.Sprintf("%v %x", user, user); s != user.String()+" "+fmt.Sprintf("%x", user.ID()) {
+		t.Errorf("Got %s, expected the ID formatting", s)
+	}
+
+	parsed, err := ParseTyped[testUser](user.String())
+	if err != nil || parsed != user {
+		t.Errorf("Got (%d, %v) parsing %s, expected (%d, nil)", parsed, err, user, user)
+	}
+	if _, err := ParseTyped[testUser]("-5"); !errors.Is(err, ErrInvalidID) {
+		t.Errorf("Got %v, expected ErrInvalidID", err)
+	}
+
+	type record struct {
+		User  Typed[testUser]  `json:"user"`
+		Order Typed[testOrder] `json:"order"`
+	}
+
+	in := record{User: user, Order: order}
+	b, err := json.Marshal(in)
+	if err != nil {
+		t.Fatalf("error marshaling, %s", err)
+	}
+	if expected := `{"user":"` + user.String() + `","order":"` + order.String() + `"}`; string(b) != expected {
+		t.Errorf("Got %s, expected %s", b, expected)
+	}
+
+	var out record
+	if err := json.Unmarshal(b, &out); err != nil || out != in {
+		t.Errorf("Got (%+v, %v), expected (%+v, nil)", out, err, in)
+	}
+
+	var scanned Typed[testOrder]
+	if err := scanned.Scan(order.Int64()); err != nil || scanned != order {
+		t.Errorf("Got (%d, %v) scanning, expected (%d, nil)", scanned, err, order)
+	}
+	if v, _ := scanned.Value(); v != order.Int64() {
+		t.Errorf("Got %v, expected %d", v, order.Int64())
+	}
+}
@@ -0,0 +1,47 @@
+package snowflake
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// ToULID converts the snowflake ID into the 16 bytes of a ULID with the same
+// millisecond timestamp, for systems migrating between ULIDs and snowflake
+// IDs, using DefaultLayout and Epoch.  The result converts directly to
+// types such as oklog/ulid.ULID.
+//
+// The node and step take the top 22 bits of the ULID's 80 bit random part
+// and the rest is zero, so ULIDs compare in the same order as their IDs and
+// FromULID recovers the ID exactly.  Such ULIDs are not random and should
+// not be used where a ULID's entropy is relied upon.
+func ToULID(id ID) [16]byte {
+	low := uint64(id) & (1<<timeShift - 1)
+
+	var u [16]byte
+	binary.BigEndian.PutUint64(u[:8], uint64(id.Time())<<16|low>>(timeShift-16))
+	binary.BigEndian.PutUint64(u[8:], low<<(64-(timeShift-16)))
+	return u
+}
+
+// FromULID converts a ULID into a snowflake ID with the same millisecond
+// timestamp, using DefaultLayout and Epoch.  The time is exact, but the node
+// and step are taken from the top 22 bits of the ULID's random part, so only
+// ULIDs made by ToULID convert back to their original ID; other ULIDs get an
+// arbitrary node and step, and two ULIDs from the same millisecond may map to
+// the same ID.  An error is returned for a ULID from before Epoch or past
+// the end of the ID's time range.
+func FromULID(ulid [16]byte) (ID, error) {
+	hi := binary.BigEndian.Uint64(ulid[:8])
+	lo := binary.BigEndian.Uint64(ulid[8:])
+
+	ms := int64(hi >> 16)
+	switch t := ms - Epoch; {
+	case t < 0:
+		return 0, errors.New("ULID time is before the epoch")
+	case t >= 1<<(63-timeShift):
+		return 0, ErrTimestampOverflow
+	}
+
+	low := (hi&0xFFFF)<<(timeShift-16) | lo>>(64-(timeShift-16))
+	return ID((ms-Epoch)<<timeShift | int64(low)), nil
+}
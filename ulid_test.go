@@ -0,0 +1,60 @@
+package snowflake
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestULID(t *testing.T) {
+	node, _ := NewNode(1023)
+
+	var prev [16]byte
+	for i := 0; i < 5000; i++ {
+		id := node.Generate()
+		u := ToULID(id)
+
+		if ms := int64(binary.BigEndian.Uint64(u[:8]) >> 16); ms != id.Time() {
+			t.Fatalf("Got ULID time %d, expected %d", ms, id.Time())
+		}
+
+		back, err := FromULID(u)
+		if err != nil || back != id {
+			t.Fatalf("Got (%d, %v) converting back, expected (%d, nil)", back, err, id)
+		}
+
+		if bytes.Compare(u[:], prev[:]) <= 0 {
+			t.Fatalf("ULID %x of ID %d does not sort after %x", u, id, prev)
+		}
+		prev = u
+	}
+}
+
+func TestFromULID(t *testing.T) {
+	// A foreign ULID keeps its time and takes the node and step from the top
+	// of its random part.
+	ms := time.Date(2024, 1, 2, 3, 4, 5, 678000000, time.UTC).UnixMilli()
+	var u [16]byte
+	binary.BigEndian.PutUint64(u[:8], uint64(ms)<<16|0xFFFF)
+	binary.BigEndian.PutUint64(u[8:], 0xFFFFFFFFFFFFFFFF)
+
+	id, err := FromULID(u)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if id.Time() != ms || id.Node() != 1023 || id.Step() != 4095 {
+		t.Errorf("Got time %d node %d step %d, expected %d, 1023, 4095", id.Time(), id.Node(), id.Step(), ms)
+	}
+
+	binary.BigEndian.PutUint64(u[:8], uint64(Epoch-1)<<16)
+	if _, err := FromULID(u); err == nil {
+		t.Error("Expected an error for a ULID before the epoch")
+	}
+
+	binary.BigEndian.PutUint64(u[:8], uint64(Epoch+1<<41)<<16)
+	if _, err := FromULID(u); !errors.Is(err, ErrTimestampOverflow) {
+		t.Errorf("Got %v, expected ErrTimestampOverflow", err)
+	}
+}
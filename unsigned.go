@@ -0,0 +1,135 @@
+package snowflake
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+)
+
+// A UID is a snowflake ID interpreted as an unsigned 64 bit integer, for IDs
+// of a Layout with Unsigned set and for IDs read from unsigned columns and
+// other ecosystems that use the full 64 bits.  Its methods format and parse
+// unsigned decimal, so such IDs round trip without overflowing.
+//
+// A UID converts to and from an ID without loss; decode it with
+// Node.Decode(u.ID()) or Layout.Decode.
+type UID uint64
+
+// Unsigned returns the snowflake ID as a UID.
+func (f ID) Unsigned() UID {
+	return UID(f)
+}
+
+// ID returns the UID as an ID with the same bits, which is negative for
+// UIDs above the largest int64.
+func (u UID) ID() ID {
+	return ID(u)
+}
+
+// String returns the unsigned decimal string of the UID.
+func (u UID) String() string {
+	return strconv.FormatUint(uint64(u), 10)
+}
+
+// AppendString appends the unsigned decimal string of the UID to dst and
+// returns the extended buffer.
+func (u UID) AppendString(dst []byte) []byte {
+	return strconv.AppendUint(dst, uint64(u), 10)
+}
+
+// ParseUID converts an unsigned decimal string, as returned by UID.String,
+// into a UID.  Errors are an *InvalidIDError wrapping ErrInvalidID.
+func ParseUID(s string) (UID, error) {
+	return parseUID([]byte(s))
+}
+
+func parseUID(b []byte) (UID, error) {
+	u, err := strconv.ParseUint(string(b), 10, 64)
+	if err != nil {
+		if ne, ok := err.(*strconv.NumError); ok {
+			err = ne.Err
+		}
+		return 0, &InvalidIDError{Input: string(b), Err: err}
+	}
+
+	return UID(u), nil
+}
+
+// MarshalJSON returns the UID as a quoted unsigned decimal string, which
+// JavaScript clients can hold without losing precision.
+func (u UID) MarshalJSON() ([]byte, error) {
+	b := make([]byte, 0, 22)
+	b = append(b, '"')
+	b = strconv.AppendUint(b, uint64(u), 10)
+	return append(b, '"'), nil
+}
+
+// UnmarshalJSON accepts a quoted string or a bare number holding an unsigned
+// decimal UID, and like encoding/json it leaves the UID unchanged for null.
+func (u *UID) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
+	}
+
+	if len(b) >= 2 && b[0] == '"' && b[len(b)-1] == '"' {
+		b = b[1 : len(b)-1]
+	}
+
+	v, err := parseUID(b)
+	if err != nil {
+		return err
+	}
+
+	*u = v
+	return nil
+}
+
+// MarshalText returns the unsigned decimal string of the UID.
+func (u UID) MarshalText() ([]byte, error) {
+	return strconv.AppendUint(nil, uint64(u), 10), nil
+}
+
+// UnmarshalText converts an unsigned decimal string into a UID.
+func (u *UID) UnmarshalText(b []byte) error {
+	v, err := parseUID(b)
+	if err != nil {
+		return err
+	}
+
+	*u = v
+	return nil
+}
+
+// Scan implements sql.Scanner.  src may be an int64, which is reinterpreted
+// as the unsigned bits drivers return for BIGINT UNSIGNED values above the
+// largest int64, a uint64, or a []byte or string holding the unsigned
+// decimal UID.
+func (u *UID) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case int64:
+		*u = UID(v)
+		return nil
+	case uint64:
+		*u = UID(v)
+		return nil
+	case []byte:
+		return u.UnmarshalText(v)
+	case string:
+		return u.UnmarshalText([]byte(v))
+	case nil:
+		return fmt.Errorf("cannot scan NULL into a snowflake UID")
+	}
+
+	return fmt.Errorf("cannot scan %T into a snowflake UID", src)
+}
+
+// Value implements driver.Valuer.  UIDs that fit in an int64 are stored as
+// one, and larger UIDs as their unsigned decimal string, since database/sql
+// does not accept uint64 values with the high bit set.  Databases with
+// unsigned 64 bit columns, such as MySQL, convert the string losslessly.
+func (u UID) Value() (driver.Value, error) {
+	if int64(u) >= 0 {
+		return int64(u), nil
+	}
+	return u.String(), nil
+}
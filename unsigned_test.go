@@ -0,0 +1,116 @@
+package snowflake
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestUnsignedLayout(t *testing.T) {
+	l := Layout{NodeBits: 10, StepBits: 12, Unsigned: true}
+	if l.TimeBits() != 42 {
+		t.Errorf("Got %d time bits, expected 42", l.TimeBits())
+	}
+
+	// An ID in the second half of the unsigned range, past where a signed
+	// layout overflows.
+	now := Epoch + 1<<41 + 5
+	node, err := NewNode(3, WithLayout(l), WithClock(ClockFunc(func() int64 { return now })))
+	if err != nil {
+		t.Fatalf("error creating NewNode, %s", err)
+	}
+
+	id, err := node.GenerateSafe()
+	if err != nil {
+		t.Fatalf("Got %v, expected an ID beyond the signed range", err)
+	}
+	if id >= 0 {
+		t.Errorf("Got %d, expected the sign bit to be set", id)
+	}
+
+	p := node.Decode(id)
+	if p.Time.UnixMilli() != now || p.Node != 3 || p.Step != 0 {
+		t.Errorf("Got %+v, expected time %d node 3 step 0", p, now)
+	}
+
+	if err := node.ValidateID(id, time.Second); err != nil {
+		t.Errorf("Got %v validating %d, expected nil", err, id)
+	}
+
+	back, err := l.Encode(p, Epoch)
+	if err != nil || back != id {
+		t.Errorf("Got (%d, %v) encoding %+v, expected (%d, nil)", back, err, p, id)
+	}
+
+	if max := node.MaxTime().UnixMilli(); max != Epoch+1<<42 {
+		t.Errorf("Got max time %d, expected %d", max, Epoch+1<<42)
+	}
+
+	for _, bad := range []Layout{{NodeBits: 0, StepBits: 12, Unsigned: true}, {NodeBits: 32, StepBits: 32, Unsigned: true}} {
+		if bad.Validate() == nil {
+			t.Errorf("Expected an error validating %+v", bad)
+		}
+	}
+
+	if _, err := NewNode(1, WithLayout(l), WithBitInterleave()); err == nil {
+		t.Error("Expected an error combining an unsigned layout with interleaving")
+	}
+}
+
+func TestUID(t *testing.T) {
+	u := UID(math.MaxUint64 - 1)
+	if s := u.String(); s != "18446744073709551614" {
+		t.Errorf("Got %s, expected 18446744073709551614", s)
+	}
+	if u.ID() != -2 || u.ID().Unsigned() != u {
+		t.Errorf("Got %d, expected -2 round tripping", u.ID())
+	}
+
+	if got, err := ParseUID(u.String()); err != nil || got != u {
+		t.Errorf("Got (%d, %v), expected (%d, nil)", got, err, u)
+	}
+	if _, err := ParseUID("-1"); !errors.Is(err, ErrInvalidID) {
+		t.Errorf("Got %v, expected ErrInvalidID", err)
+	}
+
+	b, _ := json.Marshal(u)
+	if string(b) != `"18446744073709551614"` {
+		t.Errorf("Got %s, expected the quoted unsigned string", b)
+	}
+
+	var got UID
+	for _, in := range []string{`"18446744073709551614"`, `18446744073709551614`} {
+		got = 0
+		if err := json.Unmarshal([]byte(in), &got); err != nil || got != u {
+			t.Errorf("Got (%d, %v) unmarshaling %s, expected (%d, nil)", got, err, in, u)
+		}
+	}
+	if err := json.Unmarshal([]byte("null"), &got); err != nil || got != u {
+		t.Errorf("Got (%d, %v) unmarshaling null, expected it unchanged", got, err)
+	}
+}
+
+func TestUIDSQL(t *testing.T) {
+	big := UID(1<<63 + 42)
+
+	if v, _ := big.Value(); v != "9223372036854775850" {
+		t.Errorf("Got %v, expected the decimal string", v)
+	}
+	if v, _ := UID(42).Value(); v != int64(42) {
+		t.Errorf("Got %v, expected int64 42", v)
+	}
+
+	for _, src := range []interface{}{int64(-1<<63 + 42), uint64(1<<63 + 42), []byte("9223372036854775850"), "9223372036854775850"} {
+		var u UID
+		if err := u.Scan(src); err != nil || u != big {
+			t.Errorf("Got (%d, %v) scanning %T, expected (%d, nil)", u, err, src, big)
+		}
+	}
+
+	var u UID
+	if err := u.Scan(nil); err == nil {
+		t.Error("Expected an error scanning NULL")
+	}
+}
@@ -0,0 +1,125 @@
+package snowflake
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+)
+
+// A UUID is a 128 bit RFC 9562 UUID, as generated by Node.GenerateUUID.  It
+// fits UUID database columns and interoperates with other UUIDv7 libraries.
+type UUID [16]byte
+
+// ErrInvalidUUID is returned when parsing a string that is not a UUID in the
+// canonical 8-4-4-4-12 hex form.
+var ErrInvalidUUID = errors.New("invalid UUID")
+
+// GenerateUUID creates and returns a UUIDv7 using the same clock as
+// Generate.  The 48 bit timestamp holds the Unix time in milliseconds and the
+// 74 bits of rand_a and rand_b hold the step followed by the node number, with
+// the rest filled from crypto/rand.  With the default layout that is 12 step
+// bits, 10 node bits and 52 random bits.
+//
+// Unlike a fully random v7, fewer bits are random, and the step and node
+// make UUIDs from the same node strictly increasing, so they sort in
+// generation order and reveal which node created them.  That only holds for
+// layouts with a time unit of at least a millisecond, as the step restarts
+// within a millisecond otherwise.  The fields are taken from the canonical
+// ID, so UUIDs of a node using WithBitInterleave are still time ordered.
+//
+// It panics if crypto/rand fails to return random bits.
+func (n *Node) GenerateUUID() UUID {
+	p := n.layout.Decode(n.canonical(n.Generate()), n.epoch)
+
+	var u UUID
+	if _, err := rand.Read(u[:]); err != nil {
+		panic("snowflake: reading random UUID bits: " + err.Error())
+	}
+	randA := binary.BigEndian.Uint64(u[:8]) & (1<<12 - 1)
+	randB := binary.BigEndian.Uint64(u[8:]) & (1<<62 - 1)
+
+	// Place the step and node at the top of the 74 bit rand_a and rand_b
+	// field, keeping the random bits below them.
+	k := uint(n.timeShift)
+	x := uint64(p.Step)<<n.layout.NodeBits | uint64(p.Node)
+	if k <= 12 {
+		randA = x<<(12-k) | randA&(1<<(12-k)-1)
+	} else {
+		randA = x >> (k - 12)
+		randB = (x<<(74-k) | randB&(1<<(74-k)-1)) & (1<<62 - 1)
+	}
+
+	hi := uint64(p.Time.UnixMilli())<<16 | 0x7<<12 | randA
+	lo := randB | 0x2<<62
+	binary.BigEndian.PutUint64(u[:8], hi)
+	binary.BigEndian.PutUint64(u[8:], lo)
+
+	return u
+}
+
+// GenerateUUIDv7 is like GenerateUUID but returns the UUID as a string.
+func (n *Node) GenerateUUIDv7() string {
+	return n.GenerateUUID().String()
+}
+
+// String returns the UUID in the canonical 8-4-4-4-12 lowercase hex form.
+func (u UUID) String() string {
+	buf := make([]byte, 36)
+	hex.Encode(buf[0:8], u[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], u[10:])
+
+	return string(buf)
+}
+
+// ParseUUID converts a UUID in the canonical 8-4-4-4-12 hex form, in either
+// case, into a UUID type.
+func ParseUUID(s string) (UUID, error) {
+	var u UUID
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return u, ErrInvalidUUID
+	}
+
+	src := []byte(s[0:8] + s[9:13] + s[14:18] + s[19:23] + s[24:])
+	if _, err := hex.Decode(u[:], src); err != nil {
+		return UUID{}, ErrInvalidUUID
+	}
+
+	return u, nil
+}
+
+// Time returns an int64 unix timestamp in milliseconds of a UUIDv7.
+func (u UUID) Time() int64 {
+	return int64(binary.BigEndian.Uint64(u[:8]) >> 16)
+}
+
+// MarshalJSON returns a json byte array string of the UUID.
+func (u UUID) MarshalJSON() ([]byte, error) {
+	buff := make([]byte, 0, 38)
+	buff = append(buff, '"')
+	buff = append(buff, u.String()...)
+	buff = append(buff, '"')
+	return buff, nil
+}
+
+// UnmarshalJSON converts a json byte array of a UUID into a UUID type.
+func (u *UUID) UnmarshalJSON(b []byte) error {
+	if len(b) < 2 || b[0] != '"' || b[len(b)-1] != '"' {
+		return ErrInvalidUUID
+	}
+
+	v, err := ParseUUID(string(b[1 : len(b)-1]))
+	if err != nil {
+		return err
+	}
+
+	*u = v
+	return nil
+}
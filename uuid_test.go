@@ -0,0 +1,117 @@
+package snowflake
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+)
+
+var uuidv7Pattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestGenerateUUIDv7(t *testing.T) {
+	node, _ := NewNode(42)
+
+	start := time.Now().UnixNano() / 1000000
+
+	prev := ""
+	for i := 0; i < 10000; i++ {
+		u := node.GenerateUUIDv7()
+
+		if !uuidv7Pattern.MatchString(u) {
+			t.Fatalf("UUID %s is not a valid v7 UUID", u)
+		}
+
+		if u <= prev {
+			t.Fatalf("UUID %s does not sort after %s", u, prev)
+		}
+		prev = u
+	}
+
+	ms, _ := strconv.ParseInt(prev[0:8]+prev[9:13], 16, 64)
+	if ms < start || ms > time.Now().UnixNano()/1000000 {
+		t.Errorf("UUID timestamp %d is outside the generation window", ms)
+	}
+}
+
+func TestGenerateUUIDv7Layout(t *testing.T) {
+	for _, opts := range [][]Option{
+		{WithLayout(Layout{NodeBits: 4, StepBits: 6})},
+		{WithLayout(Layout{NodeBits: 5, StepBits: 17})},
+		{WithBitInterleave()},
+	} {
+		node, _ := NewNode(9, opts...)
+		start := time.Now().UnixMilli()
+
+		prev := ""
+		for i := 0; i < 5000; i++ {
+			u := node.GenerateUUIDv7()
+
+			if !uuidv7Pattern.MatchString(u) {
+				t.Fatalf("UUID %s is not a valid v7 UUID", u)
+			}
+
+			if u <= prev {
+				t.Fatalf("UUID %s does not sort after %s", u, prev)
+			}
+			prev = u
+		}
+
+		if u, _ := ParseUUID(prev); u.Time() < start || u.Time() > time.Now().UnixMilli() {
+			t.Errorf("UUID timestamp %d is outside the generation window", u.Time())
+		}
+	}
+}
+
+func TestUUID(t *testing.T) {
+	node, _ := NewNode(42)
+
+	start := time.Now().UnixNano() / 1000000
+	u := node.GenerateUUID()
+
+	if ms := u.Time(); ms < start || ms > time.Now().UnixNano()/1000000 {
+		t.Errorf("UUID timestamp %d is outside the generation window", ms)
+	}
+
+	parsed, err := ParseUUID(u.String())
+	if err != nil || parsed != u {
+		t.Errorf("Got (%s, %v) parsing %s, expected (%s, nil)", parsed, err, u, u)
+	}
+
+	upper, err := ParseUUID("0190A1B2-C3D4-7E5F-8A6B-7C8D9E0F1A2B")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if s := upper.String(); s != "0190a1b2-c3d4-7e5f-8a6b-7c8d9e0f1a2b" {
+		t.Errorf("Got %s, expected the lowercase form", s)
+	}
+
+	for _, s := range []string{"", "0190a1b2c3d47e5f8a6b7c8d9e0f1a2b", "0190a1b2-c3d4-7e5f-8a6b-7c8d9e0f1a2g", "0190a1b2-c3d4-7e5f-8a6b_7c8d9e0f1a2b"} {
+		if _, err := ParseUUID(s); err != ErrInvalidUUID {
+			t.Errorf("Got error %v parsing %q, expected ErrInvalidUUID", err, s)
+		}
+	}
+}
+
+func TestUUIDJSON(t *testing.T) {
+	node, _ := NewNode(1)
+	u := node.GenerateUUID()
+
+	b, err := json.Marshal(struct{ ID UUID }{u})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := `{"ID":"` + u.String() + `"}`; string(b) != expected {
+		t.Errorf("Got %s, expected %s", b, expected)
+	}
+
+	var v struct{ ID UUID }
+	if err := json.Unmarshal(b, &v); err != nil || v.ID != u {
+		t.Errorf("Got (%s, %v) unmarshaling %s, expected (%s, nil)", v.ID, err, b, u)
+	}
+
+	if err := json.Unmarshal([]byte(`{"ID":12}`), &v); err == nil {
+		t.Error("Expected an error for a non-string UUID")
+	}
+}
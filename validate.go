@@ -0,0 +1,65 @@
+package snowflake
+
+import (
+	"errors"
+	"time"
+)
+
+// DefaultSkew is how far in the future IsValid allows a snowflake ID's time
+// to be, to tolerate clock differences between the generating node and the
+// validating one.
+const DefaultSkew = time.Minute
+
+var (
+	// ErrIDNotPositive is returned by Validate for a zero or negative ID,
+	// which no node generates.  IDs of unsigned layouts are only rejected
+	// when zero.
+	ErrIDNotPositive = errors.New("snowflake ID is not positive")
+
+	// ErrIDInFuture is returned by Validate for an ID whose time is further
+	// in the future than the allowed skew.
+	ErrIDInFuture = errors.New("snowflake ID is from the future")
+)
+
+// Validate checks that the snowflake ID could have been generated by now by
+// a node using layout l and epoch: it must be positive, or just non-zero
+// for an unsigned layout, and its time must be no more than skew after the
+// current time.  It returns an error describing
+// the first check that fails, or the layout's own validation error.
+//
+// Validate is meant for IDs received from untrusted clients.  A positive ID
+// always decodes to a time at or after the epoch and to a node and step that
+// fit the layout, as those fields are read from fixed bit ranges, so those
+// need no separate checks.
+func (f ID) Validate(l Layout, epoch int64, skew time.Duration) error {
+	if err := l.Validate(); err != nil {
+		return err
+	}
+
+	return validate(f, l, epoch, time.Now(), skew)
+}
+
+// IsValid reports whether the snowflake ID passes Validate with
+// DefaultLayout, Epoch and DefaultSkew.
+func (f ID) IsValid() bool {
+	return f.Validate(DefaultLayout, Epoch, DefaultSkew) == nil
+}
+
+// ValidateID is like ID.Validate but checks the snowflake ID against the
+// node's layout and epoch, and the current time of its clock.
+func (n *Node) ValidateID(id ID, skew time.Duration) error {
+	return validate(n.canonical(id), n.layout, n.epoch, time.Unix(0, n.now()), skew)
+}
+
+func validate(id ID, l Layout, epoch int64, now time.Time, skew time.Duration) error {
+	if id == 0 || id < 0 && !l.Unsigned {
+		return ErrIDNotPositive
+	}
+
+	t, _, _ := l.fields(id)
+	if l.timeOf(t, epoch).After(now.Add(skew)) {
+		return ErrIDInFuture
+	}
+
+	return nil
+}
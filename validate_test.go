@@ -0,0 +1,51 @@
+package snowflake
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	node, _ := NewNode(1)
+	id := node.Generate()
+
+	if !id.IsValid() {
+		t.Errorf("Expected %d to be valid", id)
+	}
+
+	for _, bad := range []ID{0, -1, -1 << 63} {
+		if err := bad.Validate(DefaultLayout, Epoch, DefaultSkew); err != ErrIDNotPositive {
+			t.Errorf("Got %v validating %d, expected ErrIDNotPositive", err, bad)
+		}
+	}
+
+	future := ID((nowMillis() + 10000 - Epoch) << timeShift)
+	if err := future.Validate(DefaultLayout, Epoch, time.Second); err != ErrIDInFuture {
+		t.Errorf("Got %v, expected ErrIDInFuture", err)
+	}
+	if err := future.Validate(DefaultLayout, Epoch, time.Minute); err != nil {
+		t.Errorf("Got %v, expected the skew to allow it", err)
+	}
+
+	if err := id.Validate(Layout{NodeBits: 40, StepBits: 23}, Epoch, 0); err == nil {
+		t.Error("Expected an error for an invalid layout")
+	}
+}
+
+func TestNodeValidateID(t *testing.T) {
+	now := SonyflakeEpoch + 1000000
+	node, _ := NewNode(1, WithPreset(Preset{Layout: SonyflakeLayout, Epoch: SonyflakeEpoch}), WithClock(ClockFunc(func() int64 { return now })))
+
+	id := node.Generate()
+	if err := node.ValidateID(id, 0); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	now -= 100
+	if err := node.ValidateID(id, 0); err != ErrIDInFuture {
+		t.Errorf("Got %v, expected ErrIDInFuture", err)
+	}
+	if err := node.ValidateID(id, 100*time.Millisecond); err != nil {
+		t.Errorf("Got %v, expected the skew to allow it", err)
+	}
+}